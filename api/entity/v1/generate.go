@@ -0,0 +1,4 @@
+// Package entityv1 contains the protobuf messages of the HTTP bodies generated from entity.proto
+package entityv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative api/entity/v1/entity.proto
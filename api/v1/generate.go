@@ -0,0 +1,4 @@
+// Package apiv1 contains the gRPC API of the service generated from service.proto
+package apiv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative api/v1/service.proto
@@ -0,0 +1,189 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: api/v1/service.proto
+
+package apiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	RedisPostgresService_Increment_FullMethodName = "/redis_postgres_service.v1.RedisPostgresService/Increment"
+	RedisPostgresService_Sign_FullMethodName      = "/redis_postgres_service.v1.RedisPostgresService/Sign"
+	RedisPostgresService_AddUser_FullMethodName   = "/redis_postgres_service.v1.RedisPostgresService/AddUser"
+)
+
+// RedisPostgresServiceClient is the client API for RedisPostgresService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RedisPostgresServiceClient interface {
+	// Increment increments the counter stored under the key in redis and returns its new value
+	Increment(ctx context.Context, in *IncrementRequest, opts ...grpc.CallOption) (*IncrementResponse, error)
+	// Sign returns the signature of the text made with the raw key or the server-side key referenced by key_id
+	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error)
+	// AddUser stores the user in postgres and returns its id
+	AddUser(ctx context.Context, in *AddUserRequest, opts ...grpc.CallOption) (*AddUserResponse, error)
+}
+
+type redisPostgresServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRedisPostgresServiceClient(cc grpc.ClientConnInterface) RedisPostgresServiceClient {
+	return &redisPostgresServiceClient{cc}
+}
+
+func (c *redisPostgresServiceClient) Increment(ctx context.Context, in *IncrementRequest, opts ...grpc.CallOption) (*IncrementResponse, error) {
+	out := new(IncrementResponse)
+	err := c.cc.Invoke(ctx, RedisPostgresService_Increment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *redisPostgresServiceClient) Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error) {
+	out := new(SignResponse)
+	err := c.cc.Invoke(ctx, RedisPostgresService_Sign_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *redisPostgresServiceClient) AddUser(ctx context.Context, in *AddUserRequest, opts ...grpc.CallOption) (*AddUserResponse, error) {
+	out := new(AddUserResponse)
+	err := c.cc.Invoke(ctx, RedisPostgresService_AddUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RedisPostgresServiceServer is the server API for RedisPostgresService service.
+// All implementations must embed UnimplementedRedisPostgresServiceServer
+// for forward compatibility
+type RedisPostgresServiceServer interface {
+	// Increment increments the counter stored under the key in redis and returns its new value
+	Increment(context.Context, *IncrementRequest) (*IncrementResponse, error)
+	// Sign returns the signature of the text made with the raw key or the server-side key referenced by key_id
+	Sign(context.Context, *SignRequest) (*SignResponse, error)
+	// AddUser stores the user in postgres and returns its id
+	AddUser(context.Context, *AddUserRequest) (*AddUserResponse, error)
+	mustEmbedUnimplementedRedisPostgresServiceServer()
+}
+
+// UnimplementedRedisPostgresServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRedisPostgresServiceServer struct {
+}
+
+func (UnimplementedRedisPostgresServiceServer) Increment(context.Context, *IncrementRequest) (*IncrementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Increment not implemented")
+}
+func (UnimplementedRedisPostgresServiceServer) Sign(context.Context, *SignRequest) (*SignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
+}
+func (UnimplementedRedisPostgresServiceServer) AddUser(context.Context, *AddUserRequest) (*AddUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddUser not implemented")
+}
+func (UnimplementedRedisPostgresServiceServer) mustEmbedUnimplementedRedisPostgresServiceServer() {}
+
+// UnsafeRedisPostgresServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RedisPostgresServiceServer will
+// result in compilation errors.
+type UnsafeRedisPostgresServiceServer interface {
+	mustEmbedUnimplementedRedisPostgresServiceServer()
+}
+
+func RegisterRedisPostgresServiceServer(s grpc.ServiceRegistrar, srv RedisPostgresServiceServer) {
+	s.RegisterService(&RedisPostgresService_ServiceDesc, srv)
+}
+
+func _RedisPostgresService_Increment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncrementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedisPostgresServiceServer).Increment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RedisPostgresService_Increment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedisPostgresServiceServer).Increment(ctx, req.(*IncrementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RedisPostgresService_Sign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedisPostgresServiceServer).Sign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RedisPostgresService_Sign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedisPostgresServiceServer).Sign(ctx, req.(*SignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RedisPostgresService_AddUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedisPostgresServiceServer).AddUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RedisPostgresService_AddUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedisPostgresServiceServer).AddUser(ctx, req.(*AddUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RedisPostgresService_ServiceDesc is the grpc.ServiceDesc for RedisPostgresService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RedisPostgresService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "redis_postgres_service.v1.RedisPostgresService",
+	HandlerType: (*RedisPostgresServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Increment",
+			Handler:    _RedisPostgresService_Increment_Handler,
+		},
+		{
+			MethodName: "Sign",
+			Handler:    _RedisPostgresService_Sign_Handler,
+		},
+		{
+			MethodName: "AddUser",
+			Handler:    _RedisPostgresService_AddUser_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
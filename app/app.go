@@ -2,21 +2,34 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"go.uber.org/config"
 	"go.uber.org/fx"
 	"net/http"
-	"redis-postgres-service/config"
+	internalconfig "redis-postgres-service/config"
 	"redis-postgres-service/controller"
 	"redis-postgres-service/gateway"
 	"redis-postgres-service/handler"
+	"redis-postgres-service/handler/openapi"
+	"redis-postgres-service/handler/problem"
+	"redis-postgres-service/handler/rpc"
 	"redis-postgres-service/handler/validation"
 	"redis-postgres-service/logging"
+	"redis-postgres-service/metrics"
 	"redis-postgres-service/repository"
+	"reflect"
+	"sort"
+	"strings"
 )
 
+// MetricsPath is the path of the Prometheus metrics
+const MetricsPath = "/metrics"
+
 var Module = fx.Options(
 	logging.Module,
+	metrics.Module,
 	handler.Module,
-	config.Module,
+	internalconfig.Module,
 	controller.Module,
 	repository.Module,
 	gateway.Module,
@@ -24,39 +37,34 @@ var Module = fx.Options(
 )
 
 // StartAndListen is a core service function that
-// 1. adds validation to the handler endpoints
-// 2. creates the server
+// 1. adds validation, authentication with the route scopes, rate limiting and idempotency keys to the handler endpoints
+// 2. creates the server that also serves the OpenAPI document of the endpoints, its Swagger UI page and the metrics
 // 3. adds OnStart fx.Hook that launches server listening
 // 4. adds OnStop fx.Hook that executes server shutdown when app is stopped
-func StartAndListen(h handler.Handler, lc fx.Lifecycle) {
-	mux := http.NewServeMux()
-	mux.Handle(
-		"/redis/incr",
-		validation.HttpPostCheck(
-			validation.NotNilRequest(
-				http.HandlerFunc(h.Incremental),
-			),
-		),
-	)
-	mux.Handle(
-		"/sign/hmacsha512",
-		validation.HttpPostCheck(
-			validation.NotNilRequest(
-				http.HandlerFunc(h.Signature),
-			),
-		),
-	)
-	mux.Handle(
-		"/postgres/users",
-		validation.HttpPostCheck(
-			validation.NotNilRequest(
-				http.HandlerFunc(h.AddUser),
-			),
-		),
-	)
+// 5. adds the same hooks for the gRPC server that listens its own port
+func StartAndListen(
+	h handler.Handler,
+	provider config.Provider,
+	auth validation.Authenticator,
+	limiter validation.RateLimiter,
+	idempotency validation.Idempotency,
+	instrumenter validation.Instrumenter,
+	registry metrics.Registry,
+	grpcServer rpc.Server,
+	lc fx.Lifecycle,
+) error {
+	var cfg internalconfig.HandlerConfig
+	err := provider.Get(handler.ConfigKey).Populate(&cfg)
+	if err != nil {
+		return err
+	}
+	mux, err := newMux(h, cfg, auth, limiter, idempotency, instrumenter, registry)
+	if err != nil {
+		return err
+	}
 	srv := &http.Server{
 		Addr:    ":8080",
-		Handler: mux,
+		Handler: validation.RequestID(mux),
 	}
 	lc.Append(
 		fx.Hook{
@@ -68,4 +76,131 @@ func StartAndListen(h handler.Handler, lc fx.Lifecycle) {
 				return srv.Shutdown(ctx)
 			},
 		})
+	lc.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return grpcServer.Start()
+			},
+			OnStop: func(ctx context.Context) error {
+				return grpcServer.Stop(ctx)
+			},
+		})
+	return nil
+}
+
+// newMux routes the handler endpoints described by handler.Routes, the OpenAPI document, the Swagger UI page
+// and the metrics. The endpoints are served by the Handler methods named by the routes with the scopes of the routes,
+// so that the mux can't drift from the document. Requests to every route are counted and timed by the instrumenter,
+// bodies of the idempotent requests are buffered up to the body limits of the endpoints in cfg.
+func newMux(
+	h handler.Handler,
+	cfg internalconfig.HandlerConfig,
+	auth validation.Authenticator,
+	limiter validation.RateLimiter,
+	idempotency validation.Idempotency,
+	instrumenter validation.Instrumenter,
+	registry metrics.Registry,
+) (*http.ServeMux, error) {
+	// guard authenticates the caller with the scope, limits its rate on the route and replays the responses
+	// to the retries with the same idempotency key. The rest of the middlewares are wrapped by the authenticator,
+	// so that the authenticated clients are identified by their ids. Routes without the scope are public.
+	guard := func(route, scope string, bodyLimit int64, h http.HandlerFunc) http.Handler {
+		if scope == "" {
+			return limiter.Limit(route)(idempotency.Idempotent(bodyLimit)(h))
+		}
+		return auth.Require(scope)(limiter.Limit(route)(idempotency.Idempotent(bodyLimit)(h)))
+	}
+	// idempotent streamed signature requests are buffered, so they are capped by the RequestBodyLimit too
+	bodyLimits := map[string]int64{"BulkAddUsers": cfg.BulkRequestBodyLimit}
+	var patterns []string
+	// endpoints are the endpoints of the mux patterns by the http methods
+	endpoints := make(map[string]map[string][]suffixEndpoint)
+	for _, route := range handler.Routes() {
+		fn, err := handlerFunc(h, route.Handler)
+		if err != nil {
+			return nil, err
+		}
+		bodyLimit, ok := bodyLimits[route.Handler]
+		if !ok {
+			bodyLimit = cfg.RequestBodyLimit
+		}
+		pattern, suffix := muxPattern(route.Path)
+		if endpoints[pattern] == nil {
+			patterns = append(patterns, pattern)
+			endpoints[pattern] = make(map[string][]suffixEndpoint)
+		}
+		for _, e := range endpoints[pattern][route.Method] {
+			if e.suffix == suffix {
+				return nil, fmt.Errorf("%s %s is routed to the same pattern as another route", route.Method, route.Path)
+			}
+		}
+		endpoints[pattern][route.Method] = append(endpoints[pattern][route.Method], suffixEndpoint{
+			suffix:  suffix,
+			handler: guard(pattern, route.Scope, bodyLimit, fn),
+		})
+	}
+	mux := http.NewServeMux()
+	// handle routes the pattern counting and timing its requests
+	handle := func(pattern string, h http.Handler) {
+		mux.Handle(pattern, instrumenter.Instrument(pattern)(h))
+	}
+	for _, pattern := range patterns {
+		methods := make(map[string]http.Handler)
+		for method, e := range endpoints[pattern] {
+			methods[method] = suffixRouter(e)
+		}
+		handle(pattern, validation.NotNilRequest(validation.MethodRouter(methods)))
+	}
+	handle(handler.OpenAPIPath, validation.HttpGetCheck(openapi.Handler(handler.OpenAPI())))
+	handle(handler.DocsPath, validation.HttpGetCheck(handler.Docs()))
+	handle(MetricsPath, validation.HttpGetCheck(metrics.Handler(registry)))
+	return mux, nil
+}
+
+// handlerFunc returns the method of the handler serving the route
+func handlerFunc(h handler.Handler, name string) (http.HandlerFunc, error) {
+	method := reflect.ValueOf(h).MethodByName(name)
+	if !method.IsValid() {
+		return nil, fmt.Errorf("handler has no %s method", name)
+	}
+	fn, ok := method.Interface().(func(http.ResponseWriter, *http.Request))
+	if !ok {
+		return nil, fmt.Errorf("handler method %s is not an http.HandlerFunc", name)
+	}
+	return fn, nil
+}
+
+// muxPattern returns the mux pattern of the route path and the literal suffix of the path after its parameters,
+// e.g. /sign/ and /verify of /sign/{algorithm}/verify. Paths without the parameters are the patterns themselves.
+func muxPattern(path string) (pattern, suffix string) {
+	i := strings.Index(path, "{")
+	if i < 0 {
+		return path, ""
+	}
+	return path[:i], path[strings.LastIndex(path, "}")+1:]
+}
+
+// suffixEndpoint is the endpoint of the requests to the mux pattern with the path suffix
+type suffixEndpoint struct {
+	suffix  string
+	handler http.Handler
+}
+
+// suffixRouter dispatches the requests to the endpoint of the longest suffix of their paths,
+// e.g. /sign/{algorithm}/verify requests to the verification endpoint and the rest of /sign/{algorithm}
+// requests to the signature endpoint with the empty suffix
+func suffixRouter(endpoints []suffixEndpoint) http.Handler {
+	if len(endpoints) == 1 {
+		return endpoints[0].handler
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return len(endpoints[i].suffix) > len(endpoints[j].suffix) })
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, e := range endpoints {
+			if strings.HasSuffix(req.URL.Path, e.suffix) {
+				e.handler.ServeHTTP(w, req)
+				return
+			}
+		}
+		problem.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	})
 }
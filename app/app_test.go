@@ -0,0 +1,220 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/handler"
+	"redis-postgres-service/handler/openapi"
+	"redis-postgres-service/handler/validation"
+	mock_handler "redis-postgres-service/mocks/handler"
+	mock_validation "redis-postgres-service/mocks/handler/validation"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const _scopeHeader = "X-Test-Scope"
+
+// _pathParams are the values of the path parameters used to request the routes
+var _pathParams = strings.NewReplacer("{key}", "visits", "{id}", "1", "{algorithm}", "hmacsha512")
+
+// newTestMux returns the mux of the handler with the authenticator reporting the required scope
+// in _scopeHeader, the rate limiter and the idempotency guard pass the requests through
+func newTestMux(t *testing.T, ctrl *gomock.Controller, h handler.Handler) *http.ServeMux {
+	auth := mock_validation.NewMockAuthenticator(ctrl)
+	auth.EXPECT().Require(gomock.Any()).DoAndReturn(func(scope string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(_scopeHeader, scope)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}).AnyTimes()
+	limiter := mock_validation.NewMockRateLimiter(ctrl)
+	limiter.EXPECT().Limit(gomock.Any()).Return(func(next http.Handler) http.Handler { return next }).AnyTimes()
+	idempotency := mock_validation.NewMockIdempotency(ctrl)
+	idempotency.EXPECT().Idempotent(gomock.Any()).Return(func(next http.Handler) http.Handler { return next }).AnyTimes()
+	registry := prometheus.NewRegistry()
+	instrumenter, err := validation.NewInstrumenter(validation.InstrumenterParams{Metrics: registry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux, err := newMux(h, internalconfig.HandlerConfig{}, auth, limiter, idempotency, instrumenter, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mux
+}
+
+// Test_newMux_Routes fails when the routes of the OpenAPI document drift from the handlers serving them
+func Test_newMux_Routes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	for _, route := range handler.Routes() {
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			h := mock_handler.NewMockHandler(ctrl)
+			expect := reflect.ValueOf(h.EXPECT()).MethodByName(route.Handler)
+			if !expect.IsValid() {
+				t.Fatalf("Handler has no %s method", route.Handler)
+			}
+			call := expect.Call([]reflect.Value{reflect.ValueOf(gomock.Any()), reflect.ValueOf(gomock.Any())})
+			call[0].Interface().(*gomock.Call).Times(1)
+			w := httptest.NewRecorder()
+			newTestMux(t, ctrl, h).ServeHTTP(w, httptest.NewRequest(route.Method, _pathParams.Replace(route.Path), nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, route.Scope, w.Header().Get(_scopeHeader))
+		})
+	}
+}
+
+// Test_newMux_UndocumentedMethods checks that the documented paths reject the methods missing in the document
+func Test_newMux_UndocumentedMethods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	documented := make(map[string]map[string]bool)
+	for _, route := range handler.Routes() {
+		if documented[route.Path] == nil {
+			documented[route.Path] = make(map[string]bool)
+		}
+		documented[route.Path][route.Method] = true
+	}
+	methods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	// no Handler method is expected to be called
+	mux := newTestMux(t, ctrl, mock_handler.NewMockHandler(ctrl))
+	for path, pathMethods := range documented {
+		for _, method := range methods {
+			if pathMethods[method] {
+				continue
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(method, _pathParams.Replace(path), nil))
+			assert.Equal(t, http.StatusMethodNotAllowed, w.Code, "%s %s", method, path)
+		}
+	}
+}
+
+func Test_handlerFunc(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		expectedError string
+	}{
+		{
+			name:   "handler method",
+			method: "AddUser",
+		},
+		{
+			name:          "unknown method",
+			method:        "AddUsers",
+			expectedError: "handler has no AddUsers method",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			fn, err := handlerFunc(mock_handler.NewMockHandler(ctrl), tt.method)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, fn)
+		})
+	}
+}
+
+func Test_muxPattern(t *testing.T) {
+	tests := []struct {
+		path            string
+		expectedPattern string
+		expectedSuffix  string
+	}{
+		{path: "/redis/incr", expectedPattern: "/redis/incr"},
+		{path: "/redis/incr/{key}", expectedPattern: "/redis/incr/"},
+		{path: "/sign/{algorithm}", expectedPattern: "/sign/"},
+		{path: "/sign/{algorithm}/verify", expectedPattern: "/sign/", expectedSuffix: "/verify"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pattern, suffix := muxPattern(tt.path)
+			assert.Equal(t, tt.expectedPattern, pattern)
+			assert.Equal(t, tt.expectedSuffix, suffix)
+		})
+	}
+}
+
+func Test_newMux_DocsAndMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mux := newTestMux(t, ctrl, mock_handler.NewMockHandler(ctrl))
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		code        int
+		contentType string
+	}{
+		{
+			name:        "openapi document",
+			method:      http.MethodGet,
+			path:        handler.OpenAPIPath,
+			code:        http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:        "swagger ui page",
+			method:      http.MethodGet,
+			path:        handler.DocsPath,
+			code:        http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+		},
+		{
+			name:   "swagger ui asset",
+			method: http.MethodGet,
+			path:   handler.DocsPath + "swagger-ui-bundle.js",
+			code:   http.StatusOK,
+		},
+		{
+			name:        "metrics",
+			method:      http.MethodGet,
+			path:        MetricsPath,
+			code:        http.StatusOK,
+			contentType: "text/plain; version=0.0.4; charset=utf-8",
+		},
+		{
+			name:        "method not allowed",
+			method:      http.MethodPost,
+			path:        handler.OpenAPIPath,
+			code:        http.StatusMethodNotAllowed,
+			contentType: "application/problem+json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.code, w.Code)
+			// content types of the assets depend on the mime types of the system
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="POST",route="/openapi.json",status="405"} 1`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, handler.OpenAPIPath, nil))
+	var doc openapi.Document
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Len(t, doc.Paths, len(handler.OpenAPI().Paths))
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/fx"
+	"io"
+	"redis-postgres-service/config"
+	"redis-postgres-service/logging"
+	"redis-postgres-service/metrics"
+	"redis-postgres-service/repository/postgres/migrations"
+	"redis-postgres-service/repository/postgres/pgfx"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// MigrateCommand is the name of the subcommand that manages the postgres schema instead of running the service
+const MigrateCommand = "migrate"
+
+const _migrateTimeout = 5 * time.Minute
+
+const _migrateUsage = "usage: migrate up | down [steps] | status"
+
+// MigrateModule contains only the dependencies required to run the schema migrations
+var MigrateModule = fx.Options(
+	logging.Module,
+	config.Module,
+	metrics.Module,
+	pgfx.Module,
+	fx.Provide(migrations.New),
+	fx.NopLogger,
+)
+
+// Migrate executes the migrate subcommand with the provided args and writes the result to out
+// 1. `up` applies all the pending migrations
+// 2. `down [steps]` reverts the last steps applied migrations, 1 by default
+// 3. `status` lists all the known migrations and when they were applied
+func Migrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(_migrateUsage)
+	}
+	var migrator migrations.Migrator
+	app := fx.New(MigrateModule, fx.Populate(&migrator))
+	ctx, cancel := context.WithTimeout(context.Background(), _migrateTimeout)
+	defer cancel()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(ctx)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrations(out, "applied", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return errors.Errorf("steps must be a positive integer, %s", _migrateUsage)
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		return printMigrations(out, "reverted", reverted)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.Errorf("unknown migrate command %s, %s", args[0], _migrateUsage)
+	}
+}
+
+// printMigrations writes the versions and names of the migrations processed by the subcommand
+func printMigrations(out io.Writer, action string, processed []migrations.Migration) error {
+	if len(processed) == 0 {
+		_, err := fmt.Fprintf(out, "no migrations %s\n", action)
+		return err
+	}
+	for _, migration := range processed {
+		if _, err := fmt.Fprintf(out, "%s %d_%s\n", action, migration.Version, migration.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -14,9 +14,32 @@ func New() (config.Provider, error) {
 	return provider, nil
 }
 
-// HandlerConfig is a container for the handler configuration
+// HandlerConfig is a container for the handler configuration.
+// StreamRequestBodyLimit caps the streamed signature requests, 0 means no limit.
+// StrictDecoding overrides the strict decoding of the request bodies by the endpoint names, e.g. `IssueToken`,
+// bodies of the mutating requests are decoded strictly by default.
 type HandlerConfig struct {
-	RequestBodyLimit int64 `yaml:"request_body_limit"`
+	RequestBodyLimit       int64           `yaml:"request_body_limit"`
+	BulkRequestBodyLimit   int64           `yaml:"bulk_request_body_limit"`
+	StreamRequestBodyLimit int64           `yaml:"stream_request_body_limit"`
+	StrictDecoding         map[string]bool `yaml:"strict_decoding"`
+}
+
+// GRPCConfig is a container for the gRPC server configuration. Address is the host:port the server listens,
+// Reflection enables the server reflection service used by the tools like grpcurl.
+type GRPCConfig struct {
+	Address    string `yaml:"address"`
+	Reflection bool   `yaml:"reflection"`
+}
+
+// IdempotencyConfig is a container for the idempotency keys configuration. Responses are replayed for TTL seconds,
+// LockTTL limits the seconds the key is held by the request in flight, so that the key is released if the instance
+// processing it dies. Responses bigger than MaxResponseSize bytes are not stored.
+type IdempotencyConfig struct {
+	Enabled         bool  `yaml:"enabled"`
+	TTL             int64 `yaml:"ttl"`
+	LockTTL         int64 `yaml:"lock_ttl"`
+	MaxResponseSize int64 `yaml:"max_response_size"`
 }
 
 // PgfxConfig is a container for the Postgres interface configuration (implemented by pgxpool.Pool)
@@ -34,7 +57,9 @@ type PgfxSecrets struct {
 
 // PostgresRepoConfig is a container for the postgres repository configuration
 type PostgresRepoConfig struct {
-	Schema string `yaml:"schema"`
+	Schema        string `yaml:"schema"`
+	AutoMigrate   bool   `yaml:"auto_migrate"`
+	BulkBatchSize int    `yaml:"bulk_batch_size"`
 }
 
 // RedisConfig is a container for redis repository configuration
@@ -48,3 +73,58 @@ type RedisConfig struct {
 type RedisSecrets struct {
 	Password string `yaml:"password"`
 }
+
+// SigningKeySecrets is a container for the versions of the named signing key. Signatures are produced with
+// CurrentVersion, while the rest of the versions are kept to verify the signatures made before the rotation.
+// Algorithm restricts the key to the single signature algorithm, it is required for the asymmetric keys
+// which versions are PEM encoded private keys. Internal keys are only used by the service itself to issue the tokens
+// and to authenticate the clients, so that they can't be used by the signing endpoints to impersonate anyone.
+type SigningKeySecrets struct {
+	Algorithm      string         `yaml:"algorithm"`
+	CurrentVersion int            `yaml:"current_version"`
+	Versions       map[int]string `yaml:"versions"`
+	Internal       bool           `yaml:"internal"`
+}
+
+// TokensConfig is a container for the JWT issuing configuration. KeyID references the server-side signing key
+// used when the request doesn't provide one, KeyIDs are the rest of the keys the tokens may be signed with.
+// Expirations and Leeway for the clock skew are in seconds.
+type TokensConfig struct {
+	Issuer           string   `yaml:"issuer"`
+	Algorithm        string   `yaml:"algorithm"`
+	KeyID            string   `yaml:"key_id"`
+	KeyIDs           []string `yaml:"key_ids"`
+	DefaultExpiresIn int64    `yaml:"default_expires_in"`
+	MaxExpiresIn     int64    `yaml:"max_expires_in"`
+	Leeway           int64    `yaml:"leeway"`
+}
+
+// AuthConfig is a container for the authentication middleware configuration. MaxClockSkew is the allowed difference
+// in seconds between the timestamp of the HMAC signed request and the server time, SignedBodyLimit caps the body
+// of the HMAC signed requests as it is hashed before the request is handled. Audience is expected in the bearer tokens
+// if it is not empty.
+type AuthConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	MaxClockSkew    int64  `yaml:"max_clock_skew"`
+	SignedBodyLimit int64  `yaml:"signed_body_limit"`
+	Audience        string `yaml:"audience"`
+}
+
+// AuthClientSecrets is a container for the credentials of the API client. APIKeySHA256 is the hex encoded sha256
+// of the static API key, SigningKeyID references the server-side signing key of the HMAC signed requests.
+// Scopes are the routes the client is allowed to call.
+type AuthClientSecrets struct {
+	Scopes       []string `yaml:"scopes"`
+	APIKeySHA256 string   `yaml:"api_key_sha256"`
+	SigningKeyID string   `yaml:"signing_key_id"`
+}
+
+// RateLimitConfig is a container for the rate limiting configuration. Clients are allowed to make Limit requests
+// to every route within the sliding Window in seconds, Routes override the limit for the route patterns,
+// a zero limit disables the rate limiting of the route.
+type RateLimitConfig struct {
+	Enabled bool             `yaml:"enabled"`
+	Window  int64            `yaml:"window"`
+	Limit   int64            `yaml:"limit"`
+	Routes  map[string]int64 `yaml:"routes"`
+}
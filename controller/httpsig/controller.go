@@ -0,0 +1,436 @@
+package httpsig
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"hash"
+	"net/url"
+	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/gateway/signer"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	_defaultLabel = "sig1"
+
+	_signatureInputHeader = "Signature-Input"
+	_signatureHeader      = "Signature"
+	_contentDigestHeader  = "Content-Digest"
+
+	_contentDigestComponent = "content-digest"
+
+	// _clockSkew is the tolerated difference of the clocks in seconds, signatures created later are invalid
+	_clockSkew = 60
+)
+
+// _algorithms maps the names of the RFC 9421 signature algorithms to the names of the gateway algorithms
+var _algorithms = map[string]string{
+	"hmac-sha256":       signer.HMACSHA256,
+	"ecdsa-p256-sha256": signer.ECDSAP256SHA256,
+	"ed25519":           signer.Ed25519,
+	"rsa-pss-sha512":    signer.RSAPSSSHA512,
+}
+
+// _digestAlgorithms are the RFC 9530 digest algorithms accepted in the Content-Digest header
+var _digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+var _labelRegexp = regexp.MustCompile(`^[a-z*][a-z0-9_\-.*]*$`)
+
+type Controller interface {
+	Sign(ctx context.Context, req *entity.HTTPSignRequest) (*entity.HTTPSignResponse, error)
+	Verify(ctx context.Context, req *entity.HTTPVerifyRequest) (*entity.HTTPVerifyResponse, error)
+}
+
+// Params is an fx container for all Controller dependencies
+type Params struct {
+	fx.In
+
+	SignCtrl sign.Controller
+	Logger   *zap.Logger
+}
+
+// New is a constructor provided to the fx for creating a Controller
+func New(p Params) (Controller, error) {
+	return &controller{
+		logger:   p.Logger,
+		signCtrl: p.SignCtrl,
+		now:      time.Now,
+	}, nil
+}
+
+// compile time check that controller implements Controller interface
+var _ Controller = (*controller)(nil)
+
+type controller struct {
+	logger   *zap.Logger
+	signCtrl sign.Controller
+	now      func() time.Time
+}
+
+// Sign signs the covered components of the message as defined by RFC 9421 and returns the Signature-Input
+// and Signature headers. Content-Digest of the body is computed with sha-256 if it is covered, but missing
+// in the message. The signature base is signed by sign.Controller, so the keys are resolved the same way.
+func (c *controller) Sign(ctx context.Context, req *entity.HTTPSignRequest) (*entity.HTTPSignResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	algorithm, ok := _algorithms[req.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %s: %w", req.Algorithm, entity.ErrInvalidArgument)
+	}
+	label := req.Label
+	if label == "" {
+		label = _defaultLabel
+	}
+	if !_labelRegexp.MatchString(label) {
+		return nil, fmt.Errorf("label %s must be a lowercase structured field key: %w", label, entity.ErrInvalidArgument)
+	}
+	if req.ExpiresIn < 0 {
+		return nil, fmt.Errorf("expires_in must not be negative: %w", entity.ErrInvalidArgument)
+	}
+	msg, err := parseMessage(req.HTTPMessage)
+	if err != nil {
+		return nil, err
+	}
+	components := make([]string, len(req.Components))
+	for i, component := range req.Components {
+		components[i] = strings.ToLower(component)
+	}
+	headers := make(map[string]string)
+	if contains(components, _contentDigestComponent) && msg.headers[_contentDigestComponent] == "" {
+		digest := contentDigest("sha-256", []byte(msg.body))
+		msg.headers[_contentDigestComponent] = digest
+		headers[_contentDigestHeader] = digest
+	}
+	created := c.now().Unix()
+	params := []param{{name: "created", value: created}}
+	if req.ExpiresIn > 0 {
+		params = append(params, param{name: "expires", value: created + req.ExpiresIn})
+	}
+	if req.Nonce != "" {
+		params = append(params, param{name: "nonce", value: req.Nonce})
+	}
+	params = append(params, param{name: "alg", value: req.Algorithm})
+	if req.KeyID != "" {
+		params = append(params, param{name: "keyid", value: req.KeyID})
+	}
+	if req.Tag != "" {
+		params = append(params, param{name: "tag", value: req.Tag})
+	}
+	signatureParams := serializeInnerList(components, params)
+	base, err := signatureBase(msg, components, signatureParams)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.signCtrl.Sign(ctx, &entity.SignRequest{
+		Algorithm:  algorithm,
+		Text:       base,
+		Key:        req.Key,
+		KeyID:      req.KeyID,
+		KeyVersion: req.KeyVersion,
+		Encoding:   signer.EncodingBase64,
+	})
+	if err != nil {
+		return nil, err
+	}
+	headers[_signatureInputHeader] = label + "=" + signatureParams
+	headers[_signatureHeader] = label + "=:" + response.Signature + ":"
+	return &entity.HTTPSignResponse{
+		Headers:    headers,
+		KeyID:      response.KeyID,
+		KeyVersion: response.KeyVersion,
+	}, nil
+}
+
+// Verify checks the signature of the message with the label, the first one by default, as defined by RFC 9421.
+// Expired signatures, the ones created in the future or earlier than the max age of the request and
+// the covered Content-Digest that doesn't match the body are reported as invalid.
+// The key is either provided in the request or referenced by the keyid parameter of the signature.
+func (c *controller) Verify(ctx context.Context, req *entity.HTTPVerifyRequest) (*entity.HTTPVerifyResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	msg, err := parseMessage(req.HTTPMessage)
+	if err != nil {
+		return nil, err
+	}
+	input, signature, err := findSignature(msg, req.Label)
+	if err != nil {
+		return nil, err
+	}
+	response := &entity.HTTPVerifyResponse{
+		Label:      input.name,
+		Algorithm:  req.Algorithm,
+		Components: make([]string, 0, len(input.list)),
+		KeyID:      req.KeyID,
+	}
+	for _, it := range input.list {
+		component, ok := it.value.(string)
+		if !ok || len(it.params) > 0 {
+			return nil, fmt.Errorf(
+				"signature %s: only plain component identifiers are supported: %w", input.name, entity.ErrInvalidArgument,
+			)
+		}
+		response.Components = append(response.Components, component)
+	}
+	var created, expires int64
+	for _, p := range input.item.params {
+		switch p.name {
+		case "alg":
+			alg, _ := p.value.(string)
+			if response.Algorithm != "" && response.Algorithm != alg {
+				return nil, fmt.Errorf(
+					"signature %s is made with %s algorithm: %w", input.name, alg, entity.ErrInvalidArgument,
+				)
+			}
+			response.Algorithm = alg
+		case "keyid":
+			if response.KeyID == "" && req.Key == "" {
+				response.KeyID, _ = p.value.(string)
+			}
+		case "created":
+			created, _ = p.value.(int64)
+		case "expires":
+			expires, _ = p.value.(int64)
+		}
+	}
+	algorithm, ok := _algorithms[response.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %s: %w", response.Algorithm, entity.ErrInvalidArgument)
+	}
+	if req.Key == "" && response.KeyID == "" {
+		return nil, fmt.Errorf(
+			"signature %s has no keyid parameter, key or key_id must be provided: %w", input.name, entity.ErrInvalidArgument,
+		)
+	}
+	if reason := c.expired(created, expires, req.MaxAge); reason != "" {
+		response.Reason = reason
+		return response, nil
+	}
+	// the digest is checked against an empty body too, otherwise the body could be dropped from the request
+	if contains(response.Components, _contentDigestComponent) &&
+		!digestMatches(msg.headers[_contentDigestComponent], []byte(msg.body)) {
+		response.Reason = "content digest doesn't match the body"
+		return response, nil
+	}
+	base, err := signatureBase(msg, response.Components, serializeInnerList(response.Components, input.item.params))
+	if err != nil {
+		return nil, err
+	}
+	verified, err := c.signCtrl.Verify(ctx, &entity.VerifyRequest{
+		Algorithm:  algorithm,
+		Text:       base,
+		Key:        req.Key,
+		KeyID:      response.KeyID,
+		KeyVersion: req.KeyVersion,
+		Signature:  base64.StdEncoding.EncodeToString(signature),
+		Encoding:   signer.EncodingBase64,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response.Valid = verified.Valid
+	response.KeyVersion = verified.KeyVersion
+	if !verified.Valid {
+		response.Reason = "signature doesn't match"
+	}
+	return response, nil
+}
+
+// expired returns the reason why the signature with the created and expires parameters is not valid at the moment,
+// the signatures without the created parameter are rejected only if the maxAge is set
+func (c *controller) expired(created, expires, maxAge int64) string {
+	now := c.now().Unix()
+	switch {
+	case expires != 0 && now > expires:
+		return "signature has expired"
+	case created > now+_clockSkew:
+		return "signature is created in the future"
+	case maxAge > 0 && created == 0:
+		return "signature has no created parameter to check max_age"
+	case maxAge > 0 && now-created > maxAge:
+		return "signature is older than max_age"
+	}
+	return ""
+}
+
+// message is the parsed HTTP message with the lowercase header names
+type message struct {
+	method  string
+	url     *url.URL
+	rawURL  string
+	headers map[string]string
+	body    string
+}
+
+// parseMessage parses the URL of the message and combines the header values with the same case-insensitive name
+func parseMessage(m entity.HTTPMessage) (*message, error) {
+	u, err := url.Parse(m.URL)
+	if err != nil || !u.IsAbs() {
+		return nil, fmt.Errorf("url must be an absolute URL: %w", entity.ErrInvalidArgument)
+	}
+	headers := make(map[string]string, len(m.Headers))
+	for name, value := range m.Headers {
+		name = strings.ToLower(name)
+		value = strings.TrimSpace(value)
+		if existing, ok := headers[name]; ok {
+			value = existing + ", " + value
+		}
+		headers[name] = value
+	}
+	return &message{
+		method:  m.Method,
+		url:     u,
+		rawURL:  m.URL,
+		headers: headers,
+		body:    m.Body,
+	}, nil
+}
+
+// signatureBase returns the RFC 9421 signature base: a line with the value of every covered component
+// followed by the @signature-params line
+func signatureBase(msg *message, components []string, signatureParams string) (string, error) {
+	var b strings.Builder
+	seen := make(map[string]bool, len(components))
+	for _, component := range components {
+		if seen[component] {
+			return "", fmt.Errorf("component %s is covered twice: %w", component, entity.ErrInvalidArgument)
+		}
+		seen[component] = true
+		value, err := componentValue(msg, component)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s: %s\n", serializeBareItem(component), value)
+	}
+	fmt.Fprintf(&b, "%s: %s", serializeBareItem("@signature-params"), signatureParams)
+	return b.String(), nil
+}
+
+// componentValue returns the value of the derived component or the header field of the message
+func componentValue(msg *message, component string) (string, error) {
+	switch component {
+	case "@method":
+		if msg.method == "" {
+			return "", fmt.Errorf("method must not be empty: %w", entity.ErrInvalidArgument)
+		}
+		return msg.method, nil
+	case "@target-uri":
+		return msg.rawURL, nil
+	case "@authority":
+		return authority(msg.url), nil
+	case "@scheme":
+		return strings.ToLower(msg.url.Scheme), nil
+	case "@request-target":
+		return msg.url.RequestURI(), nil
+	case "@path":
+		if msg.url.EscapedPath() == "" {
+			return "/", nil
+		}
+		return msg.url.EscapedPath(), nil
+	case "@query":
+		return "?" + msg.url.RawQuery, nil
+	}
+	if strings.HasPrefix(component, "@") {
+		return "", fmt.Errorf("unsupported derived component %s: %w", component, entity.ErrInvalidArgument)
+	}
+	value, ok := msg.headers[component]
+	if !ok {
+		return "", fmt.Errorf("covered header %s is missing: %w", component, entity.ErrInvalidArgument)
+	}
+	return value, nil
+}
+
+// authority returns the lowercase host of the url without the default port of the scheme
+func authority(u *url.URL) string {
+	host := strings.ToLower(u.Host)
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "http" && strings.HasSuffix(host, ":80") || scheme == "https" && strings.HasSuffix(host, ":443") {
+		host = host[:strings.LastIndexByte(host, ':')]
+	}
+	return host
+}
+
+// findSignature returns the Signature-Input member and the signature with the label, the first one by default
+func findSignature(msg *message, label string) (*member, []byte, error) {
+	inputs, err := parseDictionary(msg.headers[strings.ToLower(_signatureInputHeader)])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s: %w", _signatureInputHeader, err, entity.ErrInvalidArgument)
+	}
+	signatures, err := parseDictionary(msg.headers[strings.ToLower(_signatureHeader)])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s: %w", _signatureHeader, err, entity.ErrInvalidArgument)
+	}
+	if len(inputs) == 0 {
+		return nil, nil, fmt.Errorf("message has no signatures: %w", entity.ErrInvalidArgument)
+	}
+	if label == "" {
+		label = inputs[0].name
+	}
+	var input *member
+	for i := range inputs {
+		if inputs[i].name == label && inputs[i].isList {
+			input = &inputs[i]
+		}
+	}
+	for _, s := range signatures {
+		if s.name != label || input == nil {
+			continue
+		}
+		if signature, ok := s.item.value.([]byte); ok {
+			return input, signature, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("signature %s: %w", label, entity.ErrNotFound)
+}
+
+// contentDigest returns the RFC 9530 Content-Digest of the body with the algorithm
+func contentDigest(algorithm string, body []byte) string {
+	h := _digestAlgorithms[algorithm]()
+	h.Write(body)
+	return algorithm + "=" + serializeBareItem(h.Sum(nil))
+}
+
+// digestMatches checks that all the known digests in the Content-Digest header match the body,
+// at least one digest must be known
+func digestMatches(header string, body []byte) bool {
+	digests, err := parseDictionary(header)
+	if err != nil {
+		return false
+	}
+	matched := false
+	for _, digest := range digests {
+		if _, ok := _digestAlgorithms[digest.name]; !ok {
+			continue
+		}
+		expected := contentDigest(digest.name, body)
+		actual := digest.name + "=" + serializeBareItem(digest.item.value)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) != 1 {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,688 @@
+package httpsig
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/gateway/signer"
+	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_keystore "redis-postgres-service/mocks/repository/keystore"
+	"testing"
+	"time"
+)
+
+// _testMessage is the request from RFC 9421 Appendix B.2
+var _testMessage = entity.HTTPMessage{
+	Method: "POST",
+	URL:    "https://example.com/foo?param=Value&Pet=dog",
+	Headers: map[string]string{
+		"Host":           "example.com",
+		"Date":           "Tue, 20 Apr 2021 02:07:55 GMT",
+		"Content-Type":   "application/json",
+		"Content-Digest": "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:",
+		"Content-Length": "18",
+	},
+	Body: `{"hello": "world"}`,
+}
+
+// _testSharedSecret is the test-shared-secret key from RFC 9421 Appendix B.1.5
+const _testSharedSecret = "uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi6pcl8jsasjlTMtDQ=="
+
+// _testCreated is the creation time of the signatures in RFC 9421 Appendix B.2
+const _testCreated = 1618884473
+
+// signedTestMessage returns the test message with the Signature-Input and Signature headers
+func signedTestMessage(signatureInput, signature string) entity.HTTPMessage {
+	msg := _testMessage
+	msg.Headers = map[string]string{
+		"Signature-Input": signatureInput,
+		"Signature":       signature,
+	}
+	for name, value := range _testMessage.Headers {
+		msg.Headers[name] = value
+	}
+	return msg
+}
+
+func TestNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	c, err := New(Params{
+		SignCtrl: mock_sign.NewMockController(ctrl),
+		Logger:   zap.NewNop(),
+	})
+	assert.NotNil(t, c)
+	assert.NoError(t, err)
+}
+
+func Test_signatureBase(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    entity.HTTPMessage
+		components []string
+		params     string
+		want       string
+		assertion  assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "Happy path, RFC 9421 B.2.5",
+			message:    _testMessage,
+			components: []string{"date", "@authority", "content-type"},
+			params:     `("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`,
+			want: `"date": Tue, 20 Apr 2021 02:07:55 GMT
+"@authority": example.com
+"content-type": application/json
+"@signature-params": ("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`,
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, derived components",
+			message: entity.HTTPMessage{
+				Method: "GET",
+				URL:    "HTTPS://Example.com:443?a=b",
+			},
+			components: []string{"@method", "@target-uri", "@authority", "@scheme", "@request-target", "@path", "@query"},
+			params:     `()`,
+			want: `"@method": GET
+"@target-uri": HTTPS://Example.com:443?a=b
+"@authority": example.com
+"@scheme": https
+"@request-target": /?a=b
+"@path": /
+"@query": ?a=b
+"@signature-params": ()`,
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, header values are combined",
+			message: entity.HTTPMessage{
+				URL:     "https://example.com",
+				Headers: map[string]string{"X-Forwarded-For": " 10.0.0.1 "},
+			},
+			components: []string{"x-forwarded-for"},
+			params:     `("x-forwarded-for")`,
+			want:       "\"x-forwarded-for\": 10.0.0.1\n\"@signature-params\": (\"x-forwarded-for\")",
+			assertion:  assert.NoError,
+		},
+		{
+			name:       "Covered header is missing",
+			message:    _testMessage,
+			components: []string{"authorization"},
+			assertion:  assert.Error,
+		},
+		{
+			name:       "Component is covered twice",
+			message:    _testMessage,
+			components: []string{"date", "date"},
+			assertion:  assert.Error,
+		},
+		{
+			name:       "Unsupported derived component",
+			message:    _testMessage,
+			components: []string{"@status"},
+			assertion:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseMessage(tt.message)
+			assert.NoError(t, err)
+			got, err := signatureBase(msg, tt.components, tt.params)
+			tt.assertion(t, err)
+			if err == nil {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_controller_Sign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockSignCtrl struct {
+		req *entity.SignRequest
+		res *entity.SignResponse
+		err error
+	}
+	tests := []struct {
+		name         string
+		req          *entity.HTTPSignRequest
+		mockSignCtrl *mockSignCtrl
+		want         *entity.HTTPSignResponse
+		wantErr      error
+		assertion    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: _testMessage,
+				Algorithm:   "hmac-sha256",
+				Components:  []string{"Date", "@authority", "content-type"},
+				KeyID:       "partner-a",
+				ExpiresIn:   300,
+				Nonce:       "b3k2pp5k7z",
+			},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignRequest{
+					Algorithm: signer.HMACSHA256,
+					Text: `"date": Tue, 20 Apr 2021 02:07:55 GMT
+"@authority": example.com
+"content-type": application/json
+"@signature-params": ("date" "@authority" "content-type");created=1618884473;expires=1618884773;nonce="b3k2pp5k7z";alg="hmac-sha256";keyid="partner-a"`,
+					KeyID:    "partner-a",
+					Encoding: signer.EncodingBase64,
+				},
+				res: &entity.SignResponse{Signature: "LqgjxQ==", KeyID: "partner-a", KeyVersion: 2},
+			},
+			want: &entity.HTTPSignResponse{
+				Headers: map[string]string{
+					"Signature-Input": `sig1=("date" "@authority" "content-type");created=1618884473;expires=1618884773;nonce="b3k2pp5k7z";alg="hmac-sha256";keyid="partner-a"`,
+					"Signature":       "sig1=:LqgjxQ==:",
+				},
+				KeyID:      "partner-a",
+				KeyVersion: 2,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, content digest is added",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: entity.HTTPMessage{
+					Method: "POST",
+					URL:    "https://example.com/foo",
+					Body:   `{"hello": "world"}`,
+				},
+				Algorithm:  "ed25519",
+				Components: []string{"@method", "content-digest"},
+				Label:      "webhook",
+				Key:        "private key",
+				Tag:        "app",
+			},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignRequest{
+					Algorithm: signer.Ed25519,
+					Text: `"@method": POST
+"content-digest": sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:
+"@signature-params": ("@method" "content-digest");created=1618884473;alg="ed25519";tag="app"`,
+					Key:      "private key",
+					Encoding: signer.EncodingBase64,
+				},
+				res: &entity.SignResponse{Signature: "LqgjxQ=="},
+			},
+			want: &entity.HTTPSignResponse{
+				Headers: map[string]string{
+					"Content-Digest":  "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:",
+					"Signature-Input": `webhook=("@method" "content-digest");created=1618884473;alg="ed25519";tag="app"`,
+					"Signature":       "webhook=:LqgjxQ==:",
+				},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Sign controller fails",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: _testMessage,
+				Algorithm:   "hmac-sha256",
+				Components:  []string{"@method"},
+				KeyID:       "partner-b",
+			},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignRequest{
+					Algorithm: signer.HMACSHA256,
+					Text: `"@method": POST
+"@signature-params": ("@method");created=1618884473;alg="hmac-sha256";keyid="partner-b"`,
+					KeyID:    "partner-b",
+					Encoding: signer.EncodingBase64,
+				},
+				err: entity.ErrNotFound,
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name: "Unsupported algorithm",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: _testMessage,
+				Algorithm:   "rsa-v1_5-sha256",
+				Components:  []string{"@method"},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Invalid label",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: _testMessage,
+				Algorithm:   "hmac-sha256",
+				Components:  []string{"@method"},
+				Label:       "Sig 1",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Negative expiration",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: _testMessage,
+				Algorithm:   "hmac-sha256",
+				Components:  []string{"@method"},
+				ExpiresIn:   -1,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Relative url",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: entity.HTTPMessage{Method: "GET", URL: "/foo"},
+				Algorithm:   "hmac-sha256",
+				Components:  []string{"@method"},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Covered header is missing",
+			req: &entity.HTTPSignRequest{
+				HTTPMessage: _testMessage,
+				Algorithm:   "hmac-sha256",
+				Components:  []string{"authorization"},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			signCtrlMock := mock_sign.NewMockController(ctrl)
+			if tt.mockSignCtrl != nil {
+				signCtrlMock.EXPECT().
+					Sign(ctx, tt.mockSignCtrl.req).
+					Return(tt.mockSignCtrl.res, tt.mockSignCtrl.err)
+			}
+			c := &controller{
+				logger:   zap.NewNop(),
+				signCtrl: signCtrlMock,
+				now:      func() time.Time { return time.Unix(_testCreated, 0) },
+			}
+			got, err := c.Sign(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockSignCtrl struct {
+		req *entity.VerifyRequest
+		res *entity.VerifyResponse
+		err error
+	}
+	rfcInput := `sig-b25=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`
+	rfcSignature := "sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:"
+	rfcBase := `"date": Tue, 20 Apr 2021 02:07:55 GMT
+"@authority": example.com
+"content-type": application/json
+"@signature-params": ("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`
+	tests := []struct {
+		name         string
+		req          *entity.HTTPVerifyRequest
+		mockSignCtrl *mockSignCtrl
+		want         *entity.HTTPVerifyResponse
+		wantErr      error
+		assertion    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path, key id from the signature parameters",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(rfcInput, rfcSignature),
+				Algorithm:   "hmac-sha256",
+				MaxAge:      300,
+			},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.VerifyRequest{
+					Algorithm: signer.HMACSHA256,
+					Text:      rfcBase,
+					KeyID:     "test-shared-secret",
+					Signature: "pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=",
+					Encoding:  signer.EncodingBase64,
+				},
+				res: &entity.VerifyResponse{Valid: true, KeyID: "test-shared-secret", KeyVersion: 1},
+			},
+			want: &entity.HTTPVerifyResponse{
+				Valid:      true,
+				Label:      "sig-b25",
+				Algorithm:  "hmac-sha256",
+				Components: []string{"date", "@authority", "content-type"},
+				KeyID:      "test-shared-secret",
+				KeyVersion: 1,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, signature doesn't match",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(
+					`sig1=("@method" "content-digest");created=1618884473;alg="ed25519", `+rfcInput,
+					"sig1=:LqgjxQ==:, "+rfcSignature,
+				),
+				Key: "public key",
+			},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.VerifyRequest{
+					Algorithm: signer.Ed25519,
+					Text: `"@method": POST
+"content-digest": sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:
+"@signature-params": ("@method" "content-digest");created=1618884473;alg="ed25519"`,
+					Key:       "public key",
+					Signature: "LqgjxQ==",
+					Encoding:  signer.EncodingBase64,
+				},
+				res: &entity.VerifyResponse{Valid: false},
+			},
+			want: &entity.HTTPVerifyResponse{
+				Valid:      false,
+				Label:      "sig1",
+				Algorithm:  "ed25519",
+				Components: []string{"@method", "content-digest"},
+				Reason:     "signature doesn't match",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, signature has expired",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(
+					`sig1=("@method");created=1618884000;expires=1618884300;alg="hmac-sha256"`,
+					"sig1=:LqgjxQ==:",
+				),
+				Key: "key",
+			},
+			want: &entity.HTTPVerifyResponse{
+				Label:      "sig1",
+				Algorithm:  "hmac-sha256",
+				Components: []string{"@method"},
+				Reason:     "signature has expired",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, signature is created in the future",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(`sig1=("@method");created=1618884534;alg="hmac-sha256"`, "sig1=:LqgjxQ==:"),
+				Key:         "key",
+			},
+			want: &entity.HTTPVerifyResponse{
+				Label:      "sig1",
+				Algorithm:  "hmac-sha256",
+				Components: []string{"@method"},
+				Reason:     "signature is created in the future",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, signature is older than max age",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(`sig1=("@method");created=1618884172;alg="hmac-sha256"`, "sig1=:LqgjxQ==:"),
+				Key:         "key",
+				MaxAge:      300,
+			},
+			want: &entity.HTTPVerifyResponse{
+				Label:      "sig1",
+				Algorithm:  "hmac-sha256",
+				Components: []string{"@method"},
+				Reason:     "signature is older than max_age",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, signature without created parameter and max age",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(`sig1=("@method");alg="hmac-sha256"`, "sig1=:LqgjxQ==:"),
+				Key:         "key",
+				MaxAge:      300,
+			},
+			want: &entity.HTTPVerifyResponse{
+				Label:      "sig1",
+				Algorithm:  "hmac-sha256",
+				Components: []string{"@method"},
+				Reason:     "signature has no created parameter to check max_age",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, content digest doesn't match the body",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: func() entity.HTTPMessage {
+					msg := signedTestMessage(`sig1=("content-digest");alg="hmac-sha256"`, "sig1=:LqgjxQ==:")
+					msg.Body = `{"hello": "mallory"}`
+					return msg
+				}(),
+				Key: "key",
+			},
+			want: &entity.HTTPVerifyResponse{
+				Label:      "sig1",
+				Algorithm:  "hmac-sha256",
+				Components: []string{"content-digest"},
+				Reason:     "content digest doesn't match the body",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Key is not provided and not referenced by the signature",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(`sig1=("@method");alg="hmac-sha256"`, "sig1=:LqgjxQ==:"),
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Happy path, content digest doesn't match the empty body",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: func() entity.HTTPMessage {
+					msg := signedTestMessage(`sig1=("content-digest");alg="hmac-sha256"`, "sig1=:LqgjxQ==:")
+					msg.Body = ""
+					return msg
+				}(),
+				Key: "key",
+			},
+			want: &entity.HTTPVerifyResponse{
+				Label:      "sig1",
+				Algorithm:  "hmac-sha256",
+				Components: []string{"content-digest"},
+				Reason:     "content digest doesn't match the body",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Algorithm doesn't match the signature parameters",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(`sig1=("@method");alg="ed25519"`, "sig1=:LqgjxQ==:"),
+				Algorithm:   "hmac-sha256",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Algorithm is unknown",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(rfcInput, rfcSignature),
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Component has parameters",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(`sig1=("content-type";sf);alg="ed25519"`, "sig1=:LqgjxQ==:"),
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Label is not found",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(rfcInput, rfcSignature),
+				Label:       "sig1",
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name: "Message is not signed",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: _testMessage,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Signature input is malformed",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(`sig1=("@method"`, "sig1=:LqgjxQ==:"),
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Sign controller fails",
+			req: &entity.HTTPVerifyRequest{
+				HTTPMessage: signedTestMessage(rfcInput, rfcSignature),
+				Algorithm:   "hmac-sha256",
+			},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.VerifyRequest{
+					Algorithm: signer.HMACSHA256,
+					Text:      rfcBase,
+					KeyID:     "test-shared-secret",
+					Signature: "pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=",
+					Encoding:  signer.EncodingBase64,
+				},
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			signCtrlMock := mock_sign.NewMockController(ctrl)
+			if tt.mockSignCtrl != nil {
+				signCtrlMock.EXPECT().
+					Verify(ctx, tt.mockSignCtrl.req).
+					Return(tt.mockSignCtrl.res, tt.mockSignCtrl.err)
+			}
+			c := &controller{
+				logger:   zap.NewNop(),
+				signCtrl: signCtrlMock,
+				now:      func() time.Time { return time.Unix(_testCreated, 0) },
+			}
+			got, err := c.Verify(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Test_controller_Verify_RFC9421 checks the HMAC-SHA256 example from RFC 9421 Appendix B.2.5
+// with the real signature gateway
+func Test_controller_Verify_RFC9421(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	gateway, _ := signer.New()
+	signCtrl, _ := sign.New(sign.Params{
+		Gateway:  gateway,
+		Keystore: mock_keystore.NewMockKeystore(ctrl),
+		Logger:   zap.NewNop(),
+		Metrics:  prometheus.NewRegistry(),
+	})
+	key, _ := base64.StdEncoding.DecodeString(_testSharedSecret)
+	c := &controller{
+		logger:   zap.NewNop(),
+		signCtrl: signCtrl,
+		now:      func() time.Time { return time.Unix(_testCreated, 0) },
+	}
+	got, err := c.Verify(context.Background(), &entity.HTTPVerifyRequest{
+		HTTPMessage: signedTestMessage(
+			`sig-b25=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`,
+			"sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:",
+		),
+		Algorithm: "hmac-sha256",
+		Key:       string(key),
+	})
+	assert.NoError(t, err)
+	assert.True(t, got.Valid)
+
+	signed, err := c.Sign(context.Background(), &entity.HTTPSignRequest{
+		HTTPMessage: _testMessage,
+		Algorithm:   "hmac-sha256",
+		Components:  []string{"@method", "@target-uri", "content-digest"},
+		Key:         string(key),
+	})
+	assert.NoError(t, err)
+	got, err = c.Verify(context.Background(), &entity.HTTPVerifyRequest{
+		HTTPMessage: signedTestMessage(signed.Headers["Signature-Input"], signed.Headers["Signature"]),
+		Key:         string(key),
+	})
+	assert.NoError(t, err)
+	assert.True(t, got.Valid)
+
+	// messages are neither signed nor verified with an empty key, cause anyone can forge such signatures
+	forged, err := c.Sign(context.Background(), &entity.HTTPSignRequest{
+		HTTPMessage: _testMessage,
+		Algorithm:   "hmac-sha256",
+		Components:  []string{"@method"},
+	})
+	assert.ErrorIs(t, err, entity.ErrInvalidArgument)
+	assert.Nil(t, forged)
+	// the signature is HMAC-SHA256 of the signature base with an empty key
+	got, err = c.Verify(context.Background(), &entity.HTTPVerifyRequest{
+		HTTPMessage: signedTestMessage(
+			`sig1=("@method");created=1618884473;alg="hmac-sha256"`,
+			"sig1=:DV6f/27AlDq5N38MeW//TqaTS6zYwYN8gBdUSeaM1Gk=:",
+		),
+	})
+	assert.ErrorIs(t, err, entity.ErrInvalidArgument)
+	assert.Nil(t, got)
+}
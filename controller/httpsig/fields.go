@@ -0,0 +1,317 @@
+package httpsig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The subset of RFC 8941 Structured Field Values used by the Signature-Input and Signature dictionaries:
+// integers, strings, tokens, booleans and byte sequences, optionally grouped into inner lists, with parameters.
+
+// token is a Structured Field token, it is serialized without the quotes
+type token string
+
+// param is a parameter of a Structured Field item or inner list
+type param struct {
+	name  string
+	value any
+}
+
+// item is a Structured Field bare item with its parameters
+type item struct {
+	value  any
+	params []param
+}
+
+// member is a member of a Structured Field dictionary, that is either a single item or an inner list of items
+type member struct {
+	name   string
+	item   item
+	list   []item
+	isList bool
+}
+
+// parseDictionary parses the Structured Field dictionary keeping the order of the members
+func parseDictionary(s string) ([]member, error) {
+	p := &parser{s: s}
+	var members []member
+	p.skip(" ")
+	for !p.done() {
+		name, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		m := member{name: name}
+		if p.consume('=') {
+			if p.peek() == '(' {
+				m.isList = true
+				m.list, m.item.params, err = p.innerList()
+			} else {
+				m.item, err = p.item()
+			}
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			m.item.value = true
+			if m.item.params, err = p.params(); err != nil {
+				return nil, err
+			}
+		}
+		members = append(members, m)
+		p.skip(" \t")
+		if p.done() {
+			break
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected ','")
+		}
+		p.skip(" \t")
+		if p.done() {
+			return nil, p.errorf("trailing ','")
+		}
+	}
+	return members, nil
+}
+
+// serializeInnerList serializes the inner list of strings with the parameters
+func serializeInnerList(values []string, params []param) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(serializeBareItem(value))
+	}
+	b.WriteByte(')')
+	b.WriteString(serializeParams(params))
+	return b.String()
+}
+
+func serializeParams(params []param) string {
+	var b strings.Builder
+	for _, p := range params {
+		b.WriteByte(';')
+		b.WriteString(p.name)
+		if v, ok := p.value.(bool); ok && v {
+			continue
+		}
+		b.WriteByte('=')
+		b.WriteString(serializeBareItem(p.value))
+	}
+	return b.String()
+}
+
+func serializeBareItem(value any) string {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+	case token:
+		return string(v)
+	case bool:
+		if v {
+			return "?1"
+		}
+		return "?0"
+	case []byte:
+		return ":" + base64.StdEncoding.EncodeToString(v) + ":"
+	default:
+		return "" // unreachable, cause the parser produces only the types above
+	}
+}
+
+// parser reads the Structured Field value from s
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.peek() == c && !p.done() {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) skip(chars string) {
+	for !p.done() && strings.IndexByte(chars, p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("malformed structured field at %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// key reads the dictionary or parameter key: lowercase letter or '*' followed by lowercase letters, digits, '_', '-', '.' or '*'
+func (p *parser) key() (string, error) {
+	start := p.pos
+	if c := p.peek(); !(c >= 'a' && c <= 'z' || c == '*') {
+		return "", p.errorf("expected key")
+	}
+	for !p.done() {
+		c := p.s[p.pos]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("_-.*", c) >= 0) {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *parser) innerList() ([]item, []param, error) {
+	p.consume('(')
+	var items []item
+	for {
+		p.skip(" ")
+		if p.consume(')') {
+			params, err := p.params()
+			return items, params, err
+		}
+		if p.done() {
+			return nil, nil, p.errorf("unterminated inner list")
+		}
+		if len(items) > 0 && p.s[p.pos-1] != ' ' {
+			return nil, nil, p.errorf("expected ' '")
+		}
+		it, err := p.item()
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, it)
+	}
+}
+
+func (p *parser) item() (item, error) {
+	value, err := p.bareItem()
+	if err != nil {
+		return item{}, err
+	}
+	params, err := p.params()
+	if err != nil {
+		return item{}, err
+	}
+	return item{value: value, params: params}, nil
+}
+
+func (p *parser) params() ([]param, error) {
+	var params []param
+	for p.consume(';') {
+		p.skip(" ")
+		name, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		var value any = true
+		if p.consume('=') {
+			if value, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+		params = append(params, param{name: name, value: value})
+	}
+	return params, nil
+}
+
+func (p *parser) bareItem() (any, error) {
+	c := p.peek()
+	switch {
+	case c == '"':
+		return p.string()
+	case c == ':':
+		return p.byteSequence()
+	case c == '?':
+		p.pos++
+		switch {
+		case p.consume('1'):
+			return true, nil
+		case p.consume('0'):
+			return false, nil
+		}
+		return nil, p.errorf("malformed boolean")
+	case c == '-' || c >= '0' && c <= '9':
+		return p.integer()
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '*':
+		return p.token(), nil
+	default:
+		return nil, p.errorf("unexpected character %q", c)
+	}
+}
+
+func (p *parser) string() (string, error) {
+	p.consume('"')
+	var b strings.Builder
+	for !p.done() {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '\\':
+			if p.done() || p.s[p.pos] != '"' && p.s[p.pos] != '\\' {
+				return "", p.errorf("malformed escape")
+			}
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+		case c == '"':
+			return b.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", p.errorf("unexpected character in string")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) byteSequence() ([]byte, error) {
+	p.consume(':')
+	end := strings.IndexByte(p.s[p.pos:], ':')
+	if end < 0 {
+		return nil, p.errorf("unterminated byte sequence")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(p.s[p.pos : p.pos+end])
+	if err != nil {
+		return nil, p.errorf("malformed byte sequence")
+	}
+	p.pos += end + 1
+	return decoded, nil
+}
+
+func (p *parser) integer() (int64, error) {
+	start := p.pos
+	p.consume('-')
+	p.skip("0123456789")
+	value, err := strconv.ParseInt(p.s[start:p.pos], 10, 64)
+	if err != nil || p.pos-start > 16 {
+		return 0, p.errorf("malformed integer")
+	}
+	return value, nil
+}
+
+func (p *parser) token() token {
+	start := p.pos
+	for !p.done() {
+		c := p.s[p.pos]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),;<=>?@[\]{}`, c) >= 0 {
+			break
+		}
+		p.pos++
+	}
+	return token(p.s[start:p.pos])
+}
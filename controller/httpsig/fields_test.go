@@ -0,0 +1,125 @@
+package httpsig
+
+import (
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_parseDictionary(t *testing.T) {
+	digest := sha256.Sum256([]byte(`{"hello": "world"}`))
+	tests := []struct {
+		name      string
+		value     string
+		want      []member
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "Happy path, signature input",
+			value: `sig1=("@method" "content-type");created=1618884473;keyid="test-key-rsa", sig2=()`,
+			want: []member{
+				{
+					name: "sig1",
+					list: []item{{value: "@method"}, {value: "content-type"}},
+					item: item{params: []param{
+						{name: "created", value: int64(1618884473)},
+						{name: "keyid", value: "test-key-rsa"},
+					}},
+					isList: true,
+				},
+				{
+					name:   "sig2",
+					isList: true,
+				},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name:  "Happy path, byte sequences, tokens and booleans",
+			value: `sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:,  a=b/c;d;e=?0, f`,
+			want: []member{
+				{
+					name: "sha-256",
+					item: item{value: digest[:]},
+				},
+				{
+					name: "a",
+					item: item{value: token("b/c"), params: []param{{name: "d", value: true}, {name: "e", value: false}}},
+				},
+				{
+					name: "f",
+					item: item{value: true},
+				},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, empty",
+			value:     "",
+			want:      nil,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Uppercase key",
+			value:     `Sig1=("@method")`,
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unterminated inner list",
+			value:     `sig1=("@method"`,
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unterminated string",
+			value:     `sig1=("@method)`,
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Malformed byte sequence",
+			value:     `sig1=:not base64:`,
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Trailing comma",
+			value:     `sig1=:AA==:,`,
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Missing comma",
+			value:     `sig1=:AA==: sig2=:AA==:`,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDictionary(tt.value)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_serializeInnerList(t *testing.T) {
+	got := serializeInnerList(
+		[]string{"@method", "content-type"},
+		[]param{
+			{name: "created", value: int64(1618884473)},
+			{name: "nonce", value: `b3k2p"\`},
+			{name: "alg", value: token("ed25519")},
+			{name: "final", value: true},
+			{name: "draft", value: false},
+			{name: "raw", value: []byte{0xff}},
+		},
+	)
+	assert.Equal(t, `("@method" "content-type");created=1618884473;nonce="b3k2p\"\\";alg=ed25519;final;draft=?0;raw=:/w==:`, got)
+
+	members, err := parseDictionary("sig1=" + got)
+	assert.NoError(t, err)
+	assert.Equal(t, got, serializeInnerList([]string{"@method", "content-type"}, members[0].item.params))
+}
@@ -0,0 +1,131 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/repository/redis"
+	"time"
+)
+
+const (
+	_configKey = "idempotency"
+	_keyPrefix = "idempotency"
+)
+
+type Controller interface {
+	Begin(ctx context.Context, req *entity.IdempotencyRequest) (*entity.IdempotencyResponse, error)
+	Complete(ctx context.Context, req *entity.IdempotencyRequest, res *entity.StoredResponse) error
+	Release(ctx context.Context, req *entity.IdempotencyRequest) error
+}
+
+// compile time check that controller implements Controller interface
+var _ Controller = (*controller)(nil)
+
+// Params is an fx container for all Controller dependencies
+type Params struct {
+	fx.In
+
+	Repository     redis.Repository
+	ConfigProvider config.Provider
+}
+
+// New is a constructor provided to the fx for creating a Controller
+func New(p Params) (Controller, error) {
+	var cfg internalconfig.IdempotencyConfig
+	err := p.ConfigProvider.Get(_configKey).Populate(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	if cfg.Enabled && (cfg.TTL <= 0 || cfg.LockTTL <= 0) {
+		return nil, errors.New("idempotency ttl and lock_ttl must be positive")
+	}
+	return &controller{
+		repository: p.Repository,
+		config:     cfg,
+	}, nil
+}
+
+type controller struct {
+	repository redis.Repository
+	config     internalconfig.IdempotencyConfig
+}
+
+// record is stored under the idempotency key, Response is set once the request is completed
+type record struct {
+	Fingerprint string                 `json:"fingerprint"`
+	Response    *entity.StoredResponse `json:"response,omitempty"`
+}
+
+// Begin acquires the idempotency key for the request. The stored response is returned if the request with the key
+// is already completed. entity.ErrConflict is returned while the request with the key is in flight and
+// entity.ErrInvalidArgument if the key is used for another request.
+func (c *controller) Begin(ctx context.Context, req *entity.IdempotencyRequest) (*entity.IdempotencyResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	data, err := json.Marshal(record{Fingerprint: req.Fingerprint})
+	if err != nil {
+		return nil, err // unreachable in tests, cause record can always be represented as json
+	}
+	acquired, current, err := c.repository.AcquireKey(
+		ctx, key(req), data, time.Duration(c.config.LockTTL)*time.Second,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if acquired {
+		return &entity.IdempotencyResponse{}, nil
+	}
+	var stored record
+	if err = json.Unmarshal(current, &stored); err != nil {
+		return nil, fmt.Errorf("stored idempotency record is malformed: %s", err)
+	}
+	if stored.Fingerprint != req.Fingerprint {
+		return nil, &entity.PublicError{
+			Message: "idempotency key is used for another request",
+			Err:     fmt.Errorf("idempotency key %s is used for another request: %w", req.Key, entity.ErrInvalidArgument),
+		}
+	}
+	if stored.Response == nil {
+		return nil, &entity.PublicError{
+			Message: "request with the idempotency key is in flight, retry later",
+			Err:     fmt.Errorf("request with idempotency key %s is in flight: %w", req.Key, entity.ErrConflict),
+		}
+	}
+	return &entity.IdempotencyResponse{Replay: stored.Response}, nil
+}
+
+// Complete stores the response of the request that acquired the key, so that it is replayed on the retries
+func (c *controller) Complete(ctx context.Context, req *entity.IdempotencyRequest, res *entity.StoredResponse) error {
+	if req == nil || res == nil {
+		return errors.New("nil request")
+	}
+	data, err := json.Marshal(record{Fingerprint: req.Fingerprint, Response: res})
+	if err != nil {
+		return err // unreachable in tests, cause record can always be represented as json
+	}
+	return c.repository.SetKey(ctx, key(req), data, time.Duration(c.config.TTL)*time.Second)
+}
+
+// Release removes the key of the request that is not going to be completed, so that it can be retried
+func (c *controller) Release(ctx context.Context, req *entity.IdempotencyRequest) error {
+	if req == nil {
+		return errors.New("nil request")
+	}
+	err := c.repository.DeleteKey(ctx, key(req))
+	if errors.Is(err, entity.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// key returns the redis key of the request, keys are scoped by the clients
+func key(req *entity.IdempotencyRequest) string {
+	return fmt.Sprintf("%s:%s:%s", _keyPrefix, req.Client, req.Key)
+}
@@ -0,0 +1,255 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	mock_redis "redis-postgres-service/mocks/repository/redis"
+	"strings"
+	"testing"
+	"time"
+)
+
+var _testConfig = internalconfig.IdempotencyConfig{Enabled: true, TTL: 3600, LockTTL: 60}
+
+var _testRequest = &entity.IdempotencyRequest{Client: "principal:billing", Key: "some-key", Fingerprint: "POST /redis/incr"}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			yaml:      `{"idempotency":{"enabled":true,"ttl":3600,"lock_ttl":60}}`,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Idempotency is not configured",
+			yaml:      `{}`,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Lock TTL is not positive",
+			yaml:      `{"idempotency":{"enabled":true,"ttl":3600}}`,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			provider, err := config.NewYAML(config.Source(strings.NewReader(tt.yaml)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = New(Params{
+				Repository:     mock_redis.NewMockRepository(ctrl),
+				ConfigProvider: provider,
+			})
+			tt.assertion(t, err)
+		})
+	}
+}
+
+func Test_controller_Begin(t *testing.T) {
+	type mockRepository struct {
+		acquired bool
+		current  string
+		err      error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.IdempotencyRequest
+		mockRepository *mockRepository
+		want           *entity.IdempotencyResponse
+		wantErr        error
+		wantMessage    string
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name:           "Happy path, key is acquired",
+			req:            _testRequest,
+			mockRepository: &mockRepository{acquired: true},
+			want:           &entity.IdempotencyResponse{},
+			assertion:      assert.NoError,
+		},
+		{
+			name: "Completed response is replayed",
+			req:  _testRequest,
+			mockRepository: &mockRepository{
+				current: `{"fingerprint":"POST /redis/incr","response":{"status":200,"body":"eyJ2YWx1ZSI6MX0="}}`,
+			},
+			want: &entity.IdempotencyResponse{
+				Replay: &entity.StoredResponse{Status: 200, Body: []byte(`{"value":1}`)},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name:           "Request is in flight",
+			req:            _testRequest,
+			mockRepository: &mockRepository{current: `{"fingerprint":"POST /redis/incr"}`},
+			wantErr:        entity.ErrConflict,
+			wantMessage:    "request with the idempotency key is in flight, retry later",
+			assertion:      assert.Error,
+		},
+		{
+			name:           "Key is used for another request",
+			req:            _testRequest,
+			mockRepository: &mockRepository{current: `{"fingerprint":"POST /postgres/users"}`},
+			wantErr:        entity.ErrInvalidArgument,
+			wantMessage:    "idempotency key is used for another request",
+			assertion:      assert.Error,
+		},
+		{
+			name:           "Stored record is malformed",
+			req:            _testRequest,
+			mockRepository: &mockRepository{current: `not json`},
+			assertion:      assert.Error,
+		},
+		{
+			name:           "Repo fails",
+			req:            _testRequest,
+			mockRepository: &mockRepository{err: errors.New("some error")},
+			assertion:      assert.Error,
+		},
+		{
+			name:      "nil request",
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				var current []byte
+				if tt.mockRepository.current != "" {
+					current = []byte(tt.mockRepository.current)
+				}
+				repo.EXPECT().
+					AcquireKey(
+						gomock.Any(),
+						"idempotency:principal:billing:some-key",
+						[]byte(`{"fingerprint":"POST /redis/incr"}`),
+						time.Minute,
+					).
+					Return(tt.mockRepository.acquired, current, tt.mockRepository.err)
+			}
+			c := &controller{repository: repo, config: _testConfig}
+			got, err := c.Begin(context.Background(), tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, entity.PublicMessage(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Complete(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *entity.IdempotencyRequest
+		res       *entity.StoredResponse
+		repoErr   error
+		callRepo  bool
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			req:       _testRequest,
+			res:       &entity.StoredResponse{Status: 201, Body: []byte(`{"value":1}`)},
+			callRepo:  true,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Repo fails",
+			req:       _testRequest,
+			res:       &entity.StoredResponse{Status: 201, Body: []byte(`{"value":1}`)},
+			repoErr:   errors.New("some error"),
+			callRepo:  true,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil response",
+			req:       _testRequest,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.callRepo {
+				repo.EXPECT().
+					SetKey(
+						gomock.Any(),
+						"idempotency:principal:billing:some-key",
+						[]byte(`{"fingerprint":"POST /redis/incr","response":{"status":201,"body":"eyJ2YWx1ZSI6MX0="}}`),
+						time.Hour,
+					).
+					Return(tt.repoErr)
+			}
+			c := &controller{repository: repo, config: _testConfig}
+			tt.assertion(t, c.Complete(context.Background(), tt.req, tt.res))
+		})
+	}
+}
+
+func Test_controller_Release(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *entity.IdempotencyRequest
+		repoErr   error
+		callRepo  bool
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			req:       _testRequest,
+			callRepo:  true,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Key is already expired",
+			req:       _testRequest,
+			repoErr:   entity.ErrNotFound,
+			callRepo:  true,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Repo fails",
+			req:       _testRequest,
+			repoErr:   errors.New("some error"),
+			callRepo:  true,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.callRepo {
+				repo.EXPECT().DeleteKey(gomock.Any(), "idempotency:principal:billing:some-key").Return(tt.repoErr)
+			}
+			c := &controller{repository: repo, config: _testConfig}
+			tt.assertion(t, c.Release(context.Background(), tt.req))
+		})
+	}
+}
@@ -2,14 +2,30 @@ package incremental
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"math"
 	"redis-postgres-service/entity"
 	"redis-postgres-service/repository/redis"
+	"regexp"
+	"strconv"
+	"time"
 )
 
+// _maxBatchSize limits the number of increments executed in a single pipeline
+const _maxBatchSize = 10000
+
+// _decimalRegexp matches the plain decimal notation accepted in the decimal mode, e.g. -12.50
+var _decimalRegexp = regexp.MustCompile(`^[+-]?\d+(\.\d+)?$`)
+
 type Controller interface {
 	Inc(ctx context.Context, req *entity.IncrementRequest) (*entity.IncrementResponse, error)
+	IncBatch(ctx context.Context, req *entity.BatchIncrementRequest) (*entity.BatchIncrementResponse, error)
+	Get(ctx context.Context, req *entity.CounterRequest) (*entity.IncrementResponse, error)
+	Reset(ctx context.Context, req *entity.CounterRequest) (*entity.IncrementResponse, error)
 }
 
 // compile time check that controller implements Controller interface
@@ -20,30 +36,201 @@ type Params struct {
 	fx.In
 
 	Repository redis.Repository
+	Logger     *zap.Logger
 }
 
 // New is a constructor provided to the fx for creating a Controller
 func New(p Params) (Controller, error) {
 	return &controller{
 		repository: p.Repository,
+		logger:     p.Logger,
 	}, nil
 }
 
 type controller struct {
 	repository redis.Repository
+	logger     *zap.Logger
 }
 
 // Inc adds value provided in the request to the value stored in the downstream repository under the respective key
-// resulting value is returned
+// resulting value is returned. Value is interpreted according to the mode: int (default), float or decimal.
+// If ttl or bounds are provided they are applied atomically with the increment, bounds are supported in int mode only.
 func (c *controller) Inc(ctx context.Context, req *entity.IncrementRequest) (*entity.IncrementResponse, error) {
 	if req == nil {
 		return nil, errors.New("nil request")
 	}
-	res, err := c.repository.AddIntValueForKey(ctx, req.Key, req.Value)
+	if req.TTLSeconds < 0 {
+		return nil, fmt.Errorf("ttl_seconds must not be negative: %w", entity.ErrInvalidArgument)
+	}
+	switch req.Mode {
+	case "", entity.IncrementModeInt:
+		return c.incInt(ctx, req)
+	case entity.IncrementModeFloat, entity.IncrementModeDecimal:
+		if req.Min != nil || req.Max != nil {
+			return nil, fmt.Errorf("min and max are supported in %s mode only: %w", entity.IncrementModeInt, entity.ErrInvalidArgument)
+		}
+		if req.Mode == entity.IncrementModeFloat {
+			return c.incFloat(ctx, req)
+		}
+		return c.incDecimal(ctx, req)
+	default:
+		return nil, fmt.Errorf("unknown mode %s: %w", req.Mode, entity.ErrInvalidArgument)
+	}
+}
+
+// incInt increments the counter by int64 value, missing value is treated as 0
+func (c *controller) incInt(ctx context.Context, req *entity.IncrementRequest) (*entity.IncrementResponse, error) {
+	var value int64
+	if req.Value != "" {
+		var err error
+		value, err = req.Value.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("value must be an int64 in %s mode: %w", entity.IncrementModeInt, entity.ErrInvalidArgument)
+		}
+	}
+	if req.TTLSeconds == 0 && req.Min == nil && req.Max == nil {
+		res, err := c.repository.AddIntValueForKey(ctx, req.Key, value)
+		if err != nil {
+			return nil, err
+		}
+		return &entity.IncrementResponse{
+			Value: intNumber(res),
+		}, nil
+	}
+	if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+		return nil, fmt.Errorf("min must not exceed max: %w", entity.ErrInvalidArgument)
+	}
+	res, ttl, err := c.repository.AddIntValueForKeyWithOptions(ctx, req.Key, value, redis.IncrementOptions{
+		TTL: time.Duration(req.TTLSeconds) * time.Second,
+		Min: req.Min,
+		Max: req.Max,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.IncrementResponse{
+		Value:      intNumber(res),
+		TTLSeconds: ttlSeconds(ttl),
+	}, nil
+}
+
+// incFloat increments the counter by float64 value
+func (c *controller) incFloat(ctx context.Context, req *entity.IncrementRequest) (*entity.IncrementResponse, error) {
+	value, err := req.Value.Float64()
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil, fmt.Errorf("value must be a finite number in %s mode: %w", entity.IncrementModeFloat, entity.ErrInvalidArgument)
+	}
+	res, ttl, err := c.repository.AddFloatValueForKey(ctx, req.Key, value, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.IncrementResponse{
+		Value:      json.Number(res),
+		TTLSeconds: ttlSeconds(ttl),
+	}, nil
+}
+
+// incDecimal increments the counter by arbitrary-precision decimal value
+func (c *controller) incDecimal(ctx context.Context, req *entity.IncrementRequest) (*entity.IncrementResponse, error) {
+	if !_decimalRegexp.MatchString(req.Value.String()) {
+		return nil, fmt.Errorf("value must be a plain decimal, e.g. -12.50, in %s mode: %w", entity.IncrementModeDecimal, entity.ErrInvalidArgument)
+	}
+	res, ttl, err := c.repository.AddDecimalValueForKey(ctx, req.Key, req.Value.String(), time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.IncrementResponse{
+		Value:      json.Number(res),
+		TTLSeconds: ttlSeconds(ttl),
+	}, nil
+}
+
+// IncBatch adds values provided in the request items to the values stored in the downstream repository under
+// the respective keys in a single round trip. Failures of the individual increments are reported per item
+// with the messages the clients can see, the original errors are logged.
+func (c *controller) IncBatch(
+	ctx context.Context,
+	req *entity.BatchIncrementRequest,
+) (*entity.BatchIncrementResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("items must not be empty: %w", entity.ErrInvalidArgument)
+	}
+	if len(req.Items) > _maxBatchSize {
+		return nil, fmt.Errorf("batch must not exceed %d items: %w", _maxBatchSize, entity.ErrInvalidArgument)
+	}
+	res, err := c.repository.AddIntValuesForKeys(ctx, req.Items, req.Atomic)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]entity.BatchIncrementResult, len(res))
+	for i, item := range res {
+		results[i].Key = req.Items[i].Key
+		if item.Err != nil {
+			results[i].Error = itemError(item.Err)
+			c.logger.Sugar().Errorf("increment of the batch item %d failed: %s", i, item.Err)
+			continue
+		}
+		results[i].Value = intNumber(item.Value)
+	}
+	return &entity.BatchIncrementResponse{
+		Results: results,
+	}, nil
+}
+
+// itemError returns the message of the failed increment of the batch item the clients can see, the same way
+// as the failed requests are reported: public messages are returned as is, the rest are replaced by the generic ones
+func itemError(err error) string {
+	var public *entity.PublicError
+	switch {
+	case errors.As(err, &public):
+		return public.Message
+	case errors.Is(err, entity.ErrUnavailable):
+		return entity.UnavailableMessage
+	}
+	return entity.InternalErrorMessage
+}
+
+// Get returns the value stored in the downstream repository under the respective key
+func (c *controller) Get(ctx context.Context, req *entity.CounterRequest) (*entity.IncrementResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	res, ttl, err := c.repository.GetValueForKey(ctx, req.Key)
 	if err != nil {
 		return nil, err
 	}
 	return &entity.IncrementResponse{
-		Value: res,
+		Value:      json.Number(res),
+		TTLSeconds: ttlSeconds(ttl),
+	}, nil
+}
+
+// Reset removes the value stored in the downstream repository under the respective key,
+// so that the next increment starts from zero
+func (c *controller) Reset(ctx context.Context, req *entity.CounterRequest) (*entity.IncrementResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	if err := c.repository.DeleteKey(ctx, req.Key); err != nil {
+		return nil, err
+	}
+	return &entity.IncrementResponse{
+		Value: intNumber(0),
 	}, nil
 }
+
+// intNumber formats the integer counter value for the response
+func intNumber(value int64) json.Number {
+	return json.Number(strconv.FormatInt(value, 10))
+}
+
+// ttlSeconds rounds the remaining time to live up to seconds, keys that never expire are reported as 0
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
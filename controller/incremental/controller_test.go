@@ -3,11 +3,15 @@ package incremental
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 	"redis-postgres-service/entity"
 	mock_redis "redis-postgres-service/mocks/repository/redis"
+	"redis-postgres-service/repository/redis"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -16,6 +20,7 @@ func TestNew(t *testing.T) {
 	repo := mock_redis.NewMockRepository(ctrl)
 	c, err := New(Params{
 		Repository: repo,
+		Logger:     zap.NewNop(),
 	})
 	assert.NotNil(t, c)
 	assert.NoError(t, err)
@@ -43,7 +48,7 @@ func Test_controller_Inc(t *testing.T) {
 			args: args{
 				req: &entity.IncrementRequest{
 					Key:   "Key",
-					Value: 123,
+					Value: "123",
 				},
 			},
 			mockRepository: &mockRepository{
@@ -51,7 +56,7 @@ func Test_controller_Inc(t *testing.T) {
 				err: nil,
 			},
 			want: &entity.IncrementResponse{
-				Value: 124,
+				Value: "124",
 			},
 			assertion: assert.NoError,
 		},
@@ -60,7 +65,7 @@ func Test_controller_Inc(t *testing.T) {
 			args: args{
 				req: &entity.IncrementRequest{
 					Key:   "Key",
-					Value: 123,
+					Value: "123",
 				},
 			},
 			mockRepository: &mockRepository{
@@ -104,3 +109,502 @@ func Test_controller_Inc(t *testing.T) {
 		})
 	}
 }
+
+func Test_controller_Inc_WithOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	min, max := int64(0), int64(10)
+	type mockRepository struct {
+		value int64
+		opts  redis.IncrementOptions
+		res   int64
+		ttl   time.Duration
+		err   error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.IncrementRequest
+		mockRepository *mockRepository
+		want           *entity.IncrementResponse
+		wantErr        error
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req: &entity.IncrementRequest{
+				Key:        "Key",
+				Value:      "-3",
+				TTLSeconds: 60,
+				Min:        &min,
+				Max:        &max,
+			},
+			mockRepository: &mockRepository{
+				value: -3,
+				opts:  redis.IncrementOptions{TTL: time.Minute, Min: &min, Max: &max},
+				res:   0,
+				ttl:   59500 * time.Millisecond,
+			},
+			want: &entity.IncrementResponse{
+				Value:      "0",
+				TTLSeconds: 60,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, counter never expires",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "3",
+				Max:   &max,
+			},
+			mockRepository: &mockRepository{
+				value: 3,
+				opts:  redis.IncrementOptions{Max: &max},
+				res:   3,
+				ttl:   -1,
+			},
+			want: &entity.IncrementResponse{
+				Value: "3",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "min exceeds max",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "3",
+				Min:   &max,
+				Max:   &min,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "negative ttl",
+			req: &entity.IncrementRequest{
+				Key:        "Key",
+				Value:      "3",
+				TTLSeconds: -1,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "repo fails",
+			req: &entity.IncrementRequest{
+				Key:        "Key",
+				Value:      "3",
+				TTLSeconds: 60,
+			},
+			mockRepository: &mockRepository{
+				value: 3,
+				opts:  redis.IncrementOptions{TTL: time.Minute},
+				err:   errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repo.EXPECT().
+					AddIntValueForKeyWithOptions(ctx, tt.req.Key, tt.mockRepository.value, tt.mockRepository.opts).
+					Return(tt.mockRepository.res, tt.mockRepository.ttl, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repo,
+			}
+			got, err := c.Inc(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Inc_Modes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	max := int64(10)
+	type mockRepository struct {
+		res string
+		ttl time.Duration
+		err error
+	}
+	tests := []struct {
+		name      string
+		req       *entity.IncrementRequest
+		mockFloat *mockRepository
+		mockDec   *mockRepository
+		want      *entity.IncrementResponse
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path, float",
+			req: &entity.IncrementRequest{
+				Key:        "Key",
+				Value:      "1.5",
+				Mode:       entity.IncrementModeFloat,
+				TTLSeconds: 60,
+			},
+			mockFloat: &mockRepository{res: "3.25", ttl: time.Minute},
+			want: &entity.IncrementResponse{
+				Value:      "3.25",
+				TTLSeconds: 60,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, decimal",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "-0.01",
+				Mode:  entity.IncrementModeDecimal,
+			},
+			mockDec: &mockRepository{res: "92233720368547758.07", ttl: -1},
+			want: &entity.IncrementResponse{
+				Value: "92233720368547758.07",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "float repo fails",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "1.5",
+				Mode:  entity.IncrementModeFloat,
+			},
+			mockFloat: &mockRepository{err: entity.ErrOverflow},
+			want:      nil,
+			wantErr:   entity.ErrOverflow,
+			assertion: assert.Error,
+		},
+		{
+			name: "decimal repo fails",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "1.5",
+				Mode:  entity.IncrementModeDecimal,
+			},
+			mockDec:   &mockRepository{err: errors.New("some error")},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name: "int value is not an integer",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "1.5",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "int value overflows int64",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "9223372036854775808",
+				Mode:  entity.IncrementModeInt,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "decimal value in exponent notation",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "1e3",
+				Mode:  entity.IncrementModeDecimal,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "float value is missing",
+			req: &entity.IncrementRequest{
+				Key:  "Key",
+				Mode: entity.IncrementModeFloat,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "bounds in float mode",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "1.5",
+				Mode:  entity.IncrementModeFloat,
+				Max:   &max,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "unknown mode",
+			req: &entity.IncrementRequest{
+				Key:   "Key",
+				Value: "1",
+				Mode:  "complex",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := mock_redis.NewMockRepository(ctrl)
+			ttl := time.Duration(tt.req.TTLSeconds) * time.Second
+			if tt.mockFloat != nil {
+				value, _ := tt.req.Value.Float64()
+				repo.EXPECT().
+					AddFloatValueForKey(ctx, tt.req.Key, value, ttl).
+					Return(tt.mockFloat.res, tt.mockFloat.ttl, tt.mockFloat.err)
+			}
+			if tt.mockDec != nil {
+				repo.EXPECT().
+					AddDecimalValueForKey(ctx, tt.req.Key, tt.req.Value.String(), ttl).
+					Return(tt.mockDec.res, tt.mockDec.ttl, tt.mockDec.err)
+			}
+			c := &controller{
+				repository: repo,
+			}
+			got, err := c.Inc(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_IncBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	items := []entity.IncrementItem{{Key: "a", Value: 1}, {Key: "b", Value: 2}}
+	overflow := &entity.PublicError{
+		Message: "increment overflows the stored value",
+		Err:     fmt.Errorf("redis increment failed: ERR increment or decrement would overflow: %w", entity.ErrOverflow),
+	}
+	type mockRepository struct {
+		res []redis.IncrementResult
+		err error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.BatchIncrementRequest
+		mockRepository *mockRepository
+		want           *entity.BatchIncrementResponse
+		wantErr        error
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req:  &entity.BatchIncrementRequest{Atomic: true, Items: items},
+			mockRepository: &mockRepository{
+				res: []redis.IncrementResult{{Value: 1}, {Err: overflow}},
+			},
+			want: &entity.BatchIncrementResponse{
+				Results: []entity.BatchIncrementResult{
+					{Key: "a", Value: "1"},
+					{Key: "b", Error: "increment overflows the stored value"},
+				},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, messages of the internal errors are replaced",
+			req:  &entity.BatchIncrementRequest{Items: items},
+			mockRepository: &mockRepository{
+				res: []redis.IncrementResult{
+					{Err: errors.New("redis increment failed: WRONGTYPE Operation against a key holding the wrong kind of value")},
+					{Err: fmt.Errorf("redis increment failed: connection reset: %w", entity.ErrUnavailable)},
+				},
+			},
+			want: &entity.BatchIncrementResponse{
+				Results: []entity.BatchIncrementResult{
+					{Key: "a", Error: entity.InternalErrorMessage},
+					{Key: "b", Error: entity.UnavailableMessage},
+				},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "repo fails",
+			req:  &entity.BatchIncrementRequest{Items: items},
+			mockRepository: &mockRepository{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "empty batch",
+			req:       &entity.BatchIncrementRequest{},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "batch is too big",
+			req:       &entity.BatchIncrementRequest{Items: make([]entity.IncrementItem, _maxBatchSize+1)},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repo.EXPECT().
+					AddIntValuesForKeys(ctx, tt.req.Items, tt.req.Atomic).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repo,
+				logger:     zap.NewNop(),
+			}
+			got, err := c.IncBatch(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockRepository struct {
+		res string
+		ttl time.Duration
+		err error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.CounterRequest
+		mockRepository *mockRepository
+		want           *entity.IncrementResponse
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req:  &entity.CounterRequest{Key: "Key"},
+			mockRepository: &mockRepository{
+				res: "12",
+				ttl: 30 * time.Second,
+			},
+			want: &entity.IncrementResponse{
+				Value:      "12",
+				TTLSeconds: 30,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "repo fails",
+			req:  &entity.CounterRequest{Key: "Key"},
+			mockRepository: &mockRepository{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repo.EXPECT().
+					GetValueForKey(ctx, tt.req.Key).
+					Return(tt.mockRepository.res, tt.mockRepository.ttl, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repo,
+			}
+			got, err := c.Get(ctx, tt.req)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name      string
+		req       *entity.CounterRequest
+		repoErr   error
+		want      *entity.IncrementResponse
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			req:       &entity.CounterRequest{Key: "Key"},
+			want:      &entity.IncrementResponse{Value: "0"},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "repo fails",
+			req:       &entity.CounterRequest{Key: "Key"},
+			repoErr:   errors.New("some error"),
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.req != nil {
+				repo.EXPECT().
+					DeleteKey(ctx, tt.req.Key).
+					Return(tt.repoErr)
+			}
+			c := &controller{
+				repository: repo,
+			}
+			got, err := c.Reset(ctx, tt.req)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,8 +2,12 @@ package controller
 
 import (
 	"go.uber.org/fx"
+	"redis-postgres-service/controller/httpsig"
+	"redis-postgres-service/controller/idempotency"
 	"redis-postgres-service/controller/incremental"
+	"redis-postgres-service/controller/ratelimit"
 	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/controller/tokens"
 	"redis-postgres-service/controller/users"
 )
 
@@ -11,4 +15,8 @@ var Module = fx.Options(
 	fx.Provide(incremental.New),
 	fx.Provide(users.New),
 	fx.Provide(sign.New),
+	fx.Provide(httpsig.New),
+	fx.Provide(tokens.New),
+	fx.Provide(ratelimit.New),
+	fx.Provide(idempotency.New),
 )
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/repository/redis"
+	"time"
+)
+
+const (
+	_configKey = "rate_limit"
+	_keyPrefix = "ratelimit"
+)
+
+type Controller interface {
+	Allow(ctx context.Context, req *entity.RateLimitRequest) (*entity.RateLimitResponse, error)
+}
+
+// compile time check that controller implements Controller interface
+var _ Controller = (*controller)(nil)
+
+// Params is an fx container for all Controller dependencies
+type Params struct {
+	fx.In
+
+	Repository     redis.Repository
+	ConfigProvider config.Provider
+}
+
+// New is a constructor provided to the fx for creating a Controller
+func New(p Params) (Controller, error) {
+	var cfg internalconfig.RateLimitConfig
+	err := p.ConfigProvider.Get(_configKey).Populate(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	if cfg.Enabled && cfg.Window <= 0 {
+		return nil, errors.New("rate limit window must be positive")
+	}
+	return &controller{
+		repository: p.Repository,
+		config:     cfg,
+		now:        time.Now,
+	}, nil
+}
+
+type controller struct {
+	repository redis.Repository
+	config     internalconfig.RateLimitConfig
+	now        func() time.Time
+}
+
+// Allow counts the request of the client to the route in the sliding window shared by all the service instances
+// and reports whether the request is within the configured limit of the route. Peek requests are only checked,
+// so that the callers can count some of the requests, e.g. the failed ones. Routes without the limit are
+// always allowed and not counted.
+func (c *controller) Allow(ctx context.Context, req *entity.RateLimitRequest) (*entity.RateLimitResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	limit := c.limit(req.Route)
+	if limit <= 0 {
+		return &entity.RateLimitResponse{Allowed: true}, nil
+	}
+	key := fmt.Sprintf("%s:%s:%s", _keyPrefix, req.Route, req.Client)
+	window := time.Duration(c.config.Window) * time.Second
+	hit := c.repository.HitSlidingWindow
+	if req.Peek {
+		hit = c.repository.PeekSlidingWindow
+	}
+	res, err := hit(ctx, key, limit, window, c.now())
+	if err != nil {
+		return nil, err
+	}
+	// the log may hold more hits than the limit right after the limit is lowered
+	remaining := limit - res.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &entity.RateLimitResponse{
+		Allowed:   res.Allowed,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     res.Reset,
+	}, nil
+}
+
+// limit returns the limit of the route, 0 if the rate limiting is disabled
+func (c *controller) limit(route string) int64 {
+	if !c.config.Enabled {
+		return 0
+	}
+	if limit, ok := c.config.Routes[route]; ok {
+		return limit
+	}
+	return c.config.Limit
+}
@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	mock_redis "redis-postgres-service/mocks/repository/redis"
+	"redis-postgres-service/repository/redis"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			yaml:      `{"rate_limit":{"enabled":true,"window":60,"limit":10}}`,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Rate limiting is not configured",
+			yaml:      `{}`,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Window is not positive",
+			yaml:      `{"rate_limit":{"enabled":true,"window":0,"limit":10}}`,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			provider, err := config.NewYAML(config.Source(strings.NewReader(tt.yaml)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = New(Params{
+				Repository:     mock_redis.NewMockRepository(ctrl),
+				ConfigProvider: provider,
+			})
+			tt.assertion(t, err)
+		})
+	}
+}
+
+func Test_controller_Allow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cfg := internalconfig.RateLimitConfig{
+		Enabled: true,
+		Window:  60,
+		Limit:   10,
+		Routes:  map[string]int64{"/bulk": 2, "/unlimited": 0},
+	}
+	type mockRepository struct {
+		peek  bool
+		key   string
+		limit int64
+		res   redis.SlidingWindowResult
+		err   error
+	}
+	tests := []struct {
+		name           string
+		config         internalconfig.RateLimitConfig
+		req            *entity.RateLimitRequest
+		mockRepository *mockRepository
+		want           *entity.RateLimitResponse
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "Happy path, default limit",
+			config: cfg,
+			req:    &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:127.0.0.1"},
+			mockRepository: &mockRepository{
+				key:   "ratelimit:/redis/incr:ip:127.0.0.1",
+				limit: 10,
+				res:   redis.SlidingWindowResult{Allowed: true, Count: 3, Reset: time.Minute},
+			},
+			want:      &entity.RateLimitResponse{Allowed: true, Limit: 10, Remaining: 7, Reset: time.Minute},
+			assertion: assert.NoError,
+		},
+		{
+			name:   "Route limit is exceeded",
+			config: cfg,
+			req:    &entity.RateLimitRequest{Route: "/bulk", Client: "principal:client"},
+			mockRepository: &mockRepository{
+				key:   "ratelimit:/bulk:principal:client",
+				limit: 2,
+				res:   redis.SlidingWindowResult{Allowed: false, Count: 2, Reset: 10 * time.Second},
+			},
+			want:      &entity.RateLimitResponse{Allowed: false, Limit: 2, Remaining: 0, Reset: 10 * time.Second},
+			assertion: assert.NoError,
+		},
+		{
+			name:   "Happy path, request is only checked",
+			config: cfg,
+			req:    &entity.RateLimitRequest{Route: "auth", Client: "ip:127.0.0.1", Peek: true},
+			mockRepository: &mockRepository{
+				peek:  true,
+				key:   "ratelimit:auth:ip:127.0.0.1",
+				limit: 10,
+				res:   redis.SlidingWindowResult{Allowed: true, Count: 3, Reset: time.Minute},
+			},
+			want:      &entity.RateLimitResponse{Allowed: true, Limit: 10, Remaining: 7, Reset: time.Minute},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Route is not limited",
+			config:    cfg,
+			req:       &entity.RateLimitRequest{Route: "/unlimited", Client: "ip:127.0.0.1"},
+			want:      &entity.RateLimitResponse{Allowed: true},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Rate limiting is disabled",
+			config:    internalconfig.RateLimitConfig{Window: 60, Limit: 10},
+			req:       &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:127.0.0.1"},
+			want:      &entity.RateLimitResponse{Allowed: true},
+			assertion: assert.NoError,
+		},
+		{
+			name:   "Repo fails",
+			config: cfg,
+			req:    &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:127.0.0.1"},
+			mockRepository: &mockRepository{
+				key:   "ratelimit:/redis/incr:ip:127.0.0.1",
+				limit: 10,
+				err:   errors.New("some error"),
+			},
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			config:    cfg,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_redis.NewMockRepository(ctrl)
+			if tt.mockRepository != nil && tt.mockRepository.peek {
+				repo.EXPECT().
+					PeekSlidingWindow(gomock.Any(), tt.mockRepository.key, tt.mockRepository.limit, time.Minute, now).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			} else if tt.mockRepository != nil {
+				repo.EXPECT().
+					HitSlidingWindow(gomock.Any(), tt.mockRepository.key, tt.mockRepository.limit, time.Minute, now).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repo,
+				config:     tt.config,
+				now:        func() time.Time { return now },
+			}
+			got, err := c.Allow(context.Background(), tt.req)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,51 +2,260 @@ package sign
 
 import (
 	"context"
-	"github.com/pkg/errors"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+	"io"
 	"redis-postgres-service/entity"
-	"redis-postgres-service/gateway/sha512"
+	"redis-postgres-service/gateway/signer"
+	"redis-postgres-service/metrics"
+	"redis-postgres-service/repository/keystore"
 )
 
 type Controller interface {
 	Sign(ctx context.Context, req *entity.SignRequest) (*entity.SignResponse, error)
+	SignStream(ctx context.Context, req *entity.SignStreamRequest) (*entity.SignResponse, error)
+	Verify(ctx context.Context, req *entity.VerifyRequest) (*entity.VerifyResponse, error)
+	PublicKeys(ctx context.Context) (*entity.JWKS, error)
 }
 
 // Params is an fx container for all Controller dependencies
 type Params struct {
 	fx.In
 
-	Gateway sha512.Gateway
-	Logger  *zap.Logger
+	Gateway  signer.Gateway
+	Keystore keystore.Keystore
+	Logger   *zap.Logger
+	Metrics  metrics.Registry
 }
 
 // New is a constructor provided to the fx for creating a Controller
 func New(p Params) (Controller, error) {
+	signatures := newSignatures()
+	if err := p.Metrics.Register(signatures); err != nil {
+		return nil, fmt.Errorf("failed to register metrics: %s", err)
+	}
 	return &controller{
-		logger:  p.Logger,
-		gateway: p.Gateway,
+		logger:     p.Logger,
+		gateway:    p.Gateway,
+		keystore:   p.Keystore,
+		signatures: signatures,
 	}, nil
 }
 
+// newSignatures returns the counter of the signatures made by the algorithm, including the streamed ones
+func newSignatures() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "signatures_total",
+		Help: "Number of the signatures made by algorithm.",
+	}, []string{"algorithm"})
+}
+
 // compile time check that controller implements Controller interface
 var _ Controller = (*controller)(nil)
 
 type controller struct {
-	logger  *zap.Logger
-	gateway sha512.Gateway
+	logger     *zap.Logger
+	gateway    signer.Gateway
+	keystore   keystore.Keystore
+	signatures *prometheus.CounterVec
 }
 
-// Sign returns SHA512 signature of text in the request using the key in the request
+// Sign returns the signature of text in the request using the key and the algorithm in the request.
+// The key is either provided in the request or referenced by id in the keystore.
 func (c *controller) Sign(ctx context.Context, req *entity.SignRequest) (*entity.SignResponse, error) {
 	if req == nil {
 		return nil, errors.New("nil request")
 	}
-	s, err := c.gateway.SignHMACSHA512(ctx, req.Key, req.Text)
+	if err := signer.CheckEncoding(req.Encoding); err != nil {
+		return nil, err
+	}
+	keys, err := c.keys(ctx, req.Algorithm, req.Key, req.KeyID, req.KeyVersion, false, req.Internal)
 	if err != nil {
-		return nil, errors.Errorf("failed to sha512: %s", err)
+		return nil, err
 	}
-	return &entity.SignResponse{
-		Hex: s,
-	}, nil
+	s, err := c.gateway.Sign(ctx, req.Algorithm, keys[0].Secret, []byte(req.Text))
+	if err != nil {
+		return nil, err
+	}
+	c.signatures.WithLabelValues(req.Algorithm).Inc()
+	return signResponse(req.Algorithm, req.Encoding, keys[0], s)
+}
+
+// SignStream returns the signature of the body in the request. The body is copied into the signature stream
+// in chunks, so the memory consumption doesn't depend on the body size.
+func (c *controller) SignStream(ctx context.Context, req *entity.SignStreamRequest) (*entity.SignResponse, error) {
+	if req == nil || req.Body == nil {
+		return nil, errors.New("nil request")
+	}
+	if err := signer.CheckEncoding(req.Encoding); err != nil {
+		return nil, err
+	}
+	keys, err := c.keys(ctx, req.Algorithm, req.Key, req.KeyID, req.KeyVersion, false, false)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.gateway.NewStream(ctx, req.Algorithm, keys[0].Secret)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(stream, req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", entity.UnableToReadTheBody, err, entity.ErrInvalidArgument)
+	}
+	s, err := stream.Signature()
+	if err != nil {
+		return nil, err
+	}
+	c.signatures.WithLabelValues(req.Algorithm).Inc()
+	return signResponse(req.Algorithm, req.Encoding, keys[0], s)
+}
+
+// Verify checks that the signature in the request matches the text signed with the key and the algorithm
+// in the request. Signatures of the symmetric algorithms are compared in constant time, so that the response time
+// doesn't reveal how much of the signature is correct. If the version of the server-side key is not provided, the signature
+// is checked against all of its versions, so that the signatures made before the rotation remain valid.
+func (c *controller) Verify(ctx context.Context, req *entity.VerifyRequest) (*entity.VerifyResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	claimed, err := signer.Decode(req.Encoding, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) == 0 {
+		return nil, fmt.Errorf("signature must not be empty: %w", entity.ErrInvalidArgument)
+	}
+	keys, err := c.keys(ctx, req.Algorithm, req.Key, req.KeyID, req.KeyVersion, true, req.Internal)
+	if err != nil {
+		return nil, err
+	}
+	response := &entity.VerifyResponse{
+		Algorithm: req.Algorithm,
+		KeyID:     req.KeyID,
+	}
+	for _, key := range keys {
+		valid, err := c.gateway.Verify(ctx, req.Algorithm, key.Secret, []byte(req.Text), claimed)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			response.Valid = true
+			response.KeyVersion = key.Version
+			break
+		}
+	}
+	return response, nil
+}
+
+// PublicKeys returns the public keys of all the versions of the server-side asymmetric keys,
+// so that the signatures can be verified without sharing the secret. Key id of the JWK is
+// the id of the server-side key followed by the version, e.g. `webhooks.2`.
+func (c *controller) PublicKeys(ctx context.Context) (*entity.JWKS, error) {
+	keys, err := c.keystore.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jwks := &entity.JWKS{Keys: []entity.JWK{}}
+	for _, key := range keys {
+		if key.Algorithm == "" {
+			continue
+		}
+		jwk, err := c.gateway.PublicKey(ctx, key.Algorithm, key.Secret)
+		if errors.Is(err, signer.ErrNoPublicKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s version %d: %w", key.ID, key.Version, err)
+		}
+		jwk.KeyID = fmt.Sprintf("%s.%d", key.ID, key.Version)
+		jwks.Keys = append(jwks.Keys, *jwk)
+	}
+	return jwks, nil
+}
+
+// signResponse returns the response with the signature in the encoding, hex encoded signature
+// is duplicated in the Hex field for the backward compatibility
+func signResponse(algorithm, encoding string, key keystore.Key, signature []byte) (*entity.SignResponse, error) {
+	if encoding == "" {
+		encoding = signer.EncodingHex
+	}
+	encoded, err := signer.Encode(encoding, signature)
+	if err != nil {
+		return nil, err
+	}
+	response := &entity.SignResponse{
+		Algorithm:  algorithm,
+		Encoding:   encoding,
+		Signature:  encoded,
+		KeyID:      key.ID,
+		KeyVersion: key.Version,
+	}
+	if encoding == signer.EncodingHex {
+		response.Hex = encoded
+	}
+	return response, nil
+}
+
+// keys returns the keys to sign the request with. Raw key provided in the request is returned as is,
+// otherwise the server-side key is read from the keystore: the pinned or the current version,
+// or all the versions if allVersions is set and the version is not pinned. Server-side keys restricted
+// to another algorithm are rejected. Empty keys are rejected too, cause anyone can forge the signatures made with them.
+// The internal requests only use the internal server-side keys, while the rest of the requests can't use them,
+// so that the keys authenticating the clients and signing the tokens are not reachable by the signing endpoints.
+func (c *controller) keys(
+	ctx context.Context,
+	algorithm string,
+	rawKey string,
+	keyID string,
+	version int,
+	allVersions bool,
+	internal bool,
+) ([]keystore.Key, error) {
+	if rawKey != "" && keyID != "" {
+		return nil, fmt.Errorf("either key or key_id must be provided: %w", entity.ErrInvalidArgument)
+	}
+	if internal && keyID == "" {
+		return nil, fmt.Errorf("internal requests require key_id: %w", entity.ErrInvalidArgument)
+	}
+	if keyID == "" {
+		if version != 0 {
+			return nil, fmt.Errorf("key_version requires key_id: %w", entity.ErrInvalidArgument)
+		}
+		if rawKey == "" {
+			return nil, fmt.Errorf("either key or key_id must be provided: %w", entity.ErrInvalidArgument)
+		}
+		return []keystore.Key{{Secret: []byte(rawKey)}}, nil
+	}
+	var keys []keystore.Key
+	if allVersions && version == 0 {
+		versions, err := c.keystore.Versions(ctx, keyID)
+		if err != nil {
+			return nil, err
+		}
+		keys = versions
+	} else {
+		key, err := c.keystore.Key(ctx, keyID, version)
+		if err != nil {
+			return nil, err
+		}
+		keys = []keystore.Key{*key}
+	}
+	for _, key := range keys {
+		if key.Internal != internal {
+			// internal keys are reported as missing, so that their ids are not revealed by the signing endpoints
+			return nil, fmt.Errorf("signing key %s: %w", keyID, entity.ErrNotFound)
+		}
+		if len(key.Secret) == 0 {
+			return nil, fmt.Errorf("signing key %s version %d is empty: %w", keyID, key.Version, entity.ErrInvalidArgument)
+		}
+		if key.Algorithm != "" && key.Algorithm != algorithm {
+			return nil, fmt.Errorf(
+				"signing key %s is restricted to %s algorithm: %w", keyID, key.Algorithm, entity.ErrInvalidArgument,
+			)
+		}
+	}
+	return keys, nil
 }
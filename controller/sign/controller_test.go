@@ -1,72 +1,318 @@
 package sign
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"redis-postgres-service/entity"
-	mock_sha512 "redis-postgres-service/mocks/gateway/sha512"
+	"redis-postgres-service/gateway/signer"
+	mock_signer "redis-postgres-service/mocks/gateway/signer"
+	mock_keystore "redis-postgres-service/mocks/repository/keystore"
+	"redis-postgres-service/repository/keystore"
+	"strings"
 	"testing"
 )
 
 func TestNew(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
-	gw := mock_sha512.NewMockGateway(ctrl)
+	gw := mock_signer.NewMockGateway(ctrl)
+	ks := mock_keystore.NewMockKeystore(ctrl)
+	registry := prometheus.NewRegistry()
 	c, err := New(Params{
-		Logger:  zap.NewNop(),
-		Gateway: gw,
+		Logger:   zap.NewNop(),
+		Gateway:  gw,
+		Keystore: ks,
+		Metrics:  registry,
 	})
 	assert.NotNil(t, c)
 	assert.NoError(t, err)
+	// the same metrics can't be registered twice
+	_, err = New(Params{Logger: zap.NewNop(), Gateway: gw, Keystore: ks, Metrics: registry})
+	assert.Error(t, err)
 }
 
 func Test_controller_Sign(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	type mockGateway struct {
-		res string
+		key []byte
+		res []byte
+		err error
+	}
+	type mockKeystore struct {
+		key *keystore.Key
 		err error
 	}
 	type args struct {
 		req *entity.SignRequest
 	}
 	tests := []struct {
-		name        string
-		args        args
-		mockGateway *mockGateway
-		want        *entity.SignResponse
-		assertion   assert.ErrorAssertionFunc
+		name         string
+		args         args
+		mockKeystore *mockKeystore
+		mockGateway  *mockGateway
+		want         *entity.SignResponse
+		wantErr      error
+		assertion    assert.ErrorAssertionFunc
 	}{
 		{
 			name: "Happy path",
 			args: args{
 				&entity.SignRequest{
-					Text: "123",
-					Key:  "key",
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					Key:       "key",
 				},
 			},
 			mockGateway: &mockGateway{
-				res: "2ea823c645b1baf845ef76096a6d7fa9e568304ba9f7910bd52f01c03eec39cdfeec54e50b86b62ef5bfb9e6ce5c0be747ec13b3a199f9d235e99a36de369a84",
+				key: []byte("key"),
+				res: []byte{0x2e, 0xa8, 0x23},
 				err: nil,
 			},
 			want: &entity.SignResponse{
-				Hex: "2ea823c645b1baf845ef76096a6d7fa9e568304ba9f7910bd52f01c03eec39cdfeec54e50b86b62ef5bfb9e6ce5c0be747ec13b3a199f9d235e99a36de369a84",
+				Algorithm: "hmacsha512",
+				Encoding:  "hex",
+				Signature: "2ea823",
+				Hex:       "2ea823",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, server-side key",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					KeyID:     "payments",
+				},
+			},
+			mockKeystore: &mockKeystore{
+				key: &keystore.Key{ID: "payments", Version: 2, Secret: []byte("secret")},
+			},
+			mockGateway: &mockGateway{
+				key: []byte("secret"),
+				res: []byte{0x2e, 0xa8, 0x23},
+			},
+			want: &entity.SignResponse{
+				Algorithm:  "hmacsha512",
+				Encoding:   "hex",
+				Signature:  "2ea823",
+				Hex:        "2ea823",
+				KeyID:      "payments",
+				KeyVersion: 2,
 			},
 			assertion: assert.NoError,
 		},
+		{
+			name: "Happy path, base64url encoding",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					Key:       "key",
+					Encoding:  "base64url",
+				},
+			},
+			mockGateway: &mockGateway{
+				key: []byte("key"),
+				res: []byte{0xfb, 0xff, 0x00},
+			},
+			want: &entity.SignResponse{
+				Algorithm: "hmacsha512",
+				Encoding:  "base64url",
+				Signature: "-_8A",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, raw encoding",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					Key:       "key",
+					Encoding:  "raw",
+				},
+			},
+			mockGateway: &mockGateway{
+				key: []byte("key"),
+				res: []byte{0xfb, 0xff, 0x00},
+			},
+			want: &entity.SignResponse{
+				Algorithm: "hmacsha512",
+				Encoding:  "raw",
+				Signature: "\xfb\xff\x00",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Unknown encoding",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					Key:       "key",
+					Encoding:  "base32",
+				},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Server-side key is not found",
+			args: args{
+				&entity.SignRequest{
+					Algorithm:  "hmacsha512",
+					Text:       "123",
+					KeyID:      "payments",
+					KeyVersion: 7,
+				},
+			},
+			mockKeystore: &mockKeystore{
+				err: entity.ErrNotFound,
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name: "Server-side key is restricted to another algorithm",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					KeyID:     "webhooks",
+				},
+			},
+			mockKeystore: &mockKeystore{
+				key: &keystore.Key{ID: "webhooks", Version: 1, Algorithm: "ed25519", Secret: []byte("private key")},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Both key and key id",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					Key:       "key",
+					KeyID:     "payments",
+				},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Key version without key id",
+			args: args{
+				&entity.SignRequest{
+					Algorithm:  "hmacsha512",
+					Text:       "123",
+					Key:        "key",
+					KeyVersion: 1,
+				},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Internal key is not reachable by the external requests",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					KeyID:     "tokens",
+				},
+			},
+			mockKeystore: &mockKeystore{
+				key: &keystore.Key{ID: "tokens", Version: 1, Secret: []byte("secret"), Internal: true},
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name: "Internal request doesn't use the external keys",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					KeyID:     "payments",
+					Internal:  true,
+				},
+			},
+			mockKeystore: &mockKeystore{
+				key: &keystore.Key{ID: "payments", Version: 1, Secret: []byte("secret")},
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name: "Internal request doesn't use the raw keys",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					Key:       "key",
+					Internal:  true,
+				},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Empty key",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+				},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Server-side key is empty",
+			args: args{
+				&entity.SignRequest{
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					KeyID:     "payments",
+				},
+			},
+			mockKeystore: &mockKeystore{
+				key: &keystore.Key{ID: "payments", Version: 1},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
 		{
 			name: "Gateway fails",
 			args: args{
 				&entity.SignRequest{
-					Text: "123",
-					Key:  "key",
+					Algorithm: "hmacsha512",
+					Text:      "123",
+					Key:       "key",
 				},
 			},
 			mockGateway: &mockGateway{
-				res: "",
+				key: []byte("key"),
+				res: nil,
 				err: errors.New("some error"),
 			},
 			want:      nil,
@@ -84,22 +330,556 @@ func Test_controller_Sign(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			gatewayMock := mock_sha512.NewMockGateway(ctrl)
+			keystoreMock := mock_keystore.NewMockKeystore(ctrl)
+			if tt.mockKeystore != nil {
+				keystoreMock.EXPECT().
+					Key(ctx, tt.args.req.KeyID, tt.args.req.KeyVersion).
+					Return(tt.mockKeystore.key, tt.mockKeystore.err)
+			}
+			gatewayMock := mock_signer.NewMockGateway(ctrl)
 			if tt.mockGateway != nil {
 				gatewayMock.EXPECT().
-					SignHMACSHA512(
+					Sign(
 						ctx,
-						gomock.Any(),
-						gomock.Any(),
+						tt.args.req.Algorithm,
+						tt.mockGateway.key,
+						[]byte(tt.args.req.Text),
 					).
 					Return(tt.mockGateway.res, tt.mockGateway.err)
 			}
 			c := &controller{
-				logger:  zap.NewNop(),
-				gateway: gatewayMock,
+				logger:     zap.NewNop(),
+				gateway:    gatewayMock,
+				keystore:   keystoreMock,
+				signatures: newSignatures(),
 			}
 			got, err := c.Sign(ctx, tt.args.req)
 			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.args.req != nil {
+				wantSignatures := 0.0
+				if err == nil {
+					wantSignatures = 1
+				}
+				assert.Equal(t, wantSignatures, testutil.ToFloat64(c.signatures.WithLabelValues(tt.args.req.Algorithm)))
+			}
+		})
+	}
+}
+
+// bufferStream is a signer.Stream that returns the streamed message as the signature
+type bufferStream struct {
+	bytes.Buffer
+	err error
+}
+
+func (s *bufferStream) Signature() ([]byte, error) {
+	return s.Bytes(), s.err
+}
+
+// failingReader is a body that fails to be read
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func Test_controller_SignStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockGateway struct {
+		key    []byte
+		stream *bufferStream
+		err    error
+	}
+	type mockKeystore struct {
+		key *keystore.Key
+		err error
+	}
+	tests := []struct {
+		name         string
+		req          *entity.SignStreamRequest
+		mockKeystore *mockKeystore
+		mockGateway  *mockGateway
+		want         *entity.SignResponse
+		wantErr      error
+		assertion    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req: &entity.SignStreamRequest{
+				Algorithm: "hmacsha512",
+				Key:       "key",
+				Body:      strings.NewReader("123"),
+			},
+			mockGateway: &mockGateway{
+				key:    []byte("key"),
+				stream: &bufferStream{},
+			},
+			want: &entity.SignResponse{
+				Algorithm: "hmacsha512",
+				Encoding:  "hex",
+				Signature: "313233",
+				Hex:       "313233",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, server-side key",
+			req: &entity.SignStreamRequest{
+				Algorithm: "hmacsha512",
+				KeyID:     "payments",
+				Body:      strings.NewReader("123"),
+			},
+			mockKeystore: &mockKeystore{
+				key: &keystore.Key{ID: "payments", Version: 2, Secret: []byte("secret")},
+			},
+			mockGateway: &mockGateway{
+				key:    []byte("secret"),
+				stream: &bufferStream{},
+			},
+			want: &entity.SignResponse{
+				Algorithm:  "hmacsha512",
+				Encoding:   "hex",
+				Signature:  "313233",
+				Hex:        "313233",
+				KeyID:      "payments",
+				KeyVersion: 2,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Server-side key is not found",
+			req: &entity.SignStreamRequest{
+				Algorithm: "hmacsha512",
+				KeyID:     "payments",
+				Body:      strings.NewReader("123"),
+			},
+			mockKeystore: &mockKeystore{
+				err: entity.ErrNotFound,
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name: "Algorithm doesn't support streaming",
+			req: &entity.SignStreamRequest{
+				Algorithm: "ed25519",
+				Key:       "key",
+				Body:      strings.NewReader("123"),
+			},
+			mockGateway: &mockGateway{
+				key: []byte("key"),
+				err: entity.ErrInvalidArgument,
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Body read fails",
+			req: &entity.SignStreamRequest{
+				Algorithm: "hmacsha512",
+				Key:       "key",
+				Body:      failingReader{},
+			},
+			mockGateway: &mockGateway{
+				key:    []byte("key"),
+				stream: &bufferStream{},
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Signature fails",
+			req: &entity.SignStreamRequest{
+				Algorithm: "rsa-pss-sha512",
+				Key:       "key",
+				Body:      strings.NewReader("123"),
+			},
+			mockGateway: &mockGateway{
+				key:    []byte("key"),
+				stream: &bufferStream{err: errors.New("some error")},
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			keystoreMock := mock_keystore.NewMockKeystore(ctrl)
+			if tt.mockKeystore != nil {
+				keystoreMock.EXPECT().
+					Key(ctx, tt.req.KeyID, tt.req.KeyVersion).
+					Return(tt.mockKeystore.key, tt.mockKeystore.err)
+			}
+			gatewayMock := mock_signer.NewMockGateway(ctrl)
+			if tt.mockGateway != nil {
+				var stream signer.Stream
+				if tt.mockGateway.stream != nil {
+					stream = tt.mockGateway.stream
+				}
+				gatewayMock.EXPECT().
+					NewStream(ctx, tt.req.Algorithm, tt.mockGateway.key).
+					Return(stream, tt.mockGateway.err)
+			}
+			c := &controller{
+				logger:     zap.NewNop(),
+				gateway:    gatewayMock,
+				keystore:   keystoreMock,
+				signatures: newSignatures(),
+			}
+			got, err := c.SignStream(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.req != nil {
+				wantSignatures := 0.0
+				if err == nil {
+					wantSignatures = 1
+				}
+				assert.Equal(t, wantSignatures, testutil.ToFloat64(c.signatures.WithLabelValues(tt.req.Algorithm)))
+			}
+		})
+	}
+}
+
+func Test_controller_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockGateway struct {
+		key   []byte
+		valid bool
+		err   error
+	}
+	type mockKeystore struct {
+		key      *keystore.Key
+		versions []keystore.Key
+		err      error
+	}
+	tests := []struct {
+		name         string
+		req          *entity.VerifyRequest
+		mockKeystore *mockKeystore
+		mockGateway  []mockGateway
+		want         *entity.VerifyResponse
+		wantErr      error
+		assertion    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path, valid signature",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Text:      "123",
+				Key:       "key",
+				Signature: "2EA823",
+			},
+			mockGateway: []mockGateway{
+				{key: []byte("key"), valid: true},
+			},
+			want: &entity.VerifyResponse{
+				Algorithm: "hmacsha512",
+				Valid:     true,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, invalid signature",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Text:      "123",
+				Key:       "key",
+				Signature: "2ea8",
+			},
+			mockGateway: []mockGateway{
+				{key: []byte("key"), valid: false},
+			},
+			want: &entity.VerifyResponse{
+				Algorithm: "hmacsha512",
+				Valid:     false,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, base64 signature",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Text:      "123",
+				Key:       "key",
+				Signature: "LqgjxQ==",
+				Encoding:  "base64",
+			},
+			mockGateway: []mockGateway{
+				{key: []byte("key"), valid: true},
+			},
+			want: &entity.VerifyResponse{
+				Algorithm: "hmacsha512",
+				Valid:     true,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Raw signature",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Text:      "123",
+				Key:       "key",
+				Signature: "2ea823",
+				Encoding:  "raw",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Happy path, signed with the previous version of the server-side key",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Text:      "123",
+				KeyID:     "payments",
+				Signature: "2ea823",
+			},
+			mockKeystore: &mockKeystore{
+				versions: []keystore.Key{
+					{ID: "payments", Version: 2, Secret: []byte("new")},
+					{ID: "payments", Version: 1, Secret: []byte("old")},
+				},
+			},
+			mockGateway: []mockGateway{
+				{key: []byte("new"), valid: false},
+				{key: []byte("old"), valid: true},
+			},
+			want: &entity.VerifyResponse{
+				Algorithm:  "hmacsha512",
+				Valid:      true,
+				KeyID:      "payments",
+				KeyVersion: 1,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Happy path, pinned version of the server-side key doesn't match",
+			req: &entity.VerifyRequest{
+				Algorithm:  "hmacsha512",
+				Text:       "123",
+				KeyID:      "payments",
+				KeyVersion: 2,
+				Signature:  "2ea823",
+			},
+			mockKeystore: &mockKeystore{
+				key: &keystore.Key{ID: "payments", Version: 2, Secret: []byte("new")},
+			},
+			mockGateway: []mockGateway{
+				{key: []byte("new"), valid: false},
+			},
+			want: &entity.VerifyResponse{
+				Algorithm: "hmacsha512",
+				Valid:     false,
+				KeyID:     "payments",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Server-side key is not found",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Text:      "123",
+				KeyID:     "payments",
+				Signature: "2ea823",
+			},
+			mockKeystore: &mockKeystore{
+				err: entity.ErrNotFound,
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name: "Both key and key id",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Key:       "key",
+				KeyID:     "payments",
+				Signature: "2ea823",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Signature is not hex",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Signature: "xyz",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Signature is empty",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name: "Gateway fails",
+			req: &entity.VerifyRequest{
+				Algorithm: "md5",
+				Key:       "key",
+				Signature: "2ea823",
+			},
+			mockGateway: []mockGateway{
+				{key: []byte("key"), err: errors.New("some error")},
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name: "Empty key",
+			req: &entity.VerifyRequest{
+				Algorithm: "hmacsha512",
+				Text:      "123",
+				Signature: "2ea823",
+			},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			keystoreMock := mock_keystore.NewMockKeystore(ctrl)
+			if tt.mockKeystore != nil {
+				if tt.req.KeyVersion == 0 {
+					keystoreMock.EXPECT().
+						Versions(ctx, tt.req.KeyID).
+						Return(tt.mockKeystore.versions, tt.mockKeystore.err)
+				} else {
+					keystoreMock.EXPECT().
+						Key(ctx, tt.req.KeyID, tt.req.KeyVersion).
+						Return(tt.mockKeystore.key, tt.mockKeystore.err)
+				}
+			}
+			gatewayMock := mock_signer.NewMockGateway(ctrl)
+			for _, call := range tt.mockGateway {
+				signature, _ := signer.Decode(tt.req.Encoding, tt.req.Signature)
+				gatewayMock.EXPECT().
+					Verify(ctx, tt.req.Algorithm, call.key, []byte(tt.req.Text), signature).
+					Return(call.valid, call.err)
+			}
+			c := &controller{
+				logger:   zap.NewNop(),
+				gateway:  gatewayMock,
+				keystore: keystoreMock,
+			}
+			got, err := c.Verify(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_PublicKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockGateway struct {
+		key *keystore.Key
+		res *entity.JWK
+		err error
+	}
+	hmacKey := keystore.Key{ID: "partner-a", Version: 1, Secret: []byte("secret")}
+	pinnedHMACKey := keystore.Key{ID: "partner-b", Version: 1, Algorithm: "hmacsha512", Secret: []byte("secret")}
+	ed25519Key := keystore.Key{ID: "webhooks", Version: 2, Algorithm: "ed25519", Secret: []byte("private key")}
+	tests := []struct {
+		name         string
+		keys         []keystore.Key
+		keystoreErr  error
+		mockGateways []mockGateway
+		want         *entity.JWKS
+		assertion    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			keys: []keystore.Key{hmacKey, pinnedHMACKey, ed25519Key},
+			mockGateways: []mockGateway{
+				{key: &pinnedHMACKey, err: signer.ErrNoPublicKey},
+				{key: &ed25519Key, res: &entity.JWK{KeyType: "OKP", Algorithm: "EdDSA", Use: "sig", Curve: "Ed25519", X: "x"}},
+			},
+			want: &entity.JWKS{
+				Keys: []entity.JWK{
+					{KeyType: "OKP", KeyID: "webhooks.2", Algorithm: "EdDSA", Use: "sig", Curve: "Ed25519", X: "x"},
+				},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, no keys",
+			keys:      nil,
+			want:      &entity.JWKS{Keys: []entity.JWK{}},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Private key is malformed",
+			keys: []keystore.Key{ed25519Key},
+			mockGateways: []mockGateway{
+				{key: &ed25519Key, err: entity.ErrInvalidArgument},
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:        "Keystore fails",
+			keystoreErr: errors.New("some error"),
+			want:        nil,
+			assertion:   assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			keystoreMock := mock_keystore.NewMockKeystore(ctrl)
+			keystoreMock.EXPECT().Keys(ctx).Return(tt.keys, tt.keystoreErr)
+			gatewayMock := mock_signer.NewMockGateway(ctrl)
+			for _, call := range tt.mockGateways {
+				gatewayMock.EXPECT().
+					PublicKey(ctx, call.key.Algorithm, call.key.Secret).
+					Return(call.res, call.err)
+			}
+			c := &controller{
+				logger:   zap.NewNop(),
+				gateway:  gatewayMock,
+				keystore: keystoreMock,
+			}
+			got, err := c.PublicKeys(ctx)
+			tt.assertion(t, err)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -0,0 +1,409 @@
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/gateway/signer"
+	"redis-postgres-service/repository/keystore"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const _configKey = "tokens"
+
+const (
+	_tokenType        = "JWT"
+	_defaultAlgorithm = "HS512"
+)
+
+// _algorithms maps the names of the JWS algorithms to the names of the gateway algorithms
+var _algorithms = map[string]string{
+	"HS256": signer.HMACSHA256,
+	"HS384": signer.HMACSHA384,
+	"HS512": signer.HMACSHA512,
+	"EdDSA": signer.Ed25519,
+	"ES256": signer.ECDSAP256SHA256,
+	"PS256": signer.RSAPSSSHA256,
+	"PS512": signer.RSAPSSSHA512,
+}
+
+// _registeredClaims are set by the controller and can't be overridden by the custom claims
+var _registeredClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+type Controller interface {
+	Issue(ctx context.Context, req *entity.IssueTokenRequest) (*entity.IssueTokenResponse, error)
+	Verify(ctx context.Context, req *entity.VerifyTokenRequest) (*entity.VerifyTokenResponse, error)
+}
+
+// Params is an fx container for all Controller dependencies
+type Params struct {
+	fx.In
+
+	SignCtrl       sign.Controller
+	Keystore       keystore.Keystore
+	ConfigProvider config.Provider
+	Logger         *zap.Logger
+}
+
+// New is a constructor provided to the fx for creating a Controller
+func New(p Params) (Controller, error) {
+	var cfg internalconfig.TokensConfig
+	err := p.ConfigProvider.Get(_configKey).Populate(&cfg)
+	if err != nil {
+		return nil, errors.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = _defaultAlgorithm
+	}
+	if _, ok := _algorithms[cfg.Algorithm]; !ok {
+		return nil, errors.Errorf("unsupported default token algorithm %s", cfg.Algorithm)
+	}
+	return &controller{
+		logger:   p.Logger,
+		signCtrl: p.SignCtrl,
+		keystore: p.Keystore,
+		config:   cfg,
+		now:      time.Now,
+		newID:    newID,
+	}, nil
+}
+
+// compile time check that controller implements Controller interface
+var _ Controller = (*controller)(nil)
+
+type controller struct {
+	logger   *zap.Logger
+	signCtrl sign.Controller
+	keystore keystore.Keystore
+	config   internalconfig.TokensConfig
+	now      func() time.Time
+	newID    func() (string, error)
+}
+
+// header is the JOSE header of the issued tokens. Key id is the id of the server-side key followed by the version,
+// the same way as in the JWKS, so that the tokens signed by the asymmetric keys can be validated by the consumers.
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Issue returns the JWT with the registered claims and the custom claims in the request signed by one of the configured
+// token keys. The key and the algorithm default to the configured ones, asymmetric keys default to their own algorithm.
+func (c *controller) Issue(ctx context.Context, req *entity.IssueTokenRequest) (*entity.IssueTokenResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	if req.ExpiresIn < 0 || req.ExpiresIn > c.config.MaxExpiresIn {
+		return nil, fmt.Errorf(
+			"expires_in must be between 0 and %d seconds: %w", c.config.MaxExpiresIn, entity.ErrInvalidArgument,
+		)
+	}
+	for _, claim := range _registeredClaims {
+		if _, ok := req.Claims[claim]; ok {
+			return nil, fmt.Errorf("claim %s can't be overridden: %w", claim, entity.ErrInvalidArgument)
+		}
+	}
+	if err := checkScope(req.Claims["scope"], req.CallerScopes); err != nil {
+		return nil, err
+	}
+	keyID := req.KeyID
+	if keyID == "" {
+		keyID = c.config.KeyID
+	}
+	if !c.isTokenKey(keyID) {
+		return nil, fmt.Errorf("key %s is not a token key: %w", keyID, entity.ErrInvalidArgument)
+	}
+	// the version is resolved before signing, cause it is a part of the signed header
+	key, err := c.keystore.Key(ctx, keyID, req.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	alg := req.Algorithm
+	if alg == "" {
+		alg = c.config.Algorithm
+		if key.Algorithm != "" {
+			alg = jwsAlgorithm(key.Algorithm)
+		}
+	}
+	algorithm, ok := _algorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported token algorithm %s: %w", alg, entity.ErrInvalidArgument)
+	}
+	expiresIn := req.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = c.config.DefaultExpiresIn
+	}
+	id, err := c.newID()
+	if err != nil {
+		return nil, err
+	}
+	issuedAt := c.now().Unix()
+	claims := make(map[string]any, len(req.Claims)+len(_registeredClaims))
+	for name, value := range req.Claims {
+		claims[name] = value
+	}
+	if c.config.Issuer != "" {
+		claims["iss"] = c.config.Issuer
+	}
+	if req.Subject != "" {
+		claims["sub"] = req.Subject
+	}
+	if len(req.Audience) == 1 {
+		claims["aud"] = req.Audience[0]
+	} else if len(req.Audience) > 1 {
+		claims["aud"] = req.Audience
+	}
+	claims["iat"] = issuedAt
+	claims["nbf"] = issuedAt
+	claims["exp"] = issuedAt + expiresIn
+	claims["jti"] = id
+	encodedHeader, err := encodeSegment(header{
+		Algorithm: alg,
+		Type:      _tokenType,
+		KeyID:     fmt.Sprintf("%s.%d", key.ID, key.Version),
+	})
+	if err != nil {
+		return nil, err
+	}
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return nil, fmt.Errorf("claims must be JSON values: %s: %w", err, entity.ErrInvalidArgument)
+	}
+	signingInput := encodedHeader + "." + encodedClaims
+	response, err := c.signCtrl.Sign(ctx, &entity.SignRequest{
+		Algorithm:  algorithm,
+		Text:       signingInput,
+		KeyID:      key.ID,
+		KeyVersion: key.Version,
+		Encoding:   signer.EncodingBase64URL,
+		Internal:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.IssueTokenResponse{
+		Token:      signingInput + "." + response.Signature,
+		ExpiresAt:  issuedAt + expiresIn,
+		KeyID:      response.KeyID,
+		KeyVersion: response.KeyVersion,
+	}, nil
+}
+
+// Verify checks the signature of the JWT with the token key referenced by the kid header, or all the versions
+// of the configured key if the header is missing, and validates the registered claims. Claims are returned only
+// if the signature is valid. Malformed tokens are rejected, while the rest of the failures are reported as the Reason.
+func (c *controller) Verify(ctx context.Context, req *entity.VerifyTokenRequest) (*entity.VerifyTokenResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	segments := strings.Split(req.Token, ".")
+	if len(segments) != 3 {
+		return nil, fmt.Errorf("token must consist of 3 segments: %w", entity.ErrInvalidArgument)
+	}
+	var h header
+	if err := decodeSegment(segments[0], &h); err != nil {
+		return nil, fmt.Errorf("token header: %s: %w", err, entity.ErrInvalidArgument)
+	}
+	var claims map[string]any
+	if err := decodeSegment(segments[1], &claims); err != nil {
+		return nil, fmt.Errorf("token claims: %s: %w", err, entity.ErrInvalidArgument)
+	}
+	if h.Type != "" && !strings.EqualFold(h.Type, _tokenType) {
+		return nil, fmt.Errorf("unsupported token type %s: %w", h.Type, entity.ErrInvalidArgument)
+	}
+	algorithm, ok := _algorithms[h.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported token algorithm %s: %w", h.Algorithm, entity.ErrInvalidArgument)
+	}
+	keyID, keyVersion := parseKeyID(h.KeyID)
+	if keyID == "" {
+		keyID = c.config.KeyID
+	}
+	response := &entity.VerifyTokenResponse{KeyID: keyID}
+	// the rest of the internal keys, e.g. the ones of the HMAC signing clients, can't vouch for the tokens
+	if !c.isTokenKey(keyID) {
+		response.Reason = "signing key is not a token key"
+		return response, nil
+	}
+	verified, err := c.signCtrl.Verify(ctx, &entity.VerifyRequest{
+		Algorithm:  algorithm,
+		Text:       segments[0] + "." + segments[1],
+		KeyID:      keyID,
+		KeyVersion: keyVersion,
+		Signature:  segments[2],
+		Encoding:   signer.EncodingBase64URL,
+		Internal:   true,
+	})
+	if stderrors.Is(err, entity.ErrNotFound) {
+		response.Reason = "signing key is unknown"
+		return response, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !verified.Valid {
+		response.Reason = "signature doesn't match"
+		return response, nil
+	}
+	response.KeyVersion = verified.KeyVersion
+	response.Claims = claims
+	response.Reason = c.validateClaims(claims, req.Audience)
+	response.Valid = response.Reason == ""
+	return response, nil
+}
+
+// validateClaims checks the registered claims of the token and returns the reason if the token is not valid.
+// Time based claims are checked with the configured leeway.
+func (c *controller) validateClaims(claims map[string]any, audience string) string {
+	now := c.now().Unix()
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return "token has no expiration time"
+	}
+	if now > exp+c.config.Leeway {
+		return "token has expired"
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now+c.config.Leeway < nbf {
+		return "token is not valid yet"
+	}
+	if iss, _ := claims["iss"].(string); c.config.Issuer != "" && iss != c.config.Issuer {
+		return "token is issued by another issuer"
+	}
+	aud, ok := claims["aud"]
+	if !ok {
+		return ""
+	}
+	if audience == "" {
+		return "audience is required to verify the token"
+	}
+	switch aud := aud.(type) {
+	case string:
+		if aud == audience {
+			return ""
+		}
+	case []any:
+		for _, a := range aud {
+			if a == audience {
+				return ""
+			}
+		}
+	}
+	return "token is issued for another audience"
+}
+
+// isTokenKey reports whether the tokens may be signed with the key, only the configured keys are trusted
+// as the rest of the internal keys are held by the clients too
+func (c *controller) isTokenKey(keyID string) bool {
+	return keyID == c.config.KeyID || contains(c.config.KeyIDs, keyID)
+}
+
+// checkScope checks that the space separated `scope` claim only grants the scopes of the caller.
+// Scopes are not limited if the caller scopes are nil.
+func checkScope(claim any, callerScopes []string) error {
+	if claim == nil {
+		return nil
+	}
+	scope, ok := claim.(string)
+	if !ok {
+		return fmt.Errorf("claim scope must be a space separated string: %w", entity.ErrInvalidArgument)
+	}
+	if callerScopes == nil {
+		return nil
+	}
+	for _, s := range strings.Fields(scope) {
+		if !contains(callerScopes, s) {
+			return fmt.Errorf("claim scope can't grant %s scope the caller doesn't have: %w", s, entity.ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+// jwsAlgorithm returns the JWS name of the gateway algorithm
+func jwsAlgorithm(algorithm string) string {
+	for jws, name := range _algorithms {
+		if name == algorithm {
+			return jws
+		}
+	}
+	return algorithm
+}
+
+// parseKeyID splits the kid header into the id and the version of the server-side key, e.g. `tokens.2`.
+// The version is 0 if kid doesn't end with it, so that the token is checked against all the versions.
+func parseKeyID(kid string) (string, int) {
+	if i := strings.LastIndexByte(kid, '.'); i >= 0 {
+		if version, err := strconv.Atoi(kid[i+1:]); err == nil && version > 0 {
+			return kid[:i], version
+		}
+	}
+	return kid, 0
+}
+
+// numericDate returns the JWT NumericDate claim as unix timestamp, fractional seconds are truncated
+func numericDate(value any) (int64, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// encodeSegment returns the base64url encoded JSON of the value without padding
+func encodeSegment(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeSegment decodes the base64url encoded JSON segment into the value, numbers are kept as json.Number
+// to avoid the precision loss of the large integer claims
+func decodeSegment(segment string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+// newID returns the random token id
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"fmt"
 	"github.com/pkg/errors"
 	"go.uber.org/fx"
 	"redis-postgres-service/entity"
@@ -10,6 +11,11 @@ import (
 
 type Controller interface {
 	Add(ctx context.Context, req *entity.AddUserRequest) (*entity.AddUserResponse, error)
+	BulkAdd(ctx context.Context, req *entity.BulkAddUsersRequest) (*entity.BulkAddUsersResponse, error)
+	Get(ctx context.Context, req *entity.GetUserRequest) (*entity.User, error)
+	List(ctx context.Context, req *entity.ListUsersRequest) (*entity.ListUsersResponse, error)
+	Update(ctx context.Context, req *entity.UpdateUserRequest) (*entity.User, error)
+	Delete(ctx context.Context, req *entity.DeleteUserRequest) (*entity.User, error)
 }
 
 // compile time check that controller implements Controller interface
@@ -40,3 +46,54 @@ func (c *controller) Add(ctx context.Context, req *entity.AddUserRequest) (*enti
 	}
 	return c.repository.AddUser(ctx, req)
 }
+
+// BulkAdd adds many users to the `users` table at once and returns their ids in the order of the request
+func (c *controller) BulkAdd(ctx context.Context, req *entity.BulkAddUsersRequest) (*entity.BulkAddUsersResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	if len(req.Users) == 0 {
+		return nil, fmt.Errorf("users must not be empty: %w", entity.ErrInvalidArgument)
+	}
+	return c.repository.AddUsers(ctx, req)
+}
+
+// Get returns the user stored in the `users` table under the requested id
+func (c *controller) Get(ctx context.Context, req *entity.GetUserRequest) (*entity.User, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	return c.repository.GetUser(ctx, req)
+}
+
+// List returns the page of users from the `users` table ordered by id
+func (c *controller) List(ctx context.Context, req *entity.ListUsersRequest) (*entity.ListUsersResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	users, err := c.repository.ListUsers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ListUsersResponse{
+		Users:  users,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}, nil
+}
+
+// Update updates the user in the `users` table and returns the resulting row
+func (c *controller) Update(ctx context.Context, req *entity.UpdateUserRequest) (*entity.User, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	return c.repository.UpdateUser(ctx, req)
+}
+
+// Delete removes the user from the `users` table and returns the removed row
+func (c *controller) Delete(ctx context.Context, req *entity.DeleteUserRequest) (*entity.User, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	return c.repository.DeleteUser(ctx, req)
+}
@@ -24,6 +24,7 @@ func TestNew(t *testing.T) {
 func Test_controller_Add(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
+	age := 22
 	type mockRepository struct {
 		res *entity.AddUserResponse
 		err error
@@ -43,7 +44,7 @@ func Test_controller_Add(t *testing.T) {
 			args: args{
 				req: &entity.AddUserRequest{
 					Name: "Alex",
-					Age:  22,
+					Age:  &age,
 				},
 			},
 			mockRepository: &mockRepository{
@@ -62,7 +63,7 @@ func Test_controller_Add(t *testing.T) {
 			args: args{
 				req: &entity.AddUserRequest{
 					Name: "Alex",
-					Age:  22,
+					Age:  &age,
 				},
 			},
 			mockRepository: &mockRepository{
@@ -102,3 +103,315 @@ func Test_controller_Add(t *testing.T) {
 		})
 	}
 }
+
+func Test_controller_BulkAdd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	age := 22
+	type mockRepository struct {
+		res *entity.BulkAddUsersResponse
+		err error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.BulkAddUsersRequest
+		mockRepository *mockRepository
+		want           *entity.BulkAddUsersResponse
+		wantErr        error
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req: &entity.BulkAddUsersRequest{
+				Users:  []entity.AddUserRequest{{Name: "Alex", Age: &age}, {Name: "Bob"}},
+				Atomic: true,
+			},
+			mockRepository: &mockRepository{
+				res: &entity.BulkAddUsersResponse{Ids: []int64{12, 13}},
+			},
+			want:      &entity.BulkAddUsersResponse{Ids: []int64{12, 13}},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Repo fails",
+			req: &entity.BulkAddUsersRequest{
+				Users: []entity.AddUserRequest{{Name: "Alex", Age: &age}},
+			},
+			mockRepository: &mockRepository{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "no users",
+			req:       &entity.BulkAddUsersRequest{},
+			want:      nil,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repositoryMock := mock_postgres.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repositoryMock.EXPECT().
+					AddUsers(ctx, tt.req).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repositoryMock,
+			}
+			got, err := c.BulkAdd(ctx, tt.req)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockRepository struct {
+		res *entity.User
+		err error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.GetUserRequest
+		mockRepository *mockRepository
+		want           *entity.User
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req:  &entity.GetUserRequest{Id: 12},
+			mockRepository: &mockRepository{
+				res: &entity.User{Id: 12, Name: "Alex", Age: 22},
+			},
+			want:      &entity.User{Id: 12, Name: "Alex", Age: 22},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Repo fails",
+			req:  &entity.GetUserRequest{Id: 12},
+			mockRepository: &mockRepository{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repositoryMock := mock_postgres.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repositoryMock.EXPECT().
+					GetUser(ctx, tt.req).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repositoryMock,
+			}
+			got, err := c.Get(ctx, tt.req)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockRepository struct {
+		res []entity.User
+		err error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.ListUsersRequest
+		mockRepository *mockRepository
+		want           *entity.ListUsersResponse
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req:  &entity.ListUsersRequest{Limit: 1, Offset: 3},
+			mockRepository: &mockRepository{
+				res: []entity.User{{Id: 12, Name: "Alex", Age: 22}},
+			},
+			want: &entity.ListUsersResponse{
+				Users:  []entity.User{{Id: 12, Name: "Alex", Age: 22}},
+				Limit:  1,
+				Offset: 3,
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Repo fails",
+			req:  &entity.ListUsersRequest{Limit: 1},
+			mockRepository: &mockRepository{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repositoryMock := mock_postgres.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repositoryMock.EXPECT().
+					ListUsers(ctx, tt.req).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repositoryMock,
+			}
+			got, err := c.List(ctx, tt.req)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	age := 23
+	type mockRepository struct {
+		res *entity.User
+		err error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.UpdateUserRequest
+		mockRepository *mockRepository
+		want           *entity.User
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req:  &entity.UpdateUserRequest{Id: 12, Age: &age},
+			mockRepository: &mockRepository{
+				res: &entity.User{Id: 12, Name: "Alex", Age: 23},
+			},
+			want:      &entity.User{Id: 12, Name: "Alex", Age: 23},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Repo fails",
+			req:  &entity.UpdateUserRequest{Id: 12, Age: &age},
+			mockRepository: &mockRepository{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repositoryMock := mock_postgres.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repositoryMock.EXPECT().
+					UpdateUser(ctx, tt.req).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repositoryMock,
+			}
+			got, err := c.Update(ctx, tt.req)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_controller_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockRepository struct {
+		res *entity.User
+		err error
+	}
+	tests := []struct {
+		name           string
+		req            *entity.DeleteUserRequest
+		mockRepository *mockRepository
+		want           *entity.User
+		assertion      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			req:  &entity.DeleteUserRequest{Id: 12},
+			mockRepository: &mockRepository{
+				res: &entity.User{Id: 12, Name: "Alex", Age: 22},
+			},
+			want:      &entity.User{Id: 12, Name: "Alex", Age: 22},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Repo fails",
+			req:  &entity.DeleteUserRequest{Id: 12},
+			mockRepository: &mockRepository{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "nil request",
+			req:       nil,
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repositoryMock := mock_postgres.NewMockRepository(ctrl)
+			if tt.mockRepository != nil {
+				repositoryMock.EXPECT().
+					DeleteUser(ctx, tt.req).
+					Return(tt.mockRepository.res, tt.mockRepository.err)
+			}
+			c := &controller{
+				repository: repositoryMock,
+			}
+			got, err := c.Delete(ctx, tt.req)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package entity
 
+import "errors"
+
 const (
 	// MethodNotAllowed is a format string for the errors related to the respective http status
 	MethodNotAllowed = "method %s not allowed"
@@ -9,6 +11,20 @@ const (
 	RequestBodyIsTooBig = "request body is too big"
 	// BadRequest is a format string for the errors request is malformed
 	BadRequest = "bad request, err: %s"
+	// Unauthorized is a format string for the errors when the caller can't be authenticated
+	Unauthorized = "unauthorized, err: %s"
+	// Forbidden is a format string for the errors when the caller is not allowed to call the endpoint
+	Forbidden = "forbidden, err: %s"
+	// Conflict is a format string for the errors when the request conflicts with the request in flight
+	Conflict = "conflict, err: %s"
+	// NotFound is a format string for the errors when the requested entity does not exist
+	NotFound = "not found, err: %s"
+	// UnprocessableRequest is a format string for the errors when the valid request can't be applied to the stored data
+	UnprocessableRequest = "unprocessable request, err: %s"
+	// TooManyRequests is a format string for the errors when the caller exceeded the rate limit of the endpoint
+	TooManyRequests = "too many requests, err: %s"
+	// ServiceUnavailable is a format string for the errors when the downstream service can't be reached
+	ServiceUnavailable = "service unavailable, err: %s"
 	// FailedToProcessTheRequest is a format string for the errors when the request processing failed
 	FailedToProcessTheRequest = "failed to process the request, err: %s"
 	// FailedToProcessTheResponse is a format string for the errors when the response processing failed
@@ -16,3 +32,48 @@ const (
 	// FailedToWriteTheResponse is a format string for the errors when we failed to write back the http response
 	FailedToWriteTheResponse = "failed to write the response, err: %s"
 )
+
+// Generic messages returned to the clients instead of the messages of the internal and the downstream errors,
+// the original errors are expected to be logged
+const (
+	InternalErrorMessage = "internal error, see the logs for the request id"
+	UnavailableMessage   = "downstream service is unavailable, retry later"
+)
+
+var (
+	// ErrNotFound is returned by the repositories when the requested entity does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidArgument is returned by the controllers when the request is well-formed but can't be processed as is
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrConflict is returned by the controllers when the request conflicts with another request in flight
+	ErrConflict = errors.New("conflict")
+	// ErrOverflow is returned by the repositories when the result of the operation doesn't fit the stored type
+	ErrOverflow = errors.New("overflow")
+	// ErrUnavailable is returned by the repositories when the downstream service can't be reached
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// PublicError is the error whose Message is returned to the clients, while the wrapped error, e.g. the raw
+// database error, is only logged
+type PublicError struct {
+	Message string
+	Err     error
+}
+
+func (e *PublicError) Error() string {
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *PublicError) Unwrap() error {
+	return e.Err
+}
+
+// PublicMessage returns the message of the error that can be returned to the clients, it is the Message of the
+// PublicError in the chain or the error message itself otherwise
+func PublicMessage(err error) string {
+	var public *PublicError
+	if errors.As(err, &public) {
+		return public.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,70 @@
+package entity
+
+// HTTPMessage is an HTTP request to sign or verify with RFC 9421 HTTP Message Signatures.
+// Header names are case-insensitive, Body is only used to compute and check the Content-Digest.
+type HTTPMessage struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// HTTPSignRequest is a container for the request to sign the HTTPMessage. Algorithm is the name from the RFC 9421
+// registry, e.g. `hmac-sha256`, Components are the covered component identifiers, e.g. `@method` or `content-type`.
+// Keys are referenced the same way as in SignRequest. ExpiresIn is the lifetime of the signature in seconds.
+type HTTPSignRequest struct {
+	HTTPMessage
+	Algorithm  string   `json:"algorithm"`
+	Components []string `json:"components"`
+	Label      string   `json:"label,omitempty"`
+	Key        string   `json:"key,omitempty"`
+	KeyID      string   `json:"key_id,omitempty"`
+	KeyVersion int      `json:"key_version,omitempty"`
+	ExpiresIn  int64    `json:"expires_in,omitempty"`
+	Nonce      string   `json:"nonce,omitempty"`
+	Tag        string   `json:"tag,omitempty"`
+}
+
+// HTTPSignResponse contains the headers to add to the signed message: Signature-Input, Signature and
+// Content-Digest if it was covered but missing in the message, and the version of the server-side key used
+type HTTPSignResponse struct {
+	Headers    map[string]string `json:"headers"`
+	KeyID      string            `json:"key_id,omitempty"`
+	KeyVersion int               `json:"key_version,omitempty"`
+}
+
+// HTTPVerifyRequest is a container for the request to check the signature of the HTTPMessage with the Label,
+// the first signature of the message by default. Algorithm and KeyID are read from the signature parameters
+// unless provided in the request. MaxAge limits the age of the signature in seconds if it is set.
+type HTTPVerifyRequest struct {
+	HTTPMessage
+	Label      string `json:"label,omitempty"`
+	Algorithm  string `json:"algorithm,omitempty"`
+	Key        string `json:"key,omitempty"`
+	KeyID      string `json:"key_id,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+	MaxAge     int64  `json:"max_age,omitempty"`
+}
+
+// Validate checks that the key is not referenced both by value and by id, the key may be omitted
+// if the signature has the keyid parameter
+func (r *HTTPVerifyRequest) Validate() error {
+	var v violations
+	v.keyRef(r.Key, r.KeyID, r.KeyVersion, false)
+	if r.MaxAge < 0 {
+		v.add("max_age", "must not be negative")
+	}
+	return v.err()
+}
+
+// HTTPVerifyResponse contains the result of the signature check with the covered components
+// and the Reason if the signature is not valid
+type HTTPVerifyResponse struct {
+	Valid      bool     `json:"valid"`
+	Label      string   `json:"label"`
+	Algorithm  string   `json:"algorithm"`
+	Components []string `json:"components"`
+	KeyID      string   `json:"key_id,omitempty"`
+	KeyVersion int      `json:"key_version,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+// IdempotencyRequest is an internal container for the request made with the Idempotency-Key of the Client.
+// Fingerprint identifies the request the key is used for, the key can't be reused for another request.
+type IdempotencyRequest struct {
+	Client      string
+	Key         string
+	Fingerprint string
+}
+
+// IdempotencyResponse is an internal container for the outcome of the key acquisition. Replay is the stored
+// response of the completed request with the same key, it is nil if the request acquired the key and has to be processed.
+type IdempotencyResponse struct {
+	Replay *StoredResponse
+}
+
+// StoredResponse is the response of the idempotent request replayed on the retries
+type StoredResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   []byte              `json:"body,omitempty"`
+}
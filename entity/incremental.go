@@ -1,12 +1,95 @@
 package entity
 
-// IncrementRequest is an internal container for the request to increment Key for Value in redis
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// IncrementModeInt increments the counter by an int64 value, it is the default mode
+	IncrementModeInt = "int"
+	// IncrementModeFloat increments the counter by a float64 value
+	IncrementModeFloat = "float"
+	// IncrementModeDecimal increments the counter by an arbitrary-precision decimal value
+	IncrementModeDecimal = "decimal"
+)
+
+// IncrementRequest is an internal container for the request to increment Key for Value in redis.
+// Value is a JSON number or a string containing a number, it is interpreted according to Mode.
+// Optional TTLSeconds sets the expiration of the counter if it doesn't have one yet,
+// optional Min and Max clamp the resulting value (int mode only).
 type IncrementRequest struct {
-	Key   string `json:"key,omitempty"`
-	Value int64  `json:"value,omitempty"`
+	Key        string      `json:"key,omitempty"`
+	Value      json.Number `json:"value,omitempty"`
+	Mode       string      `json:"mode,omitempty"`
+	TTLSeconds int64       `json:"ttl_seconds,omitempty"`
+	Min        *int64      `json:"min,omitempty"`
+	Max        *int64      `json:"max,omitempty"`
+}
+
+// Validate checks the key, the mode and the options of the increment. The value is checked by the controller,
+// cause it is interpreted according to the mode.
+func (r *IncrementRequest) Validate() error {
+	var v violations
+	v.key("key", r.Key)
+	switch r.Mode {
+	case "", IncrementModeInt, IncrementModeFloat, IncrementModeDecimal:
+	default:
+		v.add("mode", "must be one of %s, %s, %s", IncrementModeInt, IncrementModeFloat, IncrementModeDecimal)
+	}
+	if r.TTLSeconds < 0 {
+		v.add("ttl_seconds", "must not be negative")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		v.add("min", "must not exceed max")
+	}
+	return v.err()
 }
 
-// IncrementResponse is and internal container for the incrementing results
+// IncrementResponse is and internal container for the incrementing results.
+// TTLSeconds is omitted for the counters that never expire.
 type IncrementResponse struct {
-	Value int64 `json:"value,omitempty"`
+	Value      json.Number `json:"value"`
+	TTLSeconds int64       `json:"ttl_seconds,omitempty"`
+}
+
+// CounterRequest is an internal container for the request to read or reset the counter stored under Key in redis
+type CounterRequest struct {
+	Key string `json:"key"`
+}
+
+// IncrementItem is a single int64 increment of Key for Value within the batch
+type IncrementItem struct {
+	Key   string `json:"key"`
+	Value int64  `json:"value"`
+}
+
+// BatchIncrementRequest is an internal container for the request to increment several keys in redis at once.
+// If Atomic is set the increments are executed in MULTI/EXEC transaction, so that no other client observes
+// partially applied batch. Note that redis doesn't roll back the transaction if some of the increments fail.
+// Atomic is set by the `atomic` query param the same way as of BulkAddUsersRequest.
+type BatchIncrementRequest struct {
+	Atomic bool            `json:"-"`
+	Items  []IncrementItem `json:"items"`
+}
+
+// Validate checks the keys of all the items, fields are reported with their index, e.g. `items[2].key`
+func (r *BatchIncrementRequest) Validate() error {
+	var v violations
+	for i := range r.Items {
+		v.key(fmt.Sprintf("items[%d].key", i), r.Items[i].Key)
+	}
+	return v.err()
+}
+
+// BatchIncrementResult is the outcome of a single increment of the batch, either Value or Error is set
+type BatchIncrementResult struct {
+	Key   string      `json:"key"`
+	Value json.Number `json:"value,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// BatchIncrementResponse is an internal container for the batch incrementing results in the order of the request items
+type BatchIncrementResponse struct {
+	Results []BatchIncrementResult `json:"results"`
 }
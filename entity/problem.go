@@ -0,0 +1,20 @@
+package entity
+
+// Problem is an internal container for the RFC 7807 problem details of the failed request. Code is the stable
+// error code clients can rely on, Message describes the occurrence of the problem, Details list the particular
+// problems of the request, e.g. the invalid fields. RequestID correlates the problem with the service logs.
+type Problem struct {
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Status    int             `json:"status"`
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Details   []ProblemDetail `json:"details,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+}
+
+// ProblemDetail is a particular problem of the request, Field is empty if the problem is not related to the field
+type ProblemDetail struct {
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// RateLimitRequest is an internal container for the request of the Client to the rate limited Route.
+// Peek checks whether the request is within the limit without counting it.
+type RateLimitRequest struct {
+	Route  string
+	Client string
+	Peek   bool
+}
+
+// RateLimitResponse is an internal container for the rate limiting decision. Limit is 0 if the route is not limited,
+// Reset is the time left until the oldest request of the client leaves the window, i.e. the next request is allowed.
+type RateLimitResponse struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	Reset     time.Duration
+}
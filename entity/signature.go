@@ -1,12 +1,116 @@
 package entity
 
-// SignRequest is an internal container for the request to sign the text with key (SHA512)
+import "io"
+
+// SignRequest is an internal container for the request to sign the text using the Algorithm provided in the path.
+// Either the server-side key referenced by KeyID or the raw Key must be provided. KeyVersion pins the version
+// of the server-side key, the current version is used by default. Encoding of the signature is hex by default.
+// Internal is set by the service itself to sign with the internal keys, only the internal keys are used then.
 type SignRequest struct {
-	Text string `json:"text,omitempty"`
-	Key  string `json:"key,omitempty"`
+	Algorithm  string `json:"-"`
+	Text       string `json:"text,omitempty"`
+	Key        string `json:"key,omitempty"`
+	KeyID      string `json:"key_id,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+	Encoding   string `json:"encoding,omitempty"`
+	Internal   bool   `json:"-"`
+}
+
+// Validate checks that the algorithm is provided and the key is referenced either by value or by id
+func (r *SignRequest) Validate() error {
+	var v violations
+	if r.Algorithm == "" {
+		v.add("algorithm", "must not be empty")
+	}
+	v.keyRef(r.Key, r.KeyID, r.KeyVersion, true)
+	return v.err()
+}
+
+// SignStreamRequest is an internal container for the request to sign the raw Body using the Algorithm provided
+// in the path. Keys are referenced the same way as in SignRequest, but are provided in the headers.
+type SignStreamRequest struct {
+	Algorithm  string
+	Key        string
+	KeyID      string
+	KeyVersion int
+	Encoding   string
+	Body       io.Reader
 }
 
-// SignResponse contains result signature in Hex format
+// Validate checks that the algorithm is provided and the key is referenced either by value or by id
+func (r *SignStreamRequest) Validate() error {
+	var v violations
+	if r.Algorithm == "" {
+		v.add("algorithm", "must not be empty")
+	}
+	v.keyRef(r.Key, r.KeyID, r.KeyVersion, true)
+	return v.err()
+}
+
+// SignResponse contains result Signature in the Encoding, the name of the algorithm and the version
+// of the server-side key used. Hex duplicates the hex encoded Signature for the backward compatibility.
 type SignResponse struct {
-	Hex string `json:"hex"`
+	Algorithm  string `json:"algorithm"`
+	Encoding   string `json:"encoding"`
+	Signature  string `json:"signature"`
+	Hex        string `json:"hex,omitempty"`
+	KeyID      string `json:"key_id,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+}
+
+// VerifyRequest is an internal container for the request to check the Signature of the text using
+// the Algorithm provided in the path. Keys are referenced the same way as in SignRequest, if KeyVersion is not
+// provided the signature is checked against all the versions of the server-side key.
+// Encoding of the signature is hex by default. Internal is set the same way as in SignRequest.
+type VerifyRequest struct {
+	Algorithm  string `json:"-"`
+	Text       string `json:"text,omitempty"`
+	Key        string `json:"key,omitempty"`
+	KeyID      string `json:"key_id,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+	Signature  string `json:"signature,omitempty"`
+	Encoding   string `json:"encoding,omitempty"`
+	Internal   bool   `json:"-"`
+}
+
+// Validate checks that the algorithm and the signature are provided and the key is referenced
+// either by value or by id, so that the signature is never checked against an empty key
+func (r *VerifyRequest) Validate() error {
+	var v violations
+	if r.Algorithm == "" {
+		v.add("algorithm", "must not be empty")
+	}
+	if r.Signature == "" {
+		v.add("signature", "must not be empty")
+	}
+	v.keyRef(r.Key, r.KeyID, r.KeyVersion, true)
+	return v.err()
+}
+
+// VerifyResponse contains the result of the signature check, the name of the algorithm used
+// and the version of the server-side key the signature matched
+type VerifyResponse struct {
+	Algorithm  string `json:"algorithm"`
+	Valid      bool   `json:"valid"`
+	KeyID      string `json:"key_id,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+}
+
+// JWK is a public key in the JSON Web Key format, see RFC 7517. Only the members of
+// the key types used by the service are present: OKP (Ed25519), EC (P-256) and RSA.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a set of the public keys of the server-side signing keys
+type JWKS struct {
+	Keys []JWK `json:"keys"`
 }
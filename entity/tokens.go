@@ -0,0 +1,43 @@
+package entity
+
+// IssueTokenRequest is a container for the request to issue a JWT signed by the server-side key referenced
+// by KeyID. Algorithm is the JWS algorithm name, e.g. `HS512` or `EdDSA`. Claims are added to the registered
+// claims, ExpiresIn is the lifetime of the token in seconds. Configured defaults are used for the empty fields.
+// CallerScopes are the scopes of the authenticated caller, the `scope` claim is limited to them, so that the tokens
+// can't grant more than the caller has. They are nil if the authentication is disabled and the scopes are not limited.
+type IssueTokenRequest struct {
+	Algorithm    string         `json:"algorithm,omitempty"`
+	KeyID        string         `json:"key_id,omitempty"`
+	KeyVersion   int            `json:"key_version,omitempty"`
+	Subject      string         `json:"subject,omitempty"`
+	Audience     []string       `json:"audience,omitempty"`
+	ExpiresIn    int64          `json:"expires_in,omitempty"`
+	Claims       map[string]any `json:"claims,omitempty"`
+	CallerScopes []string       `json:"-"`
+}
+
+// IssueTokenResponse contains the compact serialized JWT, its expiration time as unix timestamp
+// and the version of the server-side key used
+type IssueTokenResponse struct {
+	Token      string `json:"token"`
+	ExpiresAt  int64  `json:"expires_at"`
+	KeyID      string `json:"key_id"`
+	KeyVersion int    `json:"key_version"`
+}
+
+// VerifyTokenRequest is a container for the request to validate the JWT. Audience is the identifier
+// of the token consumer, it must be provided if the token is issued for the audience.
+type VerifyTokenRequest struct {
+	Token    string `json:"token"`
+	Audience string `json:"audience,omitempty"`
+}
+
+// VerifyTokenResponse contains the result of the token validation, the Claims of the token with the valid signature
+// and the Reason if the token is not valid
+type VerifyTokenResponse struct {
+	Valid      bool           `json:"valid"`
+	Claims     map[string]any `json:"claims,omitempty"`
+	KeyID      string         `json:"key_id,omitempty"`
+	KeyVersion int            `json:"key_version,omitempty"`
+	Reason     string         `json:"reason,omitempty"`
+}
@@ -1,12 +1,115 @@
 package entity
 
+import "fmt"
+
 // AddUserRequest is an internal container for the request to add a new row with the user data to the postgres repo
 type AddUserRequest struct {
 	Name string `json:"name,omitempty"`
-	Age  int    `json:"age,omitempty"`
+	Age  *int   `json:"age,omitempty"`
+}
+
+// Validate checks that the name and the age are provided and the age is in the range
+func (r *AddUserRequest) Validate() error {
+	var v violations
+	v.name("name", r.Name)
+	if r.Age == nil {
+		v.add("age", "must be provided")
+	} else {
+		v.age("age", *r.Age)
+	}
+	return v.err()
 }
 
 // AddUserResponse is an internal container for the response that contains the id of the row where data was inserted
 type AddUserResponse struct {
 	Id int64 `json:"id"`
 }
+
+// User is an internal container for the row stored in the `users` table
+type User struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// GetUserRequest is an internal container for the request to read a single row from the `users` table
+type GetUserRequest struct {
+	Id int64 `json:"id"`
+}
+
+// ListUsersRequest is an internal container for the request to read a page of rows from the `users` table
+type ListUsersRequest struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// ListUsersResponse is an internal container for the page of rows read from the `users` table
+type ListUsersResponse struct {
+	Users  []User `json:"users"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// UpdateUserRequest is an internal container for the request to update a row in the `users` table.
+// Nil fields are left untouched, unless Replace is set by the PUT requests that must provide all the fields.
+type UpdateUserRequest struct {
+	Id      int64   `json:"-"`
+	Name    *string `json:"name,omitempty"`
+	Age     *int    `json:"age,omitempty"`
+	Replace bool    `json:"-"`
+}
+
+// Validate checks the provided fields the same way as AddUserRequest.Validate does, all the fields
+// must be provided if the request replaces the user
+func (r *UpdateUserRequest) Validate() error {
+	var v violations
+	switch {
+	case r.Name != nil:
+		v.name("name", *r.Name)
+	case r.Replace:
+		v.add("name", "must be provided")
+	}
+	switch {
+	case r.Age != nil:
+		v.age("age", *r.Age)
+	case r.Replace:
+		v.add("age", "must be provided")
+	}
+	return v.err()
+}
+
+// DeleteUserRequest is an internal container for the request to delete a row from the `users` table
+type DeleteUserRequest struct {
+	Id int64 `json:"id"`
+}
+
+// BulkAddUsersRequest is an internal container for the request to append many rows to the `users` table.
+// If Atomic is set either all the rows are added or none, otherwise every batch of rows is added independently.
+// Atomic is set by the `atomic` query param, as the body is the array of the users.
+type BulkAddUsersRequest struct {
+	Users  []AddUserRequest `json:"users"`
+	Atomic bool             `json:"-"`
+}
+
+// Validate checks every user of the request, fields are reported with their index, e.g. `users[2].name`
+func (r *BulkAddUsersRequest) Validate() error {
+	var v violations
+	for i := range r.Users {
+		v.merge(fmt.Sprintf("users[%d].", i), r.Users[i].Validate())
+	}
+	return v.err()
+}
+
+// BulkAddUsersError describes the batch of Count rows starting at Offset of the request that failed to be added
+type BulkAddUsersError struct {
+	Offset int    `json:"offset"`
+	Count  int    `json:"count"`
+	Error  string `json:"error"`
+}
+
+// BulkAddUsersResponse is an internal container for the ids of the added rows in the order of the request.
+// Rows of the failed batches have id 0 and are described in Errors.
+type BulkAddUsersResponse struct {
+	Ids    []int64             `json:"ids"`
+	Errors []BulkAddUsersError `json:"errors,omitempty"`
+}
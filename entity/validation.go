@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxUserNameLength is the maximum number of characters in the name of the user
+	MaxUserNameLength = 255
+	// MaxUserAge is the maximum age of the user
+	MaxUserAge = 150
+	// MaxKeyLength is the maximum length of the redis keys in bytes
+	MaxKeyLength = 512
+)
+
+// _keyRegexp matches the redis keys accepted by the service: printable characters without whitespaces
+var _keyRegexp = regexp.MustCompile(`^[[:graph:]]+$`)
+
+// ValidationError lists the invalid fields of the request, it wraps ErrInvalidArgument
+type ValidationError struct {
+	Details []ProblemDetail
+}
+
+// Error returns the reasons of all the invalid fields
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Details))
+	for i, detail := range e.Details {
+		reasons[i] = fmt.Sprintf("%s %s", detail.Field, detail.Reason)
+	}
+	return "invalid request: " + strings.Join(reasons, ", ")
+}
+
+// Unwrap makes ValidationError match ErrInvalidArgument
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidArgument
+}
+
+// violations collects the invalid fields of the request
+type violations []ProblemDetail
+
+func (v *violations) add(field, format string, args ...any) {
+	*v = append(*v, ProblemDetail{Field: field, Reason: fmt.Sprintf(format, args...)})
+}
+
+// merge adds the invalid fields of the nested request with the field prefix, e.g. `items[2].`
+func (v *violations) merge(prefix string, err error) {
+	if verr, ok := err.(*ValidationError); ok {
+		for _, detail := range verr.Details {
+			v.add(prefix+detail.Field, detail.Reason)
+		}
+	}
+}
+
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return &ValidationError{Details: v}
+}
+
+func (v *violations) name(field, name string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		v.add(field, "must not be empty")
+	case utf8.RuneCountInString(name) > MaxUserNameLength:
+		v.add(field, "must not exceed %d characters", MaxUserNameLength)
+	}
+}
+
+func (v *violations) age(field string, age int) {
+	if age < 0 || age > MaxUserAge {
+		v.add(field, "must be between 0 and %d", MaxUserAge)
+	}
+}
+
+func (v *violations) key(field, key string) {
+	switch {
+	case key == "":
+		v.add(field, "must not be empty")
+	case len(key) > MaxKeyLength:
+		v.add(field, "must not exceed %d bytes", MaxKeyLength)
+	case !_keyRegexp.MatchString(key):
+		v.add(field, "must consist of printable characters without whitespaces")
+	}
+}
+
+// keyRef checks that the key is referenced either by value or by id and the version is only pinned
+// for the referenced server-side key. The key may be omitted if it is not required, e.g. if it is
+// referenced by the signature itself.
+func (v *violations) keyRef(key, keyID string, version int, required bool) {
+	switch {
+	case key == "" && keyID == "" && required:
+		v.add("key", "must be provided if key_id is not")
+	case key != "" && keyID != "":
+		v.add("key", "must not be provided with key_id")
+	}
+	if version < 0 {
+		v.add("key_version", "must not be negative")
+	} else if version > 0 && keyID == "" {
+		v.add("key_version", "requires key_id")
+	}
+}
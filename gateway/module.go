@@ -2,9 +2,9 @@ package gateway
 
 import (
 	"go.uber.org/fx"
-	"redis-postgres-service/gateway/sha512"
+	"redis-postgres-service/gateway/signer"
 )
 
 var Module = fx.Options(
-	fx.Provide(sha512.New),
+	fx.Provide(signer.New),
 )
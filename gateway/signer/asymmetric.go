@@ -0,0 +1,265 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"redis-postgres-service/entity"
+)
+
+// _signatureUse is the JWK `use` member of the signing keys
+const _signatureUse = "sig"
+
+// ed25519Signer signs with Ed25519 keys, the signature is deterministic
+type ed25519Signer struct{}
+
+func (ed25519Signer) Sign(key, message []byte) ([]byte, error) {
+	private, err := privateKey[ed25519.PrivateKey](key, Ed25519)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(private, message), nil
+}
+
+func (ed25519Signer) Verify(key, message, signature []byte) (bool, error) {
+	public, err := publicKey[ed25519.PublicKey](key, Ed25519)
+	if err != nil {
+		return false, err
+	}
+	return ed25519.Verify(public, message, signature), nil
+}
+
+func (ed25519Signer) PublicKey(key []byte) (*entity.JWK, error) {
+	public, err := publicKey[ed25519.PublicKey](key, Ed25519)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.JWK{
+		KeyType:   "OKP",
+		Algorithm: "EdDSA",
+		Use:       _signatureUse,
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(public),
+	}, nil
+}
+
+// ecdsaSigner signs the hash of the message with ECDSA keys on the curve. The signature is
+// the concatenation of the fixed size r and s, as in JWS, rather than ASN.1 DER.
+type ecdsaSigner struct {
+	curve elliptic.Curve
+	hash  crypto.Hash
+	jwa   string
+}
+
+func (s ecdsaSigner) Sign(key, message []byte) ([]byte, error) {
+	return streamSign(s, key, message)
+}
+
+func (s ecdsaSigner) NewStream(key []byte) (Stream, error) {
+	private, err := privateKey[*ecdsa.PrivateKey](key, s.jwa)
+	if err != nil {
+		return nil, err
+	}
+	if private.Curve != s.curve {
+		return nil, s.curveError()
+	}
+	return &digestStream{
+		hash: s.hash.New(),
+		sign: func(digest []byte) ([]byte, error) {
+			r, v, err := ecdsa.Sign(rand.Reader, private, digest)
+			if err != nil {
+				return nil, err // unreachable in tests, cause the key is valid and the entropy is available
+			}
+			size := s.size()
+			signature := make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			v.FillBytes(signature[size:])
+			return signature, nil
+		},
+	}, nil
+}
+
+func (s ecdsaSigner) Verify(key, message, signature []byte) (bool, error) {
+	public, err := publicKey[*ecdsa.PublicKey](key, s.jwa)
+	if err != nil {
+		return false, err
+	}
+	if public.Curve != s.curve {
+		return false, s.curveError()
+	}
+	size := s.size()
+	if len(signature) != 2*size {
+		return false, nil
+	}
+	r := new(big.Int).SetBytes(signature[:size])
+	v := new(big.Int).SetBytes(signature[size:])
+	return ecdsa.Verify(public, s.digest(message), r, v), nil
+}
+
+func (s ecdsaSigner) PublicKey(key []byte) (*entity.JWK, error) {
+	public, err := publicKey[*ecdsa.PublicKey](key, s.jwa)
+	if err != nil {
+		return nil, err
+	}
+	if public.Curve != s.curve {
+		return nil, s.curveError()
+	}
+	size := s.size()
+	return &entity.JWK{
+		KeyType:   "EC",
+		Algorithm: s.jwa,
+		Use:       _signatureUse,
+		Curve:     s.curve.Params().Name,
+		X:         base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size))),
+		Y:         base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size))),
+	}, nil
+}
+
+// size returns the size of the curve coordinates in bytes
+func (s ecdsaSigner) size() int {
+	return (s.curve.Params().BitSize + 7) / 8
+}
+
+func (s ecdsaSigner) digest(message []byte) []byte {
+	h := s.hash.New()
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+func (s ecdsaSigner) curveError() error {
+	return fmt.Errorf("%s key must be on the %s curve: %w", s.jwa, s.curve.Params().Name, entity.ErrInvalidArgument)
+}
+
+// rsaPSSSigner signs the hash of the message with RSA keys using the PSS padding with the salt of the hash size
+type rsaPSSSigner struct {
+	hash crypto.Hash
+	jwa  string
+}
+
+func (s rsaPSSSigner) Sign(key, message []byte) ([]byte, error) {
+	return streamSign(s, key, message)
+}
+
+func (s rsaPSSSigner) NewStream(key []byte) (Stream, error) {
+	private, err := privateKey[*rsa.PrivateKey](key, s.jwa)
+	if err != nil {
+		return nil, err
+	}
+	return &digestStream{
+		hash: s.hash.New(),
+		sign: func(digest []byte) ([]byte, error) {
+			signature, err := rsa.SignPSS(rand.Reader, private, s.hash, digest, s.options())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", s.jwa, err, entity.ErrInvalidArgument)
+			}
+			return signature, nil
+		},
+	}, nil
+}
+
+func (s rsaPSSSigner) Verify(key, message, signature []byte) (bool, error) {
+	public, err := publicKey[*rsa.PublicKey](key, s.jwa)
+	if err != nil {
+		return false, err
+	}
+	return rsa.VerifyPSS(public, s.hash, s.digest(message), signature, s.options()) == nil, nil
+}
+
+func (s rsaPSSSigner) PublicKey(key []byte) (*entity.JWK, error) {
+	public, err := publicKey[*rsa.PublicKey](key, s.jwa)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.JWK{
+		KeyType:   "RSA",
+		Algorithm: s.jwa,
+		Use:       _signatureUse,
+		N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+	}, nil
+}
+
+func (s rsaPSSSigner) digest(message []byte) []byte {
+	h := s.hash.New()
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+func (s rsaPSSSigner) options() *rsa.PSSOptions {
+	return &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: s.hash}
+}
+
+// streamSign signs the whole message with the Stream of the signer
+func streamSign(s StreamSigner, key, message []byte) ([]byte, error) {
+	stream, err := s.NewStream(key)
+	if err != nil {
+		return nil, err
+	}
+	stream.Write(message)
+	return stream.Signature()
+}
+
+// privateKey parses the PEM encoded private key in PKCS #8, PKCS #1 (RSA) or SEC 1 (EC) format
+// and checks that its type matches the algorithm
+func privateKey[T crypto.Signer](key []byte, algorithm string) (T, error) {
+	var typed T
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return typed, fmt.Errorf("%s key must be PEM encoded: %w", algorithm, entity.ErrInvalidArgument)
+	}
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return typed, fmt.Errorf("%s key: %s: %w", algorithm, err, entity.ErrInvalidArgument)
+	}
+	typed, ok := parsed.(T)
+	if !ok {
+		return typed, fmt.Errorf("%s key has the type %T: %w", algorithm, parsed, entity.ErrInvalidArgument)
+	}
+	return typed, nil
+}
+
+// publicKey parses the PEM encoded PKIX public key or derives it from the private key
+// and checks that its type matches the algorithm
+func publicKey[T crypto.PublicKey](key []byte, algorithm string) (T, error) {
+	var typed T
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return typed, fmt.Errorf("%s key must be PEM encoded: %w", algorithm, entity.ErrInvalidArgument)
+	}
+	var parsed any
+	if block.Type == "PUBLIC KEY" {
+		var err error
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return typed, fmt.Errorf("%s key: %s: %w", algorithm, err, entity.ErrInvalidArgument)
+		}
+	} else {
+		private, err := privateKey[crypto.Signer](key, algorithm)
+		if err != nil {
+			return typed, err
+		}
+		parsed = private.Public()
+	}
+	typed, ok := parsed.(T)
+	if !ok {
+		return typed, fmt.Errorf("%s key has the type %T: %w", algorithm, parsed, entity.ErrInvalidArgument)
+	}
+	return typed, nil
+}
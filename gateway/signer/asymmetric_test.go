@@ -0,0 +1,202 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"redis-postgres-service/entity"
+	"testing"
+)
+
+// testPrivateKey returns the PEM encoded PKCS #8 private key
+func testPrivateKey(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// testPublicKey returns the PEM encoded PKIX public key
+func testPublicKey(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func testECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestPublicKeySigner(t *testing.T) {
+	ed25519Key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	ecdsaKey := testECDSAKey(t)
+	rsaKey := testRSAKey(t)
+	tests := []struct {
+		name          string
+		signer        PublicKeySigner
+		key           crypto.Signer
+		signatureSize int
+	}{
+		{
+			name:          "Ed25519",
+			signer:        ed25519Signer{},
+			key:           ed25519Key,
+			signatureSize: ed25519.SignatureSize,
+		},
+		{
+			name:          "ECDSA P-256 SHA-256",
+			signer:        ecdsaSigner{curve: elliptic.P256(), hash: crypto.SHA256, jwa: "ES256"},
+			key:           ecdsaKey,
+			signatureSize: 64,
+		},
+		{
+			name:          "RSA-PSS SHA-256",
+			signer:        rsaPSSSigner{hash: crypto.SHA256, jwa: "PS256"},
+			key:           rsaKey,
+			signatureSize: 256,
+		},
+		{
+			name:          "RSA-PSS SHA-512",
+			signer:        rsaPSSSigner{hash: crypto.SHA512, jwa: "PS512"},
+			key:           rsaKey,
+			signatureSize: 256,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			private := testPrivateKey(t, tt.key)
+			public := testPublicKey(t, tt.key)
+			message := []byte("123")
+			signature, err := tt.signer.Sign(private, message)
+			assert.NoError(t, err)
+			assert.Len(t, signature, tt.signatureSize)
+
+			valid, err := tt.signer.Verify(public, message, signature)
+			assert.NoError(t, err)
+			assert.True(t, valid, "signature is valid with the public key")
+			valid, err = tt.signer.Verify(private, message, signature)
+			assert.NoError(t, err)
+			assert.True(t, valid, "signature is valid with the private key")
+			valid, err = tt.signer.Verify(public, []byte("1234"), signature)
+			assert.NoError(t, err)
+			assert.False(t, valid, "signature of another message is invalid")
+			valid, err = tt.signer.Verify(public, message, signature[1:])
+			assert.NoError(t, err)
+			assert.False(t, valid, "truncated signature is invalid")
+
+			fromPrivate, err := tt.signer.PublicKey(private)
+			assert.NoError(t, err)
+			fromPublic, err := tt.signer.PublicKey(public)
+			assert.NoError(t, err)
+			assert.Equal(t, fromPrivate, fromPublic)
+			assert.Equal(t, "sig", fromPublic.Use)
+		})
+	}
+}
+
+func TestPublicKeySigner_PublicKey(t *testing.T) {
+	ecdsaKey := testECDSAKey(t)
+	rsaKey := testRSAKey(t)
+	encode := base64.RawURLEncoding.EncodeToString
+
+	got, err := ecdsaSigner{curve: elliptic.P256(), hash: crypto.SHA256, jwa: "ES256"}.PublicKey(testPrivateKey(t, ecdsaKey))
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.JWK{
+		KeyType:   "EC",
+		Algorithm: "ES256",
+		Use:       "sig",
+		Curve:     "P-256",
+		X:         encode(ecdsaKey.X.FillBytes(make([]byte, 32))),
+		Y:         encode(ecdsaKey.Y.FillBytes(make([]byte, 32))),
+	}, got)
+
+	got, err = rsaPSSSigner{hash: crypto.SHA256, jwa: "PS256"}.PublicKey(testPrivateKey(t, rsaKey))
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.JWK{
+		KeyType:   "RSA",
+		Algorithm: "PS256",
+		Use:       "sig",
+		N:         encode(rsaKey.N.Bytes()),
+		E:         encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}, got)
+}
+
+func Test_privateKey(t *testing.T) {
+	ecdsaKey := testECDSAKey(t)
+	sec1, err := x509.MarshalECPrivateKey(ecdsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		key       []byte
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, PKCS #8",
+			key:       testPrivateKey(t, ecdsaKey),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, SEC 1",
+			key:       pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Key is not PEM encoded",
+			key:       []byte("key"),
+			assertion: assert.Error,
+		},
+		{
+			name:      "Key is malformed",
+			key:       pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}),
+			assertion: assert.Error,
+		},
+		{
+			name:      "Key has another type",
+			key:       testPrivateKey(t, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))),
+			assertion: assert.Error,
+		},
+		{
+			name:      "Key is on another curve",
+			key:       testPrivateKey(t, p384),
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ecdsaSigner{curve: elliptic.P256(), hash: crypto.SHA256, jwa: "ES256"}.Sign(tt.key, []byte("123"))
+			tt.assertion(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, entity.ErrInvalidArgument)
+			}
+		})
+	}
+}
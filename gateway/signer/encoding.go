@@ -0,0 +1,77 @@
+package signer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"redis-postgres-service/entity"
+	"strings"
+)
+
+// Names of the supported signature encodings
+const (
+	EncodingHex       = "hex"
+	EncodingBase64    = "base64"
+	EncodingBase64URL = "base64url"
+	EncodingRaw       = "raw"
+)
+
+// Encode returns the signature in the encoding: lowercase hex (default), standard base64, base64url without
+// the padding or the raw bytes. entity.ErrInvalidArgument is returned for the unknown encodings.
+func Encode(encoding string, signature []byte) (string, error) {
+	encode, err := encoder(encoding)
+	if err != nil {
+		return "", err
+	}
+	return encode(signature), nil
+}
+
+// CheckEncoding returns entity.ErrInvalidArgument if the encoding is unknown
+func CheckEncoding(encoding string) error {
+	_, err := encoder(encoding)
+	return err
+}
+
+// Decode returns the signature in the encoding as bytes, hex is case-insensitive and base64url
+// is accepted with or without the padding. entity.ErrInvalidArgument is returned for the unknown encodings,
+// malformed signatures and the raw encoding, cause raw signatures can't be passed as a text.
+func Decode(encoding string, signature string) ([]byte, error) {
+	var decoded []byte
+	var err error
+	switch encoding {
+	case "", EncodingHex:
+		decoded, err = hex.DecodeString(signature)
+	case EncodingBase64:
+		decoded, err = base64.StdEncoding.DecodeString(signature)
+	case EncodingBase64URL:
+		decoded, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(signature, "="))
+	case EncodingRaw:
+		return nil, fmt.Errorf("raw signatures can't be verified: %w", entity.ErrInvalidArgument)
+	default:
+		return nil, encodingError(encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signature is not a valid %s string: %w", encoding, entity.ErrInvalidArgument)
+	}
+	return decoded, nil
+}
+
+// encoder returns the function encoding the signature in the encoding
+func encoder(encoding string) (func(signature []byte) string, error) {
+	switch encoding {
+	case "", EncodingHex:
+		return hex.EncodeToString, nil
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString, nil
+	case EncodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString, nil
+	case EncodingRaw:
+		return func(signature []byte) string { return string(signature) }, nil
+	default:
+		return nil, encodingError(encoding)
+	}
+}
+
+func encodingError(encoding string) error {
+	return fmt.Errorf("unknown encoding %s: %w", encoding, entity.ErrInvalidArgument)
+}
@@ -0,0 +1,132 @@
+package signer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"redis-postgres-service/entity"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	signature := []byte{0xfb, 0xff, 0x00, 0x2e}
+	tests := []struct {
+		name      string
+		encoding  string
+		want      string
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, default",
+			encoding:  "",
+			want:      "fbff002e",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, hex",
+			encoding:  EncodingHex,
+			want:      "fbff002e",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, base64",
+			encoding:  EncodingBase64,
+			want:      "+/8ALg==",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, base64url",
+			encoding:  EncodingBase64URL,
+			want:      "-_8ALg",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, raw",
+			encoding:  EncodingRaw,
+			want:      "\xfb\xff\x00\x2e",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Unknown encoding",
+			encoding:  "base32",
+			want:      "",
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.encoding, signature)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, err, CheckEncoding(tt.encoding))
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoding  string
+		signature string
+		want      []byte
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, uppercase hex",
+			encoding:  EncodingHex,
+			signature: "FBFF002E",
+			want:      []byte{0xfb, 0xff, 0x00, 0x2e},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, base64",
+			encoding:  EncodingBase64,
+			signature: "+/8ALg==",
+			want:      []byte{0xfb, 0xff, 0x00, 0x2e},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, base64url with the padding",
+			encoding:  EncodingBase64URL,
+			signature: "-_8ALg==",
+			want:      []byte{0xfb, 0xff, 0x00, 0x2e},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Raw signature",
+			encoding:  EncodingRaw,
+			signature: "\xfb\xff\x00\x2e",
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Malformed base64",
+			encoding:  EncodingBase64,
+			signature: "-_8ALg==",
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Malformed hex",
+			encoding:  "",
+			signature: "xyz",
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unknown encoding",
+			encoding:  "base32",
+			signature: "74",
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.encoding, tt.signature)
+			tt.assertion(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, entity.ErrInvalidArgument)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
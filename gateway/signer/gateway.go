@@ -0,0 +1,236 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+	"hash"
+	"io"
+	"redis-postgres-service/entity"
+	"sort"
+)
+
+// Names of the supported signature algorithms as they appear in the /sign/{algorithm} path
+const (
+	HMACSHA256  = "hmacsha256"
+	HMACSHA384  = "hmacsha384"
+	HMACSHA512  = "hmacsha512"
+	HMACSHA3256 = "hmacsha3-256"
+	HMACSHA3512 = "hmacsha3-512"
+	BLAKE2b256  = "blake2b-256"
+	BLAKE2b512  = "blake2b-512"
+
+	Ed25519         = "ed25519"
+	ECDSAP256SHA256 = "ecdsa-p256-sha256"
+	RSAPSSSHA256    = "rsa-pss-sha256"
+	RSAPSSSHA512    = "rsa-pss-sha512"
+)
+
+// ErrNoPublicKey is returned for the algorithms that sign with a shared secret and have no public key to publish
+var ErrNoPublicKey = fmt.Errorf("algorithm has no public key: %w", entity.ErrInvalidArgument)
+
+// Signer computes the signature of the message with the key and checks the signatures made with it
+type Signer interface {
+	Sign(key, message []byte) ([]byte, error)
+	Verify(key, message, signature []byte) (bool, error)
+}
+
+// PublicKeySigner is a Signer with asymmetric keys that can publish the public part of the private key
+type PublicKeySigner interface {
+	Signer
+	PublicKey(key []byte) (*entity.JWK, error)
+}
+
+// Stream accumulates the message written into it and computes its signature at the end,
+// so that the message doesn't have to be kept in memory
+type Stream interface {
+	io.Writer
+	Signature() ([]byte, error)
+}
+
+// StreamSigner is a Signer that can sign the streamed message
+type StreamSigner interface {
+	Signer
+	NewStream(key []byte) (Stream, error)
+}
+
+// SignerFunc is an adapter to use ordinary functions computing deterministic signatures as Signer
+type SignerFunc func(key, message []byte) ([]byte, error)
+
+// Sign calls f(key, message)
+func (f SignerFunc) Sign(key, message []byte) ([]byte, error) {
+	return f(key, message)
+}
+
+// Verify calls f(key, message) and compares the result with the signature in constant time
+func (f SignerFunc) Verify(key, message, signature []byte) (bool, error) {
+	expected, err := f(key, message)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(expected, signature), nil
+}
+
+type Gateway interface {
+	Sign(ctx context.Context, algorithm string, key, message []byte) ([]byte, error)
+	Verify(ctx context.Context, algorithm string, key, message, signature []byte) (bool, error)
+	PublicKey(ctx context.Context, algorithm string, key []byte) (*entity.JWK, error)
+	NewStream(ctx context.Context, algorithm string, key []byte) (Stream, error)
+	Algorithms() []string
+}
+
+// compile time check that gateway implements Gateway interface
+var _ Gateway = (*gateway)(nil)
+
+// New is a constructor for the Gateway Interface that is provided to the fx
+func New() (Gateway, error) {
+	return &gateway{
+		signers: map[string]Signer{
+			HMACSHA256:  hmacSigner(sha256.New),
+			HMACSHA384:  hmacSigner(sha512.New384),
+			HMACSHA512:  hmacSigner(sha512.New),
+			HMACSHA3256: hmacSigner(sha3.New256),
+			HMACSHA3512: hmacSigner(sha3.New512),
+			BLAKE2b256:  blake2bSigner(blake2b.New256),
+			BLAKE2b512:  blake2bSigner(blake2b.New512),
+
+			Ed25519:         ed25519Signer{},
+			ECDSAP256SHA256: ecdsaSigner{curve: elliptic.P256(), hash: crypto.SHA256, jwa: "ES256"},
+			RSAPSSSHA256:    rsaPSSSigner{hash: crypto.SHA256, jwa: "PS256"},
+			RSAPSSSHA512:    rsaPSSSigner{hash: crypto.SHA512, jwa: "PS512"},
+		},
+	}, nil
+}
+
+type gateway struct {
+	signers map[string]Signer
+}
+
+// Sign computes the signature of the message with the key using the algorithm from the registry.
+// entity.ErrNotFound is returned for the unknown algorithms.
+func (g *gateway) Sign(ctx context.Context, algorithm string, key, message []byte) ([]byte, error) {
+	signer, err := g.signer(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(key, message)
+}
+
+// Verify checks the signature of the message made with the key using the algorithm from the registry.
+// Asymmetric algorithms accept both the private and the public key.
+// entity.ErrNotFound is returned for the unknown algorithms.
+func (g *gateway) Verify(ctx context.Context, algorithm string, key, message, signature []byte) (bool, error) {
+	signer, err := g.signer(algorithm)
+	if err != nil {
+		return false, err
+	}
+	return signer.Verify(key, message, signature)
+}
+
+// PublicKey returns the public part of the private key of the asymmetric algorithm as JWK.
+// ErrNoPublicKey is returned for the algorithms with shared secrets.
+func (g *gateway) PublicKey(ctx context.Context, algorithm string, key []byte) (*entity.JWK, error) {
+	signer, err := g.signer(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	publicKeySigner, ok := signer.(PublicKeySigner)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", algorithm, ErrNoPublicKey)
+	}
+	return publicKeySigner.PublicKey(key)
+}
+
+// NewStream returns the Stream signing the message with the key using the algorithm from the registry.
+// entity.ErrInvalidArgument is returned for the algorithms that need the whole message to sign it, e.g. Ed25519.
+func (g *gateway) NewStream(ctx context.Context, algorithm string, key []byte) (Stream, error) {
+	signer, err := g.signer(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	streamSigner, ok := signer.(StreamSigner)
+	if !ok {
+		return nil, fmt.Errorf("algorithm %s doesn't support streaming: %w", algorithm, entity.ErrInvalidArgument)
+	}
+	return streamSigner.NewStream(key)
+}
+
+// Algorithms returns the sorted names of the supported algorithms
+func (g *gateway) Algorithms() []string {
+	algorithms := make([]string, 0, len(g.signers))
+	for algorithm := range g.signers {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	return algorithms
+}
+
+// signer returns the Signer of the algorithm from the registry
+func (g *gateway) signer(algorithm string) (Signer, error) {
+	signer, ok := g.signers[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("algorithm %s: %w", algorithm, entity.ErrNotFound)
+	}
+	return signer, nil
+}
+
+// hmacSigner returns the Signer computing HMAC with the hash function
+func hmacSigner(h func() hash.Hash) Signer {
+	return macSigner(func(key []byte) (hash.Hash, error) {
+		return hmac.New(h, key), nil
+	})
+}
+
+// blake2bSigner returns the Signer computing BLAKE2b in the keyed mode, the key can't exceed 64 bytes
+func blake2bSigner(h func(key []byte) (hash.Hash, error)) Signer {
+	return macSigner(func(key []byte) (hash.Hash, error) {
+		if len(key) > blake2b.Size {
+			return nil, fmt.Errorf("blake2b key must not exceed %d bytes: %w", blake2b.Size, entity.ErrInvalidArgument)
+		}
+		return h(key)
+	})
+}
+
+// macSigner is a StreamSigner computing the keyed hash created by the function
+type macSigner func(key []byte) (hash.Hash, error)
+
+func (s macSigner) Sign(key, message []byte) ([]byte, error) {
+	return streamSign(s, key, message)
+}
+
+func (s macSigner) Verify(key, message, signature []byte) (bool, error) {
+	return SignerFunc(s.Sign).Verify(key, message, signature)
+}
+
+func (s macSigner) NewStream(key []byte) (Stream, error) {
+	mac, err := s(key)
+	if err != nil {
+		return nil, err
+	}
+	return &digestStream{hash: mac}, nil
+}
+
+// digestStream is a Stream that hashes the message and signs the digest with the sign function,
+// the digest itself is the signature if the function is nil
+type digestStream struct {
+	hash hash.Hash
+	sign func(digest []byte) ([]byte, error)
+}
+
+func (s *digestStream) Write(p []byte) (int, error) {
+	return s.hash.Write(p)
+}
+
+func (s *digestStream) Signature() ([]byte, error) {
+	digest := s.hash.Sum(nil)
+	if s.sign == nil {
+		return digest, nil
+	}
+	return s.sign(digest)
+}
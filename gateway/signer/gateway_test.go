@@ -0,0 +1,310 @@
+package signer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"redis-postgres-service/entity"
+	"strings"
+	"testing"
+)
+
+func Test_gateway_Sign(t *testing.T) {
+	type args struct {
+		algorithm string
+		key       string
+		message   string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		want      string
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, HMAC-SHA256",
+			args:      args{algorithm: HMACSHA256, key: "key", message: "123"},
+			want:      "a7f7739b1dc5b4e922b1226c9fcbdc83498dee375382caee08fd52a13eb7cfe2",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, HMAC-SHA384",
+			args:      args{algorithm: HMACSHA384, key: "key", message: "123"},
+			want:      "a94c9966bd530d65b5b09fd226479926bef037705e2090a0b24ab11922d821a7076c0a8bc120a9b49e41cd38428ec7ec",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, HMAC-SHA512",
+			args:      args{algorithm: HMACSHA512, key: "key", message: "123"},
+			want:      "2ea823c645b1baf845ef76096a6d7fa9e568304ba9f7910bd52f01c03eec39cdfeec54e50b86b62ef5bfb9e6ce5c0be747ec13b3a199f9d235e99a36de369a84",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, HMAC-SHA3-256",
+			args:      args{algorithm: HMACSHA3256, key: "key", message: "123"},
+			want:      "185ec38982c5f1232ae3d382108b6909e05af1c580eed112cef5534316185616",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, HMAC-SHA3-512",
+			args:      args{algorithm: HMACSHA3512, key: "key", message: "123"},
+			want:      "5b75ae53ec4d8b98ba8193dbd4137485478305f120bdd1a8c0852453ca37d1f61cebc5745ae5197ed6405a3dacadf95d8a98a09253960109527231657690141f",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, BLAKE2b-256",
+			args:      args{algorithm: BLAKE2b256, key: "key", message: "123"},
+			want:      "39fe029ad3a9bac11f1fba3cd10dd9d496c8a57377ecd8226bf25b2a983f9b4f",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, BLAKE2b-512",
+			args:      args{algorithm: BLAKE2b512, key: "key", message: "123"},
+			want:      "6e056d69ef3f5a117aafbe1399747208e3b73b71f904842a0157fe1af8d15bbc2a77acfe278e76a027081d5644eda949a6e0a21208c4fdacb1a0a45fd0cffedc",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "BLAKE2b key is too long",
+			args:      args{algorithm: BLAKE2b512, key: strings.Repeat("k", 65), message: "123"},
+			want:      "",
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unknown algorithm",
+			args:      args{algorithm: "md5", key: "key", message: "123"},
+			want:      "",
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := New()
+			got, err := g.Sign(context.Background(), tt.args.algorithm, []byte(tt.args.key), []byte(tt.args.message))
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+		})
+	}
+}
+
+func Test_gateway_Algorithms(t *testing.T) {
+	g, _ := New()
+	assert.Equal(t, []string{
+		BLAKE2b256,
+		BLAKE2b512,
+		ECDSAP256SHA256,
+		Ed25519,
+		HMACSHA256,
+		HMACSHA3256,
+		HMACSHA3512,
+		HMACSHA384,
+		HMACSHA512,
+		RSAPSSSHA256,
+		RSAPSSSHA512,
+	}, g.Algorithms())
+}
+
+func Test_gateway_Verify(t *testing.T) {
+	ed25519Key := testPrivateKey(t, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	ecdsaKey := testPrivateKey(t, testECDSAKey(t))
+	signature, _ := hex.DecodeString("2ea823c645b1baf845ef76096a6d7fa9e568304ba9f7910bd52f01c03eec39cdfeec54e50b86b62ef5bfb9e6ce5c0be747ec13b3a199f9d235e99a36de369a84")
+	type args struct {
+		algorithm string
+		key       []byte
+		message   string
+		signature []byte
+	}
+	tests := []struct {
+		name      string
+		args      args
+		sign      bool
+		want      bool
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, HMAC signature is valid",
+			args:      args{algorithm: HMACSHA512, key: []byte("key"), message: "123", signature: signature},
+			want:      true,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, HMAC signature is invalid",
+			args:      args{algorithm: HMACSHA512, key: []byte("key"), message: "1234", signature: signature},
+			want:      false,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, Ed25519 signature is valid",
+			args:      args{algorithm: Ed25519, key: ed25519Key, message: "123"},
+			sign:      true,
+			want:      true,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, ECDSA signature made with another algorithm is invalid",
+			args:      args{algorithm: ECDSAP256SHA256, key: ecdsaKey, message: "123", signature: signature},
+			want:      false,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Key doesn't match the algorithm",
+			args:      args{algorithm: Ed25519, key: ecdsaKey, message: "123", signature: signature},
+			want:      false,
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unknown algorithm",
+			args:      args{algorithm: "md5", key: []byte("key"), message: "123", signature: signature},
+			want:      false,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			g, _ := New()
+			if tt.sign {
+				tt.args.signature, _ = g.Sign(ctx, tt.args.algorithm, tt.args.key, []byte(tt.args.message))
+			}
+			got, err := g.Verify(ctx, tt.args.algorithm, tt.args.key, []byte(tt.args.message), tt.args.signature)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_gateway_PublicKey(t *testing.T) {
+	ed25519Key := testPrivateKey(t, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	tests := []struct {
+		name      string
+		algorithm string
+		key       []byte
+		want      *entity.JWK
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			algorithm: Ed25519,
+			key:       ed25519Key,
+			want: &entity.JWK{
+				KeyType:   "OKP",
+				Algorithm: "EdDSA",
+				Use:       "sig",
+				Curve:     "Ed25519",
+				X:         "O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik",
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Algorithm has no public key",
+			algorithm: HMACSHA512,
+			key:       []byte("key"),
+			want:      nil,
+			wantErr:   ErrNoPublicKey,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unknown algorithm",
+			algorithm: "md5",
+			key:       []byte("key"),
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := New()
+			got, err := g.PublicKey(context.Background(), tt.algorithm, tt.key)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_gateway_NewStream(t *testing.T) {
+	ecdsaKey := testPrivateKey(t, testECDSAKey(t))
+	tests := []struct {
+		name      string
+		algorithm string
+		key       []byte
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, HMAC-SHA512",
+			algorithm: HMACSHA512,
+			key:       []byte("key"),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, BLAKE2b-256",
+			algorithm: BLAKE2b256,
+			key:       []byte("key"),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, ECDSA P-256",
+			algorithm: ECDSAP256SHA256,
+			key:       ecdsaKey,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Algorithm doesn't support streaming",
+			algorithm: Ed25519,
+			key:       testPrivateKey(t, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))),
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "BLAKE2b key is too long",
+			algorithm: BLAKE2b256,
+			key:       []byte(strings.Repeat("k", 65)),
+			wantErr:   entity.ErrInvalidArgument,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unknown algorithm",
+			algorithm: "md5",
+			key:       []byte("key"),
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			g, _ := New()
+			stream, err := g.NewStream(ctx, tt.algorithm, tt.key)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			// the message is written in chunks to check that the stream signature matches the one-shot signature
+			stream.Write([]byte("1"))
+			stream.Write([]byte("23"))
+			signature, err := stream.Signature()
+			assert.NoError(t, err)
+			valid, err := g.Verify(ctx, tt.algorithm, tt.key, []byte("123"), signature)
+			assert.NoError(t, err)
+			assert.True(t, valid)
+		})
+	}
+}
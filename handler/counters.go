@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	"redis-postgres-service/handler/validation"
+	"strings"
+)
+
+// GetCounter is a GET endpoint that returns the value of the counter stored in redis under the key provided
+// in the path, e.g. /redis/incr/{key}
+// expected JSON response is defined by entity.IncrementResponse
+func (h *handler) GetCounter(w http.ResponseWriter, req *http.Request) {
+	logger := h.logger.With(
+		zap.String("scope", "handler"),
+		zap.String("function", "GetCounter"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
+	).Sugar()
+	logger.Info("Request received")
+	key, err := keyFromPath(req)
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	response, err := h.incrementalCtrl.Get(req.Context(), &entity.CounterRequest{Key: key})
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	writeResponse(w, logger, response)
+}
+
+// ResetCounter is a DELETE endpoint that removes the counter stored in redis under the key provided
+// in the path, e.g. /redis/incr/{key}
+// expected JSON response is defined by entity.IncrementResponse
+func (h *handler) ResetCounter(w http.ResponseWriter, req *http.Request) {
+	logger := h.logger.With(
+		zap.String("scope", "handler"),
+		zap.String("function", "ResetCounter"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
+	).Sugar()
+	logger.Info("Request received")
+	key, err := keyFromPath(req)
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	response, err := h.incrementalCtrl.Reset(req.Context(), &entity.CounterRequest{Key: key})
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	writeResponse(w, logger, response)
+}
+
+// keyFromPath reads the key from the last segment of the request path.
+// Keys containing slashes are expected to be url-encoded, e.g. /redis/incr/a%2Fb
+func keyFromPath(req *http.Request) (string, error) {
+	escapedPath := req.URL.EscapedPath()
+	key, err := url.PathUnescape(escapedPath[strings.LastIndex(escapedPath, "/")+1:])
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", errors.New("key in the path must not be empty")
+	}
+	return key, nil
+}
+
+// IncrementalBatch is a POST endpoint that increments several keys provided in the request body in redis
+// in a single round trip and returns the result of every incrementation. `atomic=true` query param wraps
+// the increments into a transaction the same way as of the BulkAddUsers endpoint.
+// expected JSON request is defined by entity.BatchIncrementRequest
+// expected JSON response is defined by entity.BatchIncrementResponse
+func (h *handler) IncrementalBatch(w http.ResponseWriter, req *http.Request) {
+	e := endpoint(h, "IncrementalBatch", h.incrementalCtrl.IncBatch)
+	e.Bind = func(req *http.Request, request *entity.BatchIncrementRequest) (err error) {
+		request.Atomic, err = atomicFromQuery(req)
+		return err
+	}
+	e.ServeHTTP(w, req)
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	mock_incremental "redis-postgres-service/mocks/controller/incremental"
+	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_users "redis-postgres-service/mocks/controller/users"
+	"testing"
+)
+
+func newCountersTestHandler(ctrl *gomock.Controller, incrementalCtrl *mock_incremental.MockController) *handler {
+	return &handler{
+		logger:          zap.NewNop(),
+		usersCtrl:       mock_users.NewMockController(ctrl),
+		incrementalCtrl: incrementalCtrl,
+		signCtrl:        mock_sign.NewMockController(ctrl),
+		config: internalconfig.HandlerConfig{
+			RequestBodyLimit: 1048576,
+		},
+	}
+}
+
+func Test_handler_GetCounter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockIncrementalCtrl struct {
+		req *entity.CounterRequest
+		res *entity.IncrementResponse
+		err error
+	}
+	tests := []struct {
+		name                string
+		url                 string
+		mockIncrementalCtrl *mockIncrementalCtrl
+		expectedStatusCode  int
+		expectedResponse    string
+	}{
+		{
+			name: "Happy path",
+			url:  "/redis/incr/Alex",
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.CounterRequest{Key: "Alex"},
+				res: &entity.IncrementResponse{Value: "25", TTLSeconds: 10},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"value":25,"ttl_seconds":10}`,
+		},
+		{
+			name: "Happy path, url-encoded key",
+			url:  "/redis/incr/quota%2Falex",
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.CounterRequest{Key: "quota/alex"},
+				res: &entity.IncrementResponse{Value: "0"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"value":0}`,
+		},
+		{
+			name:               "empty key",
+			url:                "/redis/incr/",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"key in the path must not be empty",
+			),
+		},
+		{
+			name: "key not found",
+			url:  "/redis/incr/Alex",
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.CounterRequest{Key: "Alex"},
+				err: fmt.Errorf("key Alex: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "key Alex: not found"),
+		},
+		{
+			name: "controller fails",
+			url:  "/redis/incr/Alex",
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.CounterRequest{Key: "Alex"},
+				err: errors.New("some error"),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
+		},
+		{
+			name: "redis is unavailable",
+			url:  "/redis/incr/Alex",
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.CounterRequest{Key: "Alex"},
+				err: fmt.Errorf("redis get failed: dial tcp: %w", entity.ErrUnavailable),
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedResponse: problemBody(
+				http.StatusServiceUnavailable,
+				problem.CodeUnavailable,
+				"downstream service is unavailable, retry later",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			incrementalCtrlMock := mock_incremental.NewMockController(ctrl)
+			if tt.mockIncrementalCtrl != nil {
+				incrementalCtrlMock.
+					EXPECT().
+					Get(httpreq.Context(), tt.mockIncrementalCtrl.req).
+					Return(tt.mockIncrementalCtrl.res, tt.mockIncrementalCtrl.err)
+			}
+			h := newCountersTestHandler(ctrl, incrementalCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.GetCounter).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_ResetCounter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockIncrementalCtrl struct {
+		req *entity.CounterRequest
+		res *entity.IncrementResponse
+		err error
+	}
+	tests := []struct {
+		name                string
+		url                 string
+		mockIncrementalCtrl *mockIncrementalCtrl
+		expectedStatusCode  int
+		expectedResponse    string
+	}{
+		{
+			name: "Happy path",
+			url:  "/redis/incr/Alex",
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.CounterRequest{Key: "Alex"},
+				res: &entity.IncrementResponse{Value: "0"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"value":0}`,
+		},
+		{
+			name: "key not found",
+			url:  "/redis/incr/Alex",
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.CounterRequest{Key: "Alex"},
+				err: fmt.Errorf("key Alex: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "key Alex: not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodDelete, tt.url, nil)
+			incrementalCtrlMock := mock_incremental.NewMockController(ctrl)
+			if tt.mockIncrementalCtrl != nil {
+				incrementalCtrlMock.
+					EXPECT().
+					Reset(httpreq.Context(), tt.mockIncrementalCtrl.req).
+					Return(tt.mockIncrementalCtrl.res, tt.mockIncrementalCtrl.err)
+			}
+			h := newCountersTestHandler(ctrl, incrementalCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.ResetCounter).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_IncrementalBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockIncrementalCtrl struct {
+		req *entity.BatchIncrementRequest
+		res *entity.BatchIncrementResponse
+		err error
+	}
+	tests := []struct {
+		name                string
+		url                 string
+		body                string
+		mockIncrementalCtrl *mockIncrementalCtrl
+		expectedStatusCode  int
+		expectedResponse    string
+	}{
+		{
+			name: "Happy path",
+			url:  "/redis/incr/batch?atomic=true",
+			body: `{"items":[{"key":"a","value":1},{"key":"b","value":-2}]}`,
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.BatchIncrementRequest{
+					Atomic: true,
+					Items:  []entity.IncrementItem{{Key: "a", Value: 1}, {Key: "b", Value: -2}},
+				},
+				res: &entity.BatchIncrementResponse{
+					Results: []entity.BatchIncrementResult{
+						{Key: "a", Value: "1"},
+						{Key: "b", Error: "increment overflows the stored value"},
+					},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"results":[{"key":"a","value":1},{"key":"b","error":"increment overflows the stored value"}]}`,
+		},
+		{
+			name:               "atomic is not a boolean",
+			url:                "/redis/incr/batch?atomic=maybe",
+			body:               `{"items":[{"key":"a","value":1}]}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"atomic must be a boolean",
+			),
+		},
+		{
+			name:               "atomic in the body",
+			body:               `{"atomic":true,"items":[{"key":"a","value":1}]}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				`failed to unmarshal: json: unknown field "atomic"`,
+			),
+		},
+		{
+			name:               "malformed body",
+			body:               `{"items":[`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: unexpected end of JSON input",
+			),
+		},
+		{
+			name: "controller rejects the request",
+			body: `{"items":[]}`,
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.BatchIncrementRequest{Items: []entity.IncrementItem{}},
+				err: fmt.Errorf("items must not be empty: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"items must not be empty: invalid argument",
+			),
+		},
+		{
+			name: "controller fails",
+			body: `{"items":[{"key":"a","value":1}]}`,
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.BatchIncrementRequest{Items: []entity.IncrementItem{{Key: "a", Value: 1}}},
+				err: errors.New("some error"),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := tt.url
+			if url == "" {
+				url = "/redis/incr/batch"
+			}
+			httpreq, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(tt.body)))
+			incrementalCtrlMock := mock_incremental.NewMockController(ctrl)
+			if tt.mockIncrementalCtrl != nil {
+				incrementalCtrlMock.
+					EXPECT().
+					IncBatch(httpreq.Context(), tt.mockIncrementalCtrl.req).
+					Return(tt.mockIncrementalCtrl.res, tt.mockIncrementalCtrl.err)
+			}
+			h := newCountersTestHandler(ctrl, incrementalCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.IncrementalBatch).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_IncrementalBatch_BodyIsTooBig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	h := newCountersTestHandler(ctrl, mock_incremental.NewMockController(ctrl))
+	h.config.RequestBodyLimit = 5
+	httpreq, _ := http.NewRequest(http.MethodPost, "/redis/incr/batch", bytes.NewReader([]byte(`{"items":[]}`)))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(h.IncrementalBatch).ServeHTTP(rr, httpreq)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, problemBody(
+		http.StatusBadRequest,
+		problem.CodeInvalidArgument,
+		"request body is too big",
+	), rr.Body.String())
+}
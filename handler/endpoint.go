@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	"redis-postgres-service/handler/validation"
+	mapper "redis-postgres-service/mapper/common"
+	"strconv"
+)
+
+// Validator is implemented by the requests that check their own fields after decoding.
+// Requests that fail the check are rejected with 400 before reaching the controller,
+// the invalid fields of entity.ValidationError are listed in the problem details.
+type Validator interface {
+	Validate() error
+}
+
+// Endpoint adapts a controller method to the http endpoint: it reads the body limited by BodyLimit, decodes it
+// into Req by the codec of the Content-Type, completes it by Bind, validates it and passes it to Call. Errors
+// of the controller are written as the problem details, the response is encoded by the codec negotiated
+// by the Accept header unless Write is set. JSON is used if the headers are missing.
+type Endpoint[Req, Resp any] struct {
+	// Name is the name of the endpoint in the logs
+	Name string
+	// BodyLimit caps the request body, bigger bodies are rejected with 400
+	BodyLimit int64
+	// Call is the controller method processing the decoded request
+	Call func(ctx context.Context, request *Req) (*Resp, error)
+	// Bind is optional, it completes the decoded request from the http request, e.g. by the values from the path.
+	// Errors returned by Bind are written as 400.
+	Bind func(req *http.Request, request *Req) error
+	// Write is optional, it replaces the encoding of the response by the negotiated codec
+	Write func(w http.ResponseWriter, logger *zap.SugaredLogger, response *Resp, codec mapper.Codec)
+	// Strict is optional, it overrides the strict decoding of the body, see mapper.Strict.
+	// If it is nil, bodies of the mutating requests are decoded strictly.
+	Strict *bool
+
+	logger *zap.Logger
+}
+
+// NewEndpoint returns the Endpoint calling the controller method with the request body limit of the handler config
+func NewEndpoint[Req, Resp any](
+	logger *zap.Logger,
+	name string,
+	bodyLimit int64,
+	call func(ctx context.Context, request *Req) (*Resp, error),
+) *Endpoint[Req, Resp] {
+	return &Endpoint[Req, Resp]{Name: name, BodyLimit: bodyLimit, Call: call, logger: logger}
+}
+
+// endpoint returns the Endpoint of the handler calling the controller method, the strict decoding
+// is overridden by HandlerConfig.StrictDecoding
+func endpoint[Req, Resp any](
+	h *handler,
+	name string,
+	call func(ctx context.Context, request *Req) (*Resp, error),
+) *Endpoint[Req, Resp] {
+	e := NewEndpoint(h.logger, name, h.config.RequestBodyLimit, call)
+	e.Strict = h.strictDecoding(name)
+	return e
+}
+
+// strictDecoding returns the strict decoding of the endpoint configured by HandlerConfig.StrictDecoding,
+// nil if it is not overridden
+func (h *handler) strictDecoding(name string) *bool {
+	strict, ok := h.config.StrictDecoding[name]
+	if !ok {
+		return nil
+	}
+	return &strict
+}
+
+// ServeHTTP implements http.Handler
+func (e *Endpoint[Req, Resp]) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	logger := e.logger.With(
+		zap.String("scope", "handler"),
+		zap.String("function", e.Name),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
+	).Sugar()
+	logger.Info("Request received")
+	defer req.Body.Close()
+	w.Header().Add("Vary", "Accept")
+	requestCodec, responseCodec, err := codecs(req)
+	if err != nil {
+		problem.Error(w, err.Error(), decodingStatus(err))
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	data, ok := readBody(w, req, e.BodyLimit, logger)
+	if !ok {
+		return
+	}
+	opts := append(decodeOptions(req, e.Strict), mapper.WithCodec(requestCodec))
+	request, err := mapper.BytesToType[Req](data, opts...)
+	if err == nil && e.Bind != nil {
+		err = e.Bind(req, request)
+	}
+	if err == nil {
+		err = validate(request)
+	}
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest, problemDetails(err)...)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	response, err := e.Call(req.Context(), request)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if e.Write != nil {
+		e.Write(w, logger, response, responseCodec)
+		return
+	}
+	writeEncodedResponse(w, logger, response, responseCodec)
+}
+
+// codecs returns the codec of the request body selected by the Content-Type and the codec of the response
+// negotiated by the Accept header
+func codecs(req *http.Request) (mapper.Codec, mapper.Codec, error) {
+	requestCodec, err := mapper.CodecFor(req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, err
+	}
+	responseCodec, err := mapper.Negotiate(req.Header.Get("Accept"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return requestCodec, responseCodec, nil
+}
+
+// decodingStatus returns the http status of the response to the request that can't be decoded
+func decodingStatus(err error) int {
+	switch {
+	case errors.Is(err, mapper.ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.Is(err, mapper.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// decodeOptions returns the options decoding the body strictly if it is required by strict
+// or, if strict is nil, by the mutating method of the request
+func decodeOptions(req *http.Request, strict *bool) []mapper.Option {
+	if strict != nil && !*strict {
+		return nil
+	}
+	if strict == nil && !mutating(req.Method) {
+		return nil
+	}
+	return []mapper.Option{mapper.Strict()}
+}
+
+// atomicFromQuery reads the `atomic` query param of the batch endpoints, that is false if it is not set
+func atomicFromQuery(req *http.Request) (bool, error) {
+	atomic := req.URL.Query().Get("atomic")
+	if atomic == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(atomic)
+	if err != nil {
+		return false, errors.New("atomic must be a boolean")
+	}
+	return value, nil
+}
+
+// mutating reports whether the method changes the state of the service
+func mutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+// validate checks the request if it implements Validator
+func validate(request any) error {
+	if v, ok := request.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// readBody reads the request body limited by the limit and writes the error response
+// if the body is too big or can't be read. One byte over the limit is read, so that the chunked bodies
+// of unknown length are rejected rather than silently truncated.
+func readBody(w http.ResponseWriter, req *http.Request, limit int64, logger *zap.SugaredLogger) ([]byte, bool) {
+	if req.ContentLength > limit {
+		problem.Error(w, entity.RequestBodyIsTooBig, http.StatusBadRequest)
+		logger.Error(entity.RequestBodyIsTooBig)
+		return nil, false
+	}
+	data, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	if err != nil {
+		problem.Error(w, entity.UnableToReadTheBody, http.StatusBadRequest)
+		logger.Error(entity.UnableToReadTheBody)
+		return nil, false
+	}
+	if int64(len(data)) > limit {
+		problem.Error(w, entity.RequestBodyIsTooBig, http.StatusBadRequest)
+		logger.Error(entity.RequestBodyIsTooBig)
+		return nil, false
+	}
+	return data, true
+}
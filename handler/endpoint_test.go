@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	mapper "redis-postgres-service/mapper/common"
+	"testing"
+)
+
+type echoRequest struct {
+	Name   string `json:"name"`
+	Suffix string `json:"-"`
+}
+
+func (r *echoRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type echoResponse struct {
+	Echo string `json:"echo"`
+}
+
+func TestEndpoint_ServeHTTP(t *testing.T) {
+	echo := func(_ context.Context, request *echoRequest) (*echoResponse, error) {
+		if request.Name == "missing" {
+			return nil, fmt.Errorf("user %s: %w", request.Name, entity.ErrNotFound)
+		}
+		if request.Name == "taken" {
+			return nil, &entity.PublicError{
+				Message: "user conflicts with the stored users",
+				Err:     fmt.Errorf(`duplicate key value violates unique constraint "users_name_key": %w`, entity.ErrConflict),
+			}
+		}
+		return &echoResponse{Echo: request.Name + request.Suffix}, nil
+	}
+	tests := []struct {
+		name               string
+		method             string
+		body               string
+		bodyLimit          int64
+		strict             *bool
+		headers            map[string]string
+		bind               func(req *http.Request, request *echoRequest) error
+		write              func(w http.ResponseWriter, logger *zap.SugaredLogger, response *echoResponse, codec mapper.Codec)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "Happy path",
+			body:               `{"name":"alex"}`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"echo":"alex"}`,
+		},
+		{
+			name:      "request is completed from the http request",
+			body:      `{"name":"alex"}`,
+			bodyLimit: 1024,
+			bind: func(req *http.Request, request *echoRequest) error {
+				request.Suffix = req.URL.Path
+				return nil
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"echo":"alex/echo"}`,
+		},
+		{
+			name:      "response is written by the custom writer",
+			body:      `{"name":"alex"}`,
+			bodyLimit: 1024,
+			write: func(w http.ResponseWriter, _ *zap.SugaredLogger, response *echoResponse, _ mapper.Codec) {
+				_, _ = w.Write([]byte(response.Echo))
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `alex`,
+		},
+		{
+			name:               "body is decoded by the Content-Type, response is encoded by the Accept",
+			body:               "\x81\xa4name\xa4alex",
+			bodyLimit:          1024,
+			headers:            map[string]string{"Content-Type": "application/msgpack", "Accept": "application/cbor"},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "\xa1\x64echo\x64alex",
+		},
+		{
+			name:               "no codec for the Content-Type",
+			body:               `name: alex`,
+			bodyLimit:          1024,
+			headers:            map[string]string{"Content-Type": "application/yaml"},
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedResponse: problemBody(
+				http.StatusUnsupportedMediaType,
+				problem.CodeUnsupportedMedia,
+				"unsupported media type application/yaml",
+			),
+		},
+		{
+			name:               "form body",
+			body:               `{"name":"alex"}`,
+			bodyLimit:          1024,
+			headers:            map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedResponse: problemBody(
+				http.StatusUnsupportedMediaType,
+				problem.CodeUnsupportedMedia,
+				"unsupported media type application/x-www-form-urlencoded",
+			),
+		},
+		{
+			name:               "no codec for the Accept",
+			body:               `{"name":"alex"}`,
+			bodyLimit:          1024,
+			headers:            map[string]string{"Accept": "text/html"},
+			expectedStatusCode: http.StatusNotAcceptable,
+			expectedResponse: problemBody(
+				http.StatusNotAcceptable,
+				problem.CodeNotAcceptable,
+				"not acceptable text/html",
+			),
+		},
+		{
+			name:               "request body too big",
+			body:               `{"name":"alex"}`,
+			bodyLimit:          1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   problemBody(http.StatusBadRequest, problem.CodeInvalidArgument, "request body is too big"),
+		},
+		{
+			name:               "body is not a valid json",
+			body:               `{"name":_`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of value",
+			),
+		},
+		{
+			name:               "body of the mutating request has unknown fields",
+			body:               `{"nmae":"alex"}`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				`failed to unmarshal: json: unknown field "nmae"`,
+			),
+		},
+		{
+			name:               "body of the mutating request has the field in another case",
+			body:               `{"Name":"alex"}`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				`failed to unmarshal: json: unknown field "Name"`,
+			),
+		},
+		{
+			name:               "body of the safe request is decoded leniently",
+			method:             http.MethodGet,
+			body:               `{"Name":"alex","nmae":"bob"}`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"echo":"alex"}`,
+		},
+		{
+			name:               "strict decoding is disabled",
+			body:               `{"name":"alex","nmae":"bob"} {}`,
+			bodyLimit:          1024,
+			strict:             new(bool),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '{' after top-level value",
+			),
+		},
+		{
+			name:               "strict decoding is disabled, unknown fields are ignored",
+			body:               `{"name":"alex","nmae":"bob"}`,
+			bodyLimit:          1024,
+			strict:             new(bool),
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"echo":"alex"}`,
+		},
+		{
+			name:      "binding fails",
+			body:      `{"name":"alex"}`,
+			bodyLimit: 1024,
+			bind: func(*http.Request, *echoRequest) error {
+				return errors.New("id in the path must be a positive integer")
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"id in the path must be a positive integer",
+			),
+		},
+		{
+			name:               "request is not valid",
+			body:               `{}`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   problemBody(http.StatusBadRequest, problem.CodeInvalidArgument, "name is required"),
+		},
+		{
+			name:               "controller fails",
+			body:               `{"name":"missing"}`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "user missing: not found"),
+		},
+		{
+			name:               "controller fails with the public error",
+			body:               `{"name":"taken"}`,
+			bodyLimit:          1024,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   problemBody(http.StatusConflict, problem.CodeConflict, "user conflicts with the stored users"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			httpreq, _ := http.NewRequest(method, "/echo", bytes.NewReader([]byte(tt.body)))
+			for name, value := range tt.headers {
+				httpreq.Header.Set(name, value)
+			}
+			e := NewEndpoint(zap.NewNop(), "Echo", tt.bodyLimit, echo)
+			e.Bind = tt.bind
+			e.Write = tt.write
+			e.Strict = tt.strict
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+			assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"go.uber.org/config"
 	"go.uber.org/fx"
@@ -8,19 +9,64 @@ import (
 	"io"
 	"net/http"
 	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/controller/httpsig"
 	"redis-postgres-service/controller/incremental"
 	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/controller/tokens"
 	"redis-postgres-service/controller/users"
 	"redis-postgres-service/entity"
+	"redis-postgres-service/gateway/signer"
+	"redis-postgres-service/handler/problem"
+	"redis-postgres-service/handler/validation"
 	mapper "redis-postgres-service/mapper/common"
+	"strconv"
+	"strings"
 )
 
-const configKey = "handler"
+// ConfigKey is the key of the handler configuration, see config.HandlerConfig
+const ConfigKey = "handler"
+
+const (
+	// _signPathPrefix is the path prefix of the signature endpoints followed by the algorithm name
+	_signPathPrefix = "/sign/"
+	// VerifyPathSuffix is the path suffix of the signature verification endpoint, e.g. /sign/{algorithm}/verify
+	VerifyPathSuffix = "/verify"
+	// StreamPathSuffix is the path suffix of the streamed signature endpoint, e.g. /sign/{algorithm}/stream
+	StreamPathSuffix = "/stream"
+)
+
+// Headers of the streamed signature request that reference the key, the body is the raw message to sign
+const (
+	SignatureKeyHeader        = "X-Signature-Key"
+	SignatureKeyIDHeader      = "X-Signature-Key-Id"
+	SignatureKeyVersionHeader = "X-Signature-Key-Version"
+	SignatureEncodingHeader   = "X-Signature-Encoding"
+)
+
+// Headers of the signature response with the raw encoding, the body is the signature itself
+const (
+	SignatureAlgorithmHeader = "X-Signature-Algorithm"
+)
 
 type Handler interface {
 	Incremental(w http.ResponseWriter, req *http.Request)
+	IncrementalBatch(w http.ResponseWriter, req *http.Request)
 	Signature(w http.ResponseWriter, req *http.Request)
+	SignatureStream(w http.ResponseWriter, req *http.Request)
+	Verify(w http.ResponseWriter, req *http.Request)
+	PublicKeys(w http.ResponseWriter, req *http.Request)
+	HTTPSign(w http.ResponseWriter, req *http.Request)
+	HTTPVerify(w http.ResponseWriter, req *http.Request)
+	IssueToken(w http.ResponseWriter, req *http.Request)
+	VerifyToken(w http.ResponseWriter, req *http.Request)
+	GetCounter(w http.ResponseWriter, req *http.Request)
+	ResetCounter(w http.ResponseWriter, req *http.Request)
 	AddUser(w http.ResponseWriter, req *http.Request)
+	BulkAddUsers(w http.ResponseWriter, req *http.Request)
+	GetUser(w http.ResponseWriter, req *http.Request)
+	ListUsers(w http.ResponseWriter, req *http.Request)
+	UpdateUser(w http.ResponseWriter, req *http.Request)
+	DeleteUser(w http.ResponseWriter, req *http.Request)
 }
 
 // Compile time check that handler implements Handler interface
@@ -31,6 +77,8 @@ type handler struct {
 	usersCtrl       users.Controller
 	incrementalCtrl incremental.Controller
 	signCtrl        sign.Controller
+	httpsigCtrl     httpsig.Controller
+	tokensCtrl      tokens.Controller
 	config          internalconfig.HandlerConfig
 }
 
@@ -43,12 +91,14 @@ type Params struct {
 	UsersCtrl       users.Controller
 	IncrementalCtrl incremental.Controller
 	SignController  sign.Controller
+	HTTPSigCtrl     httpsig.Controller
+	TokensCtrl      tokens.Controller
 }
 
 // New is a constructor of Handler interface that is provided to the fx
 func New(p Params) (Handler, error) {
 	var cfg internalconfig.HandlerConfig
-	err := p.ConfigProvider.Get(configKey).Populate(&cfg) // unreachable in tests, cause provider is populating from valid yaml.
+	err := p.ConfigProvider.Get(ConfigKey).Populate(&cfg) // unreachable in tests, cause provider is populating from valid yaml.
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +107,8 @@ func New(p Params) (Handler, error) {
 		usersCtrl:       p.UsersCtrl,
 		incrementalCtrl: p.IncrementalCtrl,
 		signCtrl:        p.SignController,
+		httpsigCtrl:     p.HTTPSigCtrl,
+		tokensCtrl:      p.TokensCtrl,
 		config:          cfg,
 	}, nil
 }
@@ -66,142 +118,109 @@ func New(p Params) (Handler, error) {
 // expected JSON request is defined by entity.IncrementRequest
 // expected JSON response is defined by entity.IncrementResponse
 func (h *handler) Incremental(w http.ResponseWriter, req *http.Request) {
+	endpoint(h, "Incremental", h.incrementalCtrl.Inc).ServeHTTP(w, req)
+}
+
+// Signature is a POST endpoint to that signs the provided text in the request body by the key using
+// the algorithm provided in the path, e.g. /sign/hmacsha512, and returns a respective Hex signature
+// expected JSON request is defined by entity.SignRequest
+// expected JSON response is defined by entity.SignResponse
+func (h *handler) Signature(w http.ResponseWriter, req *http.Request) {
+	e := endpoint(h, "Signature", h.signCtrl.Sign)
+	e.Bind = func(req *http.Request, request *entity.SignRequest) (err error) {
+		request.Algorithm, err = algorithmFromPath(req, "")
+		return err
+	}
+	e.Write = writeSignResponse
+	e.ServeHTTP(w, req)
+}
+
+// SignatureStream is a POST endpoint that signs the raw request body by the key using the algorithm provided
+// in the path, e.g. /sign/hmacsha512/stream. The body is streamed into the signature, so it is capped
+// by HandlerConfig.StreamRequestBodyLimit rather than HandlerConfig.RequestBodyLimit.
+// The key is referenced by the X-Signature-Key or X-Signature-Key-Id and X-Signature-Key-Version headers.
+// expected JSON response is defined by entity.SignResponse
+func (h *handler) SignatureStream(w http.ResponseWriter, req *http.Request) {
 	logger := h.logger.With(
 		zap.String("scope", "handler"),
-		zap.String("function", "Incremental"),
+		zap.String("function", "SignatureStream"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
 	).Sugar()
-	logger.Info("Incoming request")
+	logger.Info("Request received")
 	defer req.Body.Close()
-	if req.ContentLength > h.config.RequestBodyLimit {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.BadRequest, "request body is too big"),
-			http.StatusBadRequest,
-		)
-		logger.Error(entity.UnableToReadTheBody)
+	algorithm, err := algorithmFromPath(req, StreamPathSuffix)
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Errorf(entity.BadRequest, err)
 		return
 	}
-	data, err := io.ReadAll(io.LimitReader(req.Body, h.config.RequestBodyLimit))
-	if err != nil {
-		http.Error(w, entity.UnableToReadTheBody, http.StatusBadRequest)
-		logger.Error(entity.UnableToReadTheBody)
+	limit := h.config.StreamRequestBodyLimit
+	if limit > 0 && req.ContentLength > limit {
+		problem.Error(w, entity.RequestBodyIsTooBig, http.StatusBadRequest)
+		logger.Error(entity.RequestBodyIsTooBig)
 		return
 	}
-	request, err := mapper.BytesToType[entity.IncrementRequest](data)
-	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.BadRequest, err),
-			http.StatusBadRequest,
-		)
+	request := &entity.SignStreamRequest{
+		Algorithm: algorithm,
+		Key:       req.Header.Get(SignatureKeyHeader),
+		KeyID:     req.Header.Get(SignatureKeyIDHeader),
+		Encoding:  req.Header.Get(SignatureEncodingHeader),
+		Body:      req.Body,
+	}
+	if version := req.Header.Get(SignatureKeyVersionHeader); version != "" {
+		request.KeyVersion, err = strconv.Atoi(version)
+		if err != nil {
+			err = fmt.Errorf("%s header must be an integer", SignatureKeyVersionHeader)
+			problem.Error(w, err.Error(), http.StatusBadRequest)
+			logger.Errorf(entity.BadRequest, err)
+			return
+		}
+	}
+	if err = request.Validate(); err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest, problemDetails(err)...)
 		logger.Errorf(entity.BadRequest, err)
 		return
 	}
-	logger.With("request", request).Info("Request received")
-	response, err := h.incrementalCtrl.Inc(req.Context(), request)
-	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToProcessTheRequest, err),
-			http.StatusBadGateway,
-		)
-		logger.Errorf(entity.FailedToProcessTheRequest, err)
-		return
+	if limit > 0 {
+		request.Body = http.MaxBytesReader(w, req.Body, limit)
 	}
-	incrementResponse, err := mapper.TypeToBytes[entity.IncrementResponse](response)
+	response, err := h.signCtrl.SignStream(req.Context(), request)
 	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToProcessTheResponse, err),
-			http.StatusInternalServerError,
-		)
-		logger.Errorf(entity.FailedToProcessTheResponse, err)
-		return // unreachable in tests cause response struct can always be represented as json
+		writeError(w, logger, err)
+		return
 	}
-	w.Header().Add("Content-Type", "application/json")
-	_, err = w.Write(incrementResponse)
-	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToWriteTheResponse, err),
-			http.StatusInternalServerError,
-		)
-		logger.Errorf(entity.FailedToWriteTheResponse, err)
-		return // unreachable in tests
+	writeSignResponse(w, logger, response, mapper.JSON)
+}
+
+// Verify is a POST endpoint that checks the hex signature provided in the request body against the text signed
+// by the key using the algorithm provided in the path, e.g. /sign/hmacsha512/verify
+// expected JSON request is defined by entity.VerifyRequest
+// expected JSON response is defined by entity.VerifyResponse
+func (h *handler) Verify(w http.ResponseWriter, req *http.Request) {
+	e := endpoint(h, "Verify", h.signCtrl.Verify)
+	e.Bind = func(req *http.Request, request *entity.VerifyRequest) (err error) {
+		request.Algorithm, err = algorithmFromPath(req, VerifyPathSuffix)
+		return err
 	}
-	logger.With("response", response).Info("Request completed")
-	return
+	e.ServeHTTP(w, req)
 }
 
-// Signature is a POST endpoint to that signs the provided text in the request body by the key using SHA512
-// algorythm and returns a respective Hex signature
-// expected JSON request is defined by entity.SignRequest
-// expected JSON response is defined by entity.SignResponse
-func (h *handler) Signature(w http.ResponseWriter, req *http.Request) {
+// PublicKeys is a GET endpoint that publishes the public keys of the server-side asymmetric signing keys
+// as JSON Web Key Set, e.g. /.well-known/jwks.json
+// expected JSON response is defined by entity.JWKS
+func (h *handler) PublicKeys(w http.ResponseWriter, req *http.Request) {
 	logger := h.logger.With(
 		zap.String("scope", "handler"),
-		zap.String("function", "Signature"),
+		zap.String("function", "PublicKeys"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
 	).Sugar()
 	logger.Info("Request received")
-	defer req.Body.Close()
-	if req.ContentLength > h.config.RequestBodyLimit {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.BadRequest, "request body is too big"),
-			http.StatusBadRequest,
-		)
-		logger.Error(entity.UnableToReadTheBody)
-		return
-	}
-	data, err := io.ReadAll(io.LimitReader(req.Body, h.config.RequestBodyLimit))
-	if err != nil {
-		http.Error(w, entity.UnableToReadTheBody, http.StatusBadRequest)
-		logger.Error(entity.UnableToReadTheBody)
-		return
-	}
-	request, err := mapper.BytesToType[entity.SignRequest](data)
-	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.BadRequest, err),
-			http.StatusBadRequest,
-		)
-		logger.Errorf(entity.BadRequest, err)
-		return
-	}
-	response, err := h.signCtrl.Sign(req.Context(), request)
+	response, err := h.signCtrl.PublicKeys(req.Context())
 	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToProcessTheRequest, err),
-			http.StatusBadGateway,
-		)
-		logger.Errorf(entity.FailedToProcessTheRequest, err)
+		writeError(w, logger, err)
 		return
 	}
-	signResponse, err := mapper.TypeToBytes[entity.SignResponse](response)
-	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToProcessTheResponse, err),
-			http.StatusInternalServerError,
-		)
-		logger.Errorf(entity.FailedToProcessTheResponse, err)
-		return // unreachable in tests cause response struct can always be represented as json
-	}
-	w.Header().Add("Content-Type", "application/json")
-	_, err = w.Write(signResponse)
-	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToWriteTheResponse, err),
-			http.StatusInternalServerError,
-		)
-		logger.Errorf(entity.FailedToWriteTheResponse, err)
-		return // unreachable in tests
-	}
-	logger.With("response", response).Info("Request completed")
-	return
+	writeResponse(w, logger, response)
 }
 
 // AddUser is a POST endpoint to that appends a row to `users` table in postgres
@@ -209,68 +228,104 @@ func (h *handler) Signature(w http.ResponseWriter, req *http.Request) {
 // expected JSON request is defined by entity.AddUserRequest
 // expected JSON response is defined by entity.AddUserResponse
 func (h *handler) AddUser(w http.ResponseWriter, req *http.Request) {
-	logger := h.logger.With(
-		zap.String("scope", "handler"),
-		zap.String("function", "AddUser"),
-	).Sugar()
-	logger.Info("Request received")
-	defer req.Body.Close()
-	if req.ContentLength > h.config.RequestBodyLimit {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.BadRequest, "request body is too big"),
-			http.StatusBadRequest,
-		)
-		logger.Error(entity.RequestBodyIsTooBig)
-		return
+	endpoint(h, "AddUser", h.usersCtrl.Add).ServeHTTP(w, req)
+}
+
+// algorithmFromPath reads the signature algorithm from the request path, e.g. /sign/{algorithm}{suffix}
+func algorithmFromPath(req *http.Request, suffix string) (string, error) {
+	algorithm := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, _signPathPrefix), suffix)
+	if algorithm == "" || algorithm == req.URL.Path {
+		return "", errors.New("algorithm in the path must not be empty")
 	}
-	data, err := io.ReadAll(io.LimitReader(req.Body, h.config.RequestBodyLimit))
-	if err != nil {
-		http.Error(w, entity.UnableToReadTheBody, http.StatusBadRequest)
-		logger.Error(entity.UnableToReadTheBody)
-		return
+	return algorithm, nil
+}
+
+// writeError writes the error returned by a controller as the problem details and logs it, the clients get
+// the public message of the error only, see entity.PublicMessage
+func writeError(w http.ResponseWriter, logger *zap.SugaredLogger, err error) {
+	message, status := controllerError(err)
+	problem.Error(w, entity.PublicMessage(err), status, problemDetails(err)...)
+	logger.Error(message)
+}
+
+// problemDetails returns the invalid fields of the request if the error is entity.ValidationError
+func problemDetails(err error) []entity.ProblemDetail {
+	var verr *entity.ValidationError
+	if errors.As(err, &verr) {
+		return verr.Details
 	}
-	request, err := mapper.BytesToType[entity.AddUserRequest](data)
-	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.BadRequest, err),
-			http.StatusBadRequest,
-		)
-		logger.Errorf(entity.BadRequest, err)
+	return nil
+}
+
+// controllerError maps the error returned by a controller to the log message and http status of the response.
+// Errors that are not caused by the request nor by the downstream availability are internal.
+func controllerError(err error) (string, int) {
+	if errors.Is(err, entity.ErrNotFound) {
+		return fmt.Sprintf(entity.NotFound, err), http.StatusNotFound
+	}
+	if errors.Is(err, entity.ErrInvalidArgument) {
+		return fmt.Sprintf(entity.BadRequest, err), http.StatusBadRequest
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		return fmt.Sprintf(entity.Conflict, err), http.StatusConflict
+	}
+	if errors.Is(err, entity.ErrOverflow) {
+		return fmt.Sprintf(entity.UnprocessableRequest, err), http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, entity.ErrUnavailable) {
+		return fmt.Sprintf(entity.ServiceUnavailable, err), http.StatusServiceUnavailable
+	}
+	return fmt.Sprintf(entity.FailedToProcessTheRequest, err), http.StatusInternalServerError
+}
+
+// writeSignResponse writes the signature response encoded by the codec into the http response. Signatures with
+// the raw encoding can't be represented as JSON, so they are written as the response body, while the rest
+// of the response is moved to the X-Signature-* headers.
+func writeSignResponse(
+	w http.ResponseWriter,
+	logger *zap.SugaredLogger,
+	response *entity.SignResponse,
+	codec mapper.Codec,
+) {
+	if response.Encoding != signer.EncodingRaw {
+		writeEncodedResponse(w, logger, response, codec)
 		return
 	}
-	response, err := h.usersCtrl.Add(req.Context(), request)
+	w.Header().Add("Content-Type", "application/octet-stream")
+	w.Header().Add(SignatureAlgorithmHeader, response.Algorithm)
+	w.Header().Add(SignatureEncodingHeader, response.Encoding)
+	if response.KeyID != "" {
+		w.Header().Add(SignatureKeyIDHeader, response.KeyID)
+		w.Header().Add(SignatureKeyVersionHeader, strconv.Itoa(response.KeyVersion))
+	}
+	_, err := io.WriteString(w, response.Signature)
 	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToProcessTheRequest, err),
-			http.StatusBadGateway,
-		)
-		logger.Errorf(entity.FailedToProcessTheRequest, err)
-		return
+		problem.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Errorf(entity.FailedToWriteTheResponse, err)
+		return // unreachable in tests
 	}
-	addUserResponse, err := mapper.TypeToBytes[entity.AddUserResponse](response)
+	logger.With("algorithm", response.Algorithm).Info("Request completed")
+}
+
+// writeResponse writes the response as JSON into the http response
+func writeResponse[T any](w http.ResponseWriter, logger *zap.SugaredLogger, response *T) {
+	writeEncodedResponse(w, logger, response, mapper.JSON)
+}
+
+// writeEncodedResponse writes the response encoded by the codec into the http response
+func writeEncodedResponse[T any](w http.ResponseWriter, logger *zap.SugaredLogger, response *T, codec mapper.Codec) {
+	data, err := mapper.TypeToBytes[T](response, mapper.WithCodec(codec))
 	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToProcessTheResponse, err),
-			http.StatusInternalServerError,
-		)
+		problem.Error(w, err.Error(), http.StatusInternalServerError)
 		logger.Errorf(entity.FailedToProcessTheResponse, err)
 		return // unreachable in tests cause response struct can always be represented as json
 	}
-	w.Header().Add("Content-Type", "application/json")
-	_, err = w.Write(addUserResponse)
+	w.Header().Add("Content-Type", codec.ContentType())
+	_, err = w.Write(data)
 	if err != nil {
-		http.Error(
-			w,
-			fmt.Sprintf(entity.FailedToWriteTheResponse, err),
-			http.StatusInternalServerError,
-		)
+		problem.Error(w, err.Error(), http.StatusInternalServerError)
 		logger.Errorf(entity.FailedToWriteTheResponse, err)
 		return // unreachable in tests
 	}
 	logger.With("response", response).Info("Request completed")
-	return
 }
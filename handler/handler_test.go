@@ -2,18 +2,25 @@ package handler
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/config"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	internalconfig "redis-postgres-service/config"
 	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
 	mapper "redis-postgres-service/mapper/common"
+	mock_httpsig "redis-postgres-service/mocks/controller/httpsig"
 	mock_incremental "redis-postgres-service/mocks/controller/incremental"
 	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_tokens "redis-postgres-service/mocks/controller/tokens"
 	mock_users "redis-postgres-service/mocks/controller/users"
 	"strings"
 	"testing"
@@ -39,11 +46,15 @@ func TestNew(t *testing.T) {
 	mockUsers := mock_users.NewMockController(ctrl)
 	mockIncremental := mock_incremental.NewMockController(ctrl)
 	mockSign := mock_sign.NewMockController(ctrl)
+	mockHTTPSig := mock_httpsig.NewMockController(ctrl)
+	mockTokens := mock_tokens.NewMockController(ctrl)
 	r, err := New(Params{
 		ConfigProvider:  providerGood,
 		Logger:          logger,
 		UsersCtrl:       mockUsers,
 		SignController:  mockSign,
+		HTTPSigCtrl:     mockHTTPSig,
+		TokensCtrl:      mockTokens,
 		IncrementalCtrl: mockIncremental,
 	})
 	assert.NotNil(t, r)
@@ -81,7 +92,7 @@ func Test_handler_Incremental(t *testing.T) {
 			requestBodyLimit: 1048576,
 			mockIncrementalCtrl: &mockIncrementalCtrl{
 				res: &entity.IncrementResponse{
-					Value: 25,
+					Value: "25",
 				},
 				err: nil,
 			},
@@ -97,7 +108,11 @@ func Test_handler_Incremental(t *testing.T) {
 			},
 			requestBodyLimit:   1,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "bad request, err: request body is too big\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
 		},
 		{
 			name: "body is not a valid json",
@@ -108,7 +123,28 @@ func Test_handler_Incremental(t *testing.T) {
 			},
 			requestBodyLimit:   1048576,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "bad request, err: failed to unmarshal: invalid character '_' looking for beginning of object key string\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of object key string",
+			),
+		},
+		{
+			name: "request is not valid",
+			args: args{
+				method: "POST",
+				url:    "/redis/incr",
+				body:   []byte(`{"key":"Al ex","value":23,"ttl_seconds":-1}`),
+			},
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: key must consist of printable characters without whitespaces, ttl_seconds must not be negative",
+				entity.ProblemDetail{Field: "key", Reason: "must consist of printable characters without whitespaces"},
+				entity.ProblemDetail{Field: "ttl_seconds", Reason: "must not be negative"},
+			),
 		},
 		{
 			name: "io.ReadAll fails",
@@ -120,7 +156,11 @@ func Test_handler_Incremental(t *testing.T) {
 			failureBody:        true,
 			requestBodyLimit:   1048576,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "unable to read the body\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"unable to read the body",
+			),
 		},
 		{
 			name: "controller fails",
@@ -134,8 +174,65 @@ func Test_handler_Incremental(t *testing.T) {
 				res: nil,
 				err: errors.New("some error"),
 			},
-			expectedStatusCode: http.StatusBadGateway,
-			expectedResponse:   "failed to process the request, err: some error\n",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
+		},
+		{
+			name: "controller rejects the request",
+			args: args{
+				method: "POST",
+				url:    "/redis/incr",
+				body:   []byte(`{"key":"Alex","value":1.5}`),
+			},
+			requestBodyLimit: 1048576,
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				res: nil,
+				err: fmt.Errorf("value must be an int64 in int mode: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"value must be an int64 in int mode: invalid argument",
+			),
+		},
+		{
+			name: "increment overflows",
+			args: args{
+				method: "POST",
+				url:    "/redis/incr",
+				body:   []byte(`{"key":"Alex","value":9223372036854775807}`),
+			},
+			requestBodyLimit: 1048576,
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				res: nil,
+				err: fmt.Errorf("redis increment failed: %w", entity.ErrOverflow),
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: problemBody(
+				http.StatusUnprocessableEntity,
+				problem.CodeUnprocessable,
+				"redis increment failed: overflow",
+			),
+		},
+		{
+			name: "Happy path, decimal mode",
+			args: args{
+				method: "POST",
+				url:    "/redis/incr",
+				body:   []byte(`{"key":"Alex","value":"0.10","mode":"decimal"}`),
+			},
+			requestBodyLimit: 1048576,
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				res: &entity.IncrementResponse{Value: "12345678901234567890.10"},
+				err: nil,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"value":12345678901234567890.10}`,
 		},
 	}
 	for _, tt := range tests {
@@ -193,72 +290,168 @@ func Test_handler_Signature(t *testing.T) {
 		mockSignCtrl       *mockSignCtrl
 		expectedStatusCode int
 		expectedResponse   string
+		expectedHeaders    map[string]string
 	}{
 		{
 			name: "Happy path",
 			args: args{
 				method: "POST",
-				url:    "/redis/incr",
+				url:    "/sign/hmacsha512",
 				body:   []byte(`{"key":"Alex","text":"23"}`),
 			},
 			requestBodyLimit: 1048576,
 			mockSignCtrl: &mockSignCtrl{
 				res: &entity.SignResponse{
-					Hex: "12345",
+					Algorithm: "hmacsha512",
+					Encoding:  "hex",
+					Signature: "12345",
+					Hex:       "12345",
+				},
+				err: nil,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"algorithm":"hmacsha512","encoding":"hex","signature":"12345","hex":"12345"}`,
+			expectedHeaders:    map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name: "Happy path, raw encoding",
+			args: args{
+				method: "POST",
+				url:    "/sign/hmacsha512",
+				body:   []byte(`{"key_id":"payments","text":"23","encoding":"raw"}`),
+			},
+			requestBodyLimit: 1048576,
+			mockSignCtrl: &mockSignCtrl{
+				res: &entity.SignResponse{
+					Algorithm:  "hmacsha512",
+					Encoding:   "raw",
+					Signature:  "\x12\x34\x00",
+					KeyID:      "payments",
+					KeyVersion: 2,
 				},
 				err: nil,
 			},
 			expectedStatusCode: http.StatusOK,
-			expectedResponse:   `{"hex":"12345"}`,
+			expectedResponse:   "\x12\x34\x00",
+			expectedHeaders: map[string]string{
+				"Content-Type":            "application/octet-stream",
+				SignatureAlgorithmHeader:  "hmacsha512",
+				SignatureEncodingHeader:   "raw",
+				SignatureKeyIDHeader:      "payments",
+				SignatureKeyVersionHeader: "2",
+			},
+		},
+		{
+			name: "algorithm is missing",
+			args: args{
+				method: "POST",
+				url:    "/sign/",
+				body:   []byte(`{"key":"Alex","text":"23"}`),
+			},
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"algorithm in the path must not be empty",
+			),
+		},
+		{
+			name: "key is missing",
+			args: args{
+				method: "POST",
+				url:    "/sign/hmacsha512",
+				body:   []byte(`{"text":"23","key_version":2}`),
+			},
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: key must be provided if key_id is not, key_version requires key_id",
+				entity.ProblemDetail{Field: "key", Reason: "must be provided if key_id is not"},
+				entity.ProblemDetail{Field: "key_version", Reason: "requires key_id"},
+			),
+		},
+		{
+			name: "unknown algorithm",
+			args: args{
+				method: "POST",
+				url:    "/sign/md5",
+				body:   []byte(`{"key":"Alex","text":"23"}`),
+			},
+			requestBodyLimit: 1048576,
+			mockSignCtrl: &mockSignCtrl{
+				res: nil,
+				err: fmt.Errorf("algorithm md5: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "algorithm md5: not found"),
 		},
 		{
 			name: "request body too big",
 			args: args{
 				method: "POST",
-				url:    "/redis/incr",
+				url:    "/sign/hmacsha512",
 				body:   []byte(`{"key":"Alex","text":23}`),
 			},
 			requestBodyLimit:   1,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "bad request, err: request body is too big\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
 		},
 		{
 			name: "body is not a valid json",
 			args: args{
 				method: "POST",
-				url:    "/redis/incr",
+				url:    "/sign/hmacsha512",
 				body:   []byte(`{"key":"Alex",_`),
 			},
 			requestBodyLimit:   1048576,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "bad request, err: failed to unmarshal: invalid character '_' looking for beginning of object key string\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of object key string",
+			),
 		},
 		{
 			name: "io.ReadAll fails",
 			args: args{
 				method: "POST",
-				url:    "/redis/incr",
+				url:    "/sign/hmacsha512",
 				body:   []byte(`{"key":"Alex","value":23}`),
 			},
 			failureBody:        true,
 			requestBodyLimit:   1048576,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "unable to read the body\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"unable to read the body",
+			),
 		},
 		{
 			name: "controller fails",
 			args: args{
 				method: "POST",
-				url:    "/redis/incr",
-				body:   []byte(`{"key":"Alex","value":23}`),
+				url:    "/sign/hmacsha512",
+				body:   []byte(`{"key":"Alex","text":"23"}`),
 			},
 			requestBodyLimit: 1048576,
 			mockSignCtrl: &mockSignCtrl{
 				res: nil,
 				err: errors.New("some error"),
 			},
-			expectedStatusCode: http.StatusBadGateway,
-			expectedResponse:   "failed to process the request, err: some error\n",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
 		},
 	}
 	for _, tt := range tests {
@@ -273,6 +466,7 @@ func Test_handler_Signature(t *testing.T) {
 			signCtrlMock := mock_sign.NewMockController(ctrl)
 			if req, err := mapper.BytesToType[entity.SignRequest](tt.args.body); err == nil &&
 				tt.mockSignCtrl != nil {
+				req.Algorithm = strings.TrimPrefix(tt.args.url, "/sign/")
 				signCtrlMock.
 					EXPECT().
 					Sign(httpreq.Context(), req).
@@ -292,6 +486,340 @@ func Test_handler_Signature(t *testing.T) {
 			testhandler.ServeHTTP(rr, httpreq)
 			assert.Equal(t, tt.expectedStatusCode, rr.Code)
 			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+			for header, value := range tt.expectedHeaders {
+				assert.Equal(t, value, rr.Header().Get(header))
+			}
+		})
+	}
+}
+
+func Test_handler_SignatureStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockSignCtrl struct {
+		req *entity.SignStreamRequest
+		res *entity.SignResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		url                string
+		headers            map[string]string
+		body               string
+		streamBodyLimit    int64
+		mockSignCtrl       *mockSignCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:    "Happy path, raw key",
+			url:     "/sign/hmacsha512/stream",
+			headers: map[string]string{SignatureKeyHeader: "key"},
+			body:    "123",
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignStreamRequest{Algorithm: "hmacsha512", Key: "key"},
+				res: &entity.SignResponse{Algorithm: "hmacsha512", Encoding: "hex", Signature: "2ea823", Hex: "2ea823"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"algorithm":"hmacsha512","encoding":"hex","signature":"2ea823","hex":"2ea823"}`,
+		},
+		{
+			name: "Happy path, server-side key",
+			url:  "/sign/hmacsha512/stream",
+			headers: map[string]string{
+				SignatureKeyIDHeader:      "payments",
+				SignatureKeyVersionHeader: "2",
+				SignatureEncodingHeader:   "base64",
+			},
+			body:            "123",
+			streamBodyLimit: 1048576,
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignStreamRequest{Algorithm: "hmacsha512", KeyID: "payments", KeyVersion: 2, Encoding: "base64"},
+				res: &entity.SignResponse{Algorithm: "hmacsha512", Encoding: "base64", Signature: "LqgjxQ==", KeyID: "payments", KeyVersion: 2},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"algorithm":"hmacsha512","encoding":"base64","signature":"LqgjxQ==","key_id":"payments","key_version":2}`,
+		},
+		{
+			name:               "algorithm is missing",
+			url:                "/sign//stream",
+			headers:            map[string]string{SignatureKeyHeader: "key"},
+			body:               "123",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"algorithm in the path must not be empty",
+			),
+		},
+		{
+			name:               "request body too big",
+			url:                "/sign/hmacsha512/stream",
+			headers:            map[string]string{SignatureKeyHeader: "key"},
+			body:               "123",
+			streamBodyLimit:    1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
+		},
+		{
+			name:               "key version is not an integer",
+			url:                "/sign/hmacsha512/stream",
+			headers:            map[string]string{SignatureKeyIDHeader: "payments", SignatureKeyVersionHeader: "latest"},
+			body:               "123",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"X-Signature-Key-Version header must be an integer",
+			),
+		},
+		{
+			name:               "key headers are missing",
+			url:                "/sign/hmacsha512/stream",
+			body:               "123",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: key must be provided if key_id is not",
+				entity.ProblemDetail{Field: "key", Reason: "must be provided if key_id is not"},
+			),
+		},
+		{
+			name:    "controller rejects the algorithm",
+			url:     "/sign/ed25519/stream",
+			headers: map[string]string{SignatureKeyIDHeader: "webhooks"},
+			body:    "123",
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignStreamRequest{Algorithm: "ed25519", KeyID: "webhooks"},
+				err: fmt.Errorf("algorithm ed25519 doesn't support streaming: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"algorithm ed25519 doesn't support streaming: invalid argument",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			for header, value := range tt.headers {
+				httpreq.Header.Set(header, value)
+			}
+			signCtrlMock := mock_sign.NewMockController(ctrl)
+			if tt.mockSignCtrl != nil {
+				signCtrlMock.
+					EXPECT().
+					SignStream(httpreq.Context(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req *entity.SignStreamRequest) (*entity.SignResponse, error) {
+						body, err := io.ReadAll(req.Body)
+						assert.NoError(t, err)
+						assert.Equal(t, tt.body, string(body))
+						req.Body = nil
+						assert.Equal(t, tt.mockSignCtrl.req, req)
+						return tt.mockSignCtrl.res, tt.mockSignCtrl.err
+					})
+			}
+			h := &handler{
+				logger:          zap.NewNop(),
+				usersCtrl:       mock_users.NewMockController(ctrl),
+				incrementalCtrl: mock_incremental.NewMockController(ctrl),
+				signCtrl:        signCtrlMock,
+				config: internalconfig.HandlerConfig{
+					StreamRequestBodyLimit: tt.streamBodyLimit,
+				},
+			}
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.SignatureStream).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockSignCtrl struct {
+		req *entity.VerifyRequest
+		res *entity.VerifyResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		url                string
+		body               string
+		requestBodyLimit   int64
+		mockSignCtrl       *mockSignCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:             "Happy path",
+			url:              "/sign/hmacsha512/verify",
+			body:             `{"key":"Alex","text":"23","signature":"12ab"}`,
+			requestBodyLimit: 1048576,
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.VerifyRequest{Algorithm: "hmacsha512", Key: "Alex", Text: "23", Signature: "12ab"},
+				res: &entity.VerifyResponse{Algorithm: "hmacsha512", Valid: true},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"algorithm":"hmacsha512","valid":true}`,
+		},
+		{
+			name:               "algorithm is missing",
+			url:                "/sign//verify",
+			body:               `{"key":"Alex","text":"23","signature":"12ab"}`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"algorithm in the path must not be empty",
+			),
+		},
+		{
+			name:               "request body too big",
+			url:                "/sign/hmacsha512/verify",
+			body:               `{"key":"Alex","text":"23","signature":"12ab"}`,
+			requestBodyLimit:   1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
+		},
+		{
+			name:               "body is not a valid json",
+			url:                "/sign/hmacsha512/verify",
+			body:               `{"key":"Alex",_`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of object key string",
+			),
+		},
+		{
+			name:               "key and signature are missing",
+			url:                "/sign/hmacsha512/verify",
+			body:               `{"text":"23"}`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: signature must not be empty, key must be provided if key_id is not",
+				entity.ProblemDetail{Field: "signature", Reason: "must not be empty"},
+				entity.ProblemDetail{Field: "key", Reason: "must be provided if key_id is not"},
+			),
+		},
+		{
+			name:             "controller rejects the signature",
+			url:              "/sign/hmacsha512/verify",
+			body:             `{"key":"Alex","text":"23","signature":"xyz"}`,
+			requestBodyLimit: 1048576,
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.VerifyRequest{Algorithm: "hmacsha512", Key: "Alex", Text: "23", Signature: "xyz"},
+				err: fmt.Errorf("signature must be a non-empty hex string: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"signature must be a non-empty hex string: invalid argument",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, tt.url, bytes.NewReader([]byte(tt.body)))
+			signCtrlMock := mock_sign.NewMockController(ctrl)
+			if tt.mockSignCtrl != nil {
+				signCtrlMock.
+					EXPECT().
+					Verify(httpreq.Context(), tt.mockSignCtrl.req).
+					Return(tt.mockSignCtrl.res, tt.mockSignCtrl.err)
+			}
+			h := &handler{
+				logger:          zap.NewNop(),
+				usersCtrl:       mock_users.NewMockController(ctrl),
+				incrementalCtrl: mock_incremental.NewMockController(ctrl),
+				signCtrl:        signCtrlMock,
+				config: internalconfig.HandlerConfig{
+					RequestBodyLimit: tt.requestBodyLimit,
+				},
+			}
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.Verify).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_PublicKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockSignCtrl struct {
+		res *entity.JWKS
+		err error
+	}
+	tests := []struct {
+		name               string
+		mockSignCtrl       *mockSignCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "Happy path",
+			mockSignCtrl: &mockSignCtrl{
+				res: &entity.JWKS{Keys: []entity.JWK{
+					{KeyType: "OKP", KeyID: "webhooks.1", Algorithm: "EdDSA", Use: "sig", Curve: "Ed25519", X: "x"},
+				}},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"keys":[{"kty":"OKP","kid":"webhooks.1","alg":"EdDSA","use":"sig","crv":"Ed25519","x":"x"}]}`,
+		},
+		{
+			name: "controller fails",
+			mockSignCtrl: &mockSignCtrl{
+				err: fmt.Errorf("signing key webhooks version 1: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"signing key webhooks version 1: invalid argument",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			signCtrlMock := mock_sign.NewMockController(ctrl)
+			signCtrlMock.
+				EXPECT().
+				PublicKeys(httpreq.Context()).
+				Return(tt.mockSignCtrl.res, tt.mockSignCtrl.err)
+			h := &handler{
+				logger:          zap.NewNop(),
+				usersCtrl:       mock_users.NewMockController(ctrl),
+				incrementalCtrl: mock_incremental.NewMockController(ctrl),
+				signCtrl:        signCtrlMock,
+			}
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.PublicKeys).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
 		})
 	}
 }
@@ -343,7 +871,11 @@ func Test_handler_AddUser(t *testing.T) {
 			},
 			requestBodyLimit:   1,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "bad request, err: request body is too big\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
 		},
 		{
 			name: "body is not a valid json",
@@ -354,7 +886,11 @@ func Test_handler_AddUser(t *testing.T) {
 			},
 			requestBodyLimit:   1048576,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "bad request, err: failed to unmarshal: invalid character '_' looking for beginning of object key string\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of object key string",
+			),
 		},
 		{
 			name: "io.ReadAll fails",
@@ -366,22 +902,47 @@ func Test_handler_AddUser(t *testing.T) {
 			failureBody:        true,
 			requestBodyLimit:   1048576,
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "unable to read the body\n",
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"unable to read the body",
+			),
+		},
+		{
+			name: "request is not valid",
+			args: args{
+				method: "POST",
+				url:    "/postgres/users",
+				body:   []byte(`{"name":" ","age":-5}`),
+			},
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: name must not be empty, age must be between 0 and 150",
+				entity.ProblemDetail{Field: "name", Reason: "must not be empty"},
+				entity.ProblemDetail{Field: "age", Reason: "must be between 0 and 150"},
+			),
 		},
 		{
 			name: "controller fails",
 			args: args{
 				method: "POST",
-				url:    "/redis/incr",
-				body:   []byte(`{"key":"Alex","value":23}`),
+				url:    "/postgres/users",
+				body:   []byte(`{"name":"Alex","age":23}`),
 			},
 			requestBodyLimit: 1048576,
 			mockUserCtrl: &mockUserCtrl{
 				res: nil,
 				err: errors.New("some error"),
 			},
-			expectedStatusCode: http.StatusBadGateway,
-			expectedResponse:   "failed to process the request, err: some error\n",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
 		},
 	}
 	for _, tt := range tests {
@@ -418,3 +979,16 @@ func Test_handler_AddUser(t *testing.T) {
 		})
 	}
 }
+
+// problemBody returns the problem details written by the handler for the error
+func problemBody(status int, code string, message string, details ...entity.ProblemDetail) string {
+	data, _ := json.Marshal(&entity.Problem{
+		Type:    "about:blank",
+		Title:   http.StatusText(status),
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+	return string(data)
+}
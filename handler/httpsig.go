@@ -0,0 +1,20 @@
+package handler
+
+import "net/http"
+
+// HTTPSign is a POST endpoint that signs the HTTP message provided in the request body with RFC 9421
+// HTTP Message Signatures and returns the Signature-Input and Signature headers to add to the message
+// expected JSON request is defined by entity.HTTPSignRequest
+// expected JSON response is defined by entity.HTTPSignResponse
+func (h *handler) HTTPSign(w http.ResponseWriter, req *http.Request) {
+	endpoint(h, "HTTPSign", h.httpsigCtrl.Sign).ServeHTTP(w, req)
+}
+
+// HTTPVerify is a POST endpoint that checks the RFC 9421 signature of the HTTP message provided
+// in the request body. Signatures that don't match, have expired or cover a stale Content-Digest
+// are reported as invalid with the reason rather than as errors.
+// expected JSON request is defined by entity.HTTPVerifyRequest
+// expected JSON response is defined by entity.HTTPVerifyResponse
+func (h *handler) HTTPVerify(w http.ResponseWriter, req *http.Request) {
+	endpoint(h, "HTTPVerify", h.httpsigCtrl.Verify).ServeHTTP(w, req)
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	mock_httpsig "redis-postgres-service/mocks/controller/httpsig"
+	mock_incremental "redis-postgres-service/mocks/controller/incremental"
+	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_users "redis-postgres-service/mocks/controller/users"
+	"testing"
+)
+
+func newHTTPSigTestHandler(ctrl *gomock.Controller, httpsigCtrl *mock_httpsig.MockController, limit int64) *handler {
+	return &handler{
+		logger:          zap.NewNop(),
+		usersCtrl:       mock_users.NewMockController(ctrl),
+		incrementalCtrl: mock_incremental.NewMockController(ctrl),
+		signCtrl:        mock_sign.NewMockController(ctrl),
+		httpsigCtrl:     httpsigCtrl,
+		config: internalconfig.HandlerConfig{
+			RequestBodyLimit: limit,
+		},
+	}
+}
+
+func Test_handler_HTTPSign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockHTTPSigCtrl struct {
+		req *entity.HTTPSignRequest
+		res *entity.HTTPSignResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		body               string
+		requestBodyLimit   int64
+		mockHTTPSigCtrl    *mockHTTPSigCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "Happy path",
+			body: `{"method":"GET","url":"https://example.com/foo","algorithm":"hmac-sha256",` +
+				`"components":["@method","@authority"],"key_id":"partner-a"}`,
+			requestBodyLimit: 1048576,
+			mockHTTPSigCtrl: &mockHTTPSigCtrl{
+				req: &entity.HTTPSignRequest{
+					HTTPMessage: entity.HTTPMessage{Method: "GET", URL: "https://example.com/foo"},
+					Algorithm:   "hmac-sha256",
+					Components:  []string{"@method", "@authority"},
+					KeyID:       "partner-a",
+				},
+				res: &entity.HTTPSignResponse{
+					Headers: map[string]string{
+						"Signature-Input": `sig1=("@method" "@authority");created=1618884473;alg="hmac-sha256";keyid="partner-a"`,
+						"Signature":       "sig1=:LqgjxQ==:",
+					},
+					KeyID:      "partner-a",
+					KeyVersion: 2,
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"headers":{"Signature":"sig1=:LqgjxQ==:",` +
+				`"Signature-Input":"sig1=(\"@method\" \"@authority\");created=1618884473;alg=\"hmac-sha256\";keyid=\"partner-a\""},` +
+				`"key_id":"partner-a","key_version":2}`,
+		},
+		{
+			name:               "request body too big",
+			body:               `{"method":"GET","url":"https://example.com/foo"}`,
+			requestBodyLimit:   1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
+		},
+		{
+			name:               "body is not a valid json",
+			body:               `{"method":"GET",_`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of object key string",
+			),
+		},
+		{
+			name:             "controller rejects the request",
+			body:             `{"method":"GET","url":"https://example.com/foo","algorithm":"rsa-v1_5-sha256"}`,
+			requestBodyLimit: 1048576,
+			mockHTTPSigCtrl: &mockHTTPSigCtrl{
+				req: &entity.HTTPSignRequest{
+					HTTPMessage: entity.HTTPMessage{Method: "GET", URL: "https://example.com/foo"},
+					Algorithm:   "rsa-v1_5-sha256",
+				},
+				err: fmt.Errorf("unsupported algorithm rsa-v1_5-sha256: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"unsupported algorithm rsa-v1_5-sha256: invalid argument",
+			),
+		},
+		{
+			name:             "signing key is not found",
+			body:             `{"method":"GET","url":"https://example.com/foo","algorithm":"hmac-sha256","key_id":"partner-b"}`,
+			requestBodyLimit: 1048576,
+			mockHTTPSigCtrl: &mockHTTPSigCtrl{
+				req: &entity.HTTPSignRequest{
+					HTTPMessage: entity.HTTPMessage{Method: "GET", URL: "https://example.com/foo"},
+					Algorithm:   "hmac-sha256",
+					KeyID:       "partner-b",
+				},
+				err: fmt.Errorf("signing key partner-b: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: problemBody(
+				http.StatusNotFound,
+				problem.CodeNotFound,
+				"signing key partner-b: not found",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, "/httpsig/sign", bytes.NewReader([]byte(tt.body)))
+			httpsigCtrlMock := mock_httpsig.NewMockController(ctrl)
+			if tt.mockHTTPSigCtrl != nil {
+				httpsigCtrlMock.
+					EXPECT().
+					Sign(httpreq.Context(), tt.mockHTTPSigCtrl.req).
+					Return(tt.mockHTTPSigCtrl.res, tt.mockHTTPSigCtrl.err)
+			}
+			h := newHTTPSigTestHandler(ctrl, httpsigCtrlMock, tt.requestBodyLimit)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.HTTPSign).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_HTTPVerify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockHTTPSigCtrl struct {
+		req *entity.HTTPVerifyRequest
+		res *entity.HTTPVerifyResponse
+		err error
+	}
+	message := entity.HTTPMessage{
+		Method: "GET",
+		URL:    "https://example.com/foo",
+		Headers: map[string]string{
+			"Signature-Input": `sig1=("@method");alg="hmac-sha256";keyid="partner-a"`,
+			"Signature":       "sig1=:LqgjxQ==:",
+		},
+	}
+	body := `{"method":"GET","url":"https://example.com/foo","headers":{` +
+		`"Signature-Input":"sig1=(\"@method\");alg=\"hmac-sha256\";keyid=\"partner-a\"",` +
+		`"Signature":"sig1=:LqgjxQ==:"}}`
+	tests := []struct {
+		name               string
+		body               string
+		requestBodyLimit   int64
+		mockHTTPSigCtrl    *mockHTTPSigCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:             "Happy path",
+			body:             body,
+			requestBodyLimit: 1048576,
+			mockHTTPSigCtrl: &mockHTTPSigCtrl{
+				req: &entity.HTTPVerifyRequest{HTTPMessage: message},
+				res: &entity.HTTPVerifyResponse{
+					Valid:      true,
+					Label:      "sig1",
+					Algorithm:  "hmac-sha256",
+					Components: []string{"@method"},
+					KeyID:      "partner-a",
+					KeyVersion: 1,
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"valid":true,"label":"sig1","algorithm":"hmac-sha256","components":["@method"],` +
+				`"key_id":"partner-a","key_version":1}`,
+		},
+		{
+			name:             "Happy path, signature has expired",
+			body:             body,
+			requestBodyLimit: 1048576,
+			mockHTTPSigCtrl: &mockHTTPSigCtrl{
+				req: &entity.HTTPVerifyRequest{HTTPMessage: message},
+				res: &entity.HTTPVerifyResponse{
+					Label:      "sig1",
+					Algorithm:  "hmac-sha256",
+					Components: []string{"@method"},
+					Reason:     "signature has expired",
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"valid":false,"label":"sig1","algorithm":"hmac-sha256","components":["@method"],` +
+				`"reason":"signature has expired"}`,
+		},
+		{
+			name:               "request body too big",
+			body:               body,
+			requestBodyLimit:   1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
+		},
+		{
+			name:               "body is not a valid json",
+			body:               `{"method":"GET",_`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of object key string",
+			),
+		},
+		{
+			name:               "both key and key id",
+			body:               `{"method":"GET","url":"https://example.com/foo","key":"secret","key_id":"partner-a"}`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: key must not be provided with key_id",
+				entity.ProblemDetail{Field: "key", Reason: "must not be provided with key_id"},
+			),
+		},
+		{
+			name:               "negative max age",
+			body:               `{"method":"GET","url":"https://example.com/foo","max_age":-1}`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: max_age must not be negative",
+				entity.ProblemDetail{Field: "max_age", Reason: "must not be negative"},
+			),
+		},
+		{
+			name:             "label is not found",
+			body:             `{"method":"GET","url":"https://example.com/foo","label":"sig2"}`,
+			requestBodyLimit: 1048576,
+			mockHTTPSigCtrl: &mockHTTPSigCtrl{
+				req: &entity.HTTPVerifyRequest{
+					HTTPMessage: entity.HTTPMessage{Method: "GET", URL: "https://example.com/foo"},
+					Label:       "sig2",
+				},
+				err: fmt.Errorf("signature sig2: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "signature sig2: not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, "/httpsig/verify", bytes.NewReader([]byte(tt.body)))
+			httpsigCtrlMock := mock_httpsig.NewMockController(ctrl)
+			if tt.mockHTTPSigCtrl != nil {
+				httpsigCtrlMock.
+					EXPECT().
+					Verify(httpreq.Context(), tt.mockHTTPSigCtrl.req).
+					Return(tt.mockHTTPSigCtrl.res, tt.mockHTTPSigCtrl.err)
+			}
+			h := newHTTPSigTestHandler(ctrl, httpsigCtrlMock, tt.requestBodyLimit)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.HTTPVerify).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
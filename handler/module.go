@@ -1,7 +1,16 @@
 package handler
 
-import "go.uber.org/fx"
+import (
+	"go.uber.org/fx"
+	"redis-postgres-service/handler/rpc"
+	"redis-postgres-service/handler/validation"
+)
 
 var Module = fx.Options(
 	fx.Provide(New),
+	fx.Provide(validation.NewAuthenticator),
+	fx.Provide(validation.NewRateLimiter),
+	fx.Provide(validation.NewIdempotency),
+	fx.Provide(validation.NewInstrumenter),
+	fx.Provide(rpc.New),
 )
@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"redis-postgres-service/handler/problem"
+	"sort"
+	"strings"
+)
+
+// Version is the version of the OpenAPI specification the documents follow
+const Version = "3.0.3"
+
+// Document is the OpenAPI document built from the routes by Build
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	Tags       []map[string]string `json:"tags,omitempty"`
+}
+
+// Info is the metadata of the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps the lowercase http methods of the path to their operations
+type PathItem map[string]*Operation
+
+// Operation is the single http method of the path
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+// Parameter is the path, query or header parameter of the operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody maps the media types of the request body to their schemas
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is either the reference to the response of the components or the response of the operation
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of the body of the media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components are the schemas, the responses and the security schemes referenced by the operations
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is the way the callers are authenticated, e.g. by the API key in the header
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Content maps the media types of the body to the zero values of its types, e.g. entity.AddUserRequest{}.
+// Values of the Binary type are the raw bodies.
+type Content map[string]any
+
+// Param is the path, query or header parameter of the route, Type is the zero value of its type.
+// Path parameters are always required.
+type Param struct {
+	Name        string
+	In          string
+	Description string
+	Type        any
+	Required    bool
+}
+
+// Route describes the endpoint served on the Path template, e.g. /postgres/users/{id}, with the Method.
+// Handler is the name of the handler method serving the route, it is the operationId unless OperationID is set.
+// Callers are required to have the Scope, routes without the Scope are public. Response is returned with 200 OK,
+// errors are returned as the problem details.
+type Route struct {
+	Method      string
+	Path        string
+	Handler     string
+	OperationID string
+	Tag         string
+	Summary     string
+	Description string
+	Scope       string
+	Params      []Param
+	Request     Content
+	Response    Content
+}
+
+// Build returns the document of the routes, the request and the response types are added to the schemas
+// of the components by their names. problemType is the zero value of the problem details of the failed requests,
+// operations with the Scope require one of the security schemes.
+func Build(info Info, problemType any, schemes map[string]*SecurityScheme, routes []Route) *Document {
+	g := &generator{schemas: make(map[string]*Schema)}
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: g.schemas,
+			Responses: map[string]*Response{
+				"Problem": {
+					Description: "Problem details of the failed request, see RFC 7807",
+					Content:     map[string]*MediaType{"application/problem+json": {Schema: g.schema(problemType)}},
+				},
+			},
+			SecuritySchemes: schemes,
+		},
+	}
+	schemeNames := make([]string, 0, len(schemes))
+	for name := range schemes {
+		schemeNames = append(schemeNames, name)
+	}
+	sort.Strings(schemeNames)
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		operation := &Operation{
+			OperationID: route.OperationID,
+			Summary:     route.Summary,
+			Description: route.Description,
+			Responses: map[string]*Response{
+				"200":     {Description: "OK", Content: g.content(route.Response)},
+				"default": {Ref: "#/components/responses/Problem"},
+			},
+			Security: []map[string][]string{},
+		}
+		if operation.OperationID == "" {
+			operation.OperationID = route.Handler
+		}
+		if route.Tag != "" {
+			operation.Tags = []string{route.Tag}
+			if !tags[route.Tag] {
+				tags[route.Tag] = true
+				doc.Tags = append(doc.Tags, map[string]string{"name": route.Tag})
+			}
+		}
+		for _, param := range route.Params {
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:        param.Name,
+				In:          param.In,
+				Description: param.Description,
+				Required:    param.Required || param.In == "path",
+				Schema:      g.schema(param.Type),
+			})
+		}
+		if route.Request != nil {
+			operation.RequestBody = &RequestBody{Required: true, Content: g.content(route.Request)}
+		}
+		if route.Scope != "" {
+			for _, name := range schemeNames {
+				operation.Security = append(operation.Security, map[string][]string{name: {}})
+			}
+			scope := "Requires the `" + route.Scope + "` scope."
+			operation.Description = strings.TrimSpace(operation.Description + "\n\n" + scope)
+		}
+		item, ok := doc.Paths[route.Path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+	return doc
+}
+
+// content returns the schemas of the body by its media types
+func (g *generator) content(content Content) map[string]*MediaType {
+	if content == nil {
+		return nil
+	}
+	media := make(map[string]*MediaType, len(content))
+	for mediaType, v := range content {
+		media[mediaType] = &MediaType{Schema: g.schema(v)}
+	}
+	return media
+}
+
+// Handler serves the document as JSON, it is encoded once, so that the document must not be modified afterwards
+func Handler(doc *Document) http.Handler {
+	data, err := json.Marshal(doc)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			problem.Error(w, err.Error(), http.StatusInternalServerError) // unreachable, cause the document consists of JSON types
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testProblem struct {
+	Message string `json:"message"`
+}
+
+func TestBuild(t *testing.T) {
+	schemes := map[string]*SecurityScheme{
+		"bearer": {Type: "http", Scheme: "bearer"},
+		"apiKey": {Type: "apiKey", In: "header", Name: "X-Api-Key"},
+	}
+	doc := Build(Info{Title: "test", Version: "1.0.0"}, testProblem{}, schemes, []Route{
+		{
+			Method:      http.MethodPost,
+			Path:        "/items",
+			Handler:     "AddItem",
+			Tag:         "items",
+			Description: "Adds the item.",
+			Scope:       "items:write",
+			Request:     Content{"application/json": testItem{}},
+			Response:    Content{"application/json": testItem{}},
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/items/{id}",
+			Handler:     "UpdateItem",
+			OperationID: "PatchItem",
+			Tag:         "items",
+			Params: []Param{
+				{Name: "id", In: "path", Type: int64(0)},
+				{Name: "X-Version", In: "header", Type: ""},
+			},
+			Response: Content{"application/octet-stream": Binary{}},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/health",
+			Handler:  "Health",
+			Tag:      "health",
+			Response: Content{"application/json": map[string]string{}},
+		},
+	})
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Equal(t, Info{Title: "test", Version: "1.0.0"}, doc.Info)
+	assert.Equal(t, []map[string]string{{"name": "items"}, {"name": "health"}}, doc.Tags)
+	assert.Equal(t, schemes, doc.Components.SecuritySchemes)
+	assert.Contains(t, doc.Components.Schemas, "testItem")
+	assert.Contains(t, doc.Components.Schemas, "testProblem")
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/testProblem"},
+		doc.Components.Responses["Problem"].Content["application/problem+json"].Schema)
+
+	add := doc.Paths["/items"]["post"]
+	if add == nil {
+		t.Fatal("post /items is missing")
+	}
+	assert.Equal(t, "AddItem", add.OperationID)
+	assert.Equal(t, []string{"items"}, add.Tags)
+	assert.Equal(t, "Adds the item.\n\nRequires the `items:write` scope.", add.Description)
+	assert.Equal(t, []map[string][]string{{"apiKey": {}}, {"bearer": {}}}, add.Security)
+	assert.Equal(t, &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/testItem"}}},
+	}, add.RequestBody)
+	assert.Equal(t, &Response{Ref: "#/components/responses/Problem"}, add.Responses["default"])
+
+	patch := doc.Paths["/items/{id}"]["patch"]
+	if patch == nil {
+		t.Fatal("patch /items/{id} is missing")
+	}
+	assert.Equal(t, "PatchItem", patch.OperationID)
+	assert.Equal(t, []*Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}},
+		{Name: "X-Version", In: "header", Schema: &Schema{Type: "string"}},
+	}, patch.Parameters)
+	assert.Nil(t, patch.RequestBody)
+	assert.Equal(t, &Schema{Type: "string", Format: "binary"},
+		patch.Responses["200"].Content["application/octet-stream"].Schema)
+
+	health := doc.Paths["/health"]["get"]
+	if health == nil {
+		t.Fatal("get /health is missing")
+	}
+	assert.Empty(t, health.Description)
+	assert.Equal(t, []map[string][]string{}, health.Security)
+}
+
+func TestHandler(t *testing.T) {
+	doc := Build(Info{Title: "test", Version: "1.0.0"}, testProblem{}, nil, []Route{
+		{Method: http.MethodGet, Path: "/health", Handler: "Health", Response: Content{"application/json": ""}},
+	})
+	w := httptest.NewRecorder()
+	Handler(doc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var got map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Version, got["openapi"])
+	assert.Contains(t, got["paths"], "/health")
+}
+
+func TestUI(t *testing.T) {
+	ui := http.StripPrefix("/docs", UI("test <api>", "/openapi.json"))
+	tests := []struct {
+		name        string
+		path        string
+		code        int
+		contentType string
+		contains    []string
+	}{
+		{
+			name:        "page",
+			path:        "/docs/",
+			code:        http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			contains:    []string{"<title>test &lt;api&gt;</title>", `"/openapi.json"`, "swagger-ui-bundle.js"},
+		},
+		{
+			name:        "index",
+			path:        "/docs/index.html",
+			code:        http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			contains:    []string{`"/openapi.json"`},
+		},
+		{
+			name: "asset",
+			path: "/docs/swagger-ui.css",
+			code: http.StatusOK,
+		},
+		{
+			name: "missing asset",
+			path: "/docs/missing.js",
+			code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ui.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.code, w.Code)
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			}
+			body, _ := io.ReadAll(w.Body)
+			for _, s := range tt.contains {
+				assert.True(t, strings.Contains(string(body), s), "%q is missing in the page", s)
+			}
+		})
+	}
+}
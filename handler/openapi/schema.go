@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Schema is the JSON schema subset of the OpenAPI 3.0, Ref references the schema of the components
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Binary is the raw body of the request or the response, e.g. the streamed message to sign
+type Binary []byte
+
+var (
+	_binaryType = reflect.TypeOf(Binary(nil))
+	_bytesType  = reflect.TypeOf([]byte(nil))
+	_numberType = reflect.TypeOf(json.Number(""))
+)
+
+// generator collects the schemas of the named structs, so that they are referenced rather than inlined
+type generator struct {
+	schemas map[string]*Schema
+}
+
+// schema returns the schema of the value the way encoding/json encodes it
+func (g *generator) schema(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return g.typeSchema(reflect.TypeOf(v))
+}
+
+func (g *generator) typeSchema(t reflect.Type) *Schema {
+	switch t {
+	case _binaryType:
+		return &Schema{Type: "string", Format: "binary"}
+	case _bytesType:
+		return &Schema{Type: "string", Format: "byte"}
+	case _numberType:
+		// json.Number fields accept both the numbers and the strings containing them
+		return &Schema{OneOf: []*Schema{{Type: "number"}, {Type: "string"}}}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.typeSchema(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = &Schema{} // placeholder for the recursive types
+			g.schemas[t.Name()] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{} // any value, e.g. the interfaces
+	}
+}
+
+// structSchema returns the object schema of the struct fields by their json tags. Fields of the embedded structs
+// are promoted, fields without omitempty are required.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+		embedded := field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct
+		// encoding/json promotes the exported fields of the embedded structs even if their types are unexported
+		if (!field.IsExported() && !embedded) || tag == "-" {
+			continue
+		}
+		if embedded {
+			promoted := g.structSchema(field.Type)
+			for property, s := range promoted.Properties {
+				schema.Properties[property] = s
+			}
+			schema.Required = append(schema.Required, promoted.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.typeSchema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
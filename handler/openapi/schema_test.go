@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+type testEmbedded struct {
+	ID int64 `json:"id"`
+}
+
+type testRequest struct {
+	testEmbedded
+	Title    string            `json:"title"`
+	Count    int32             `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio,omitempty"`
+	Enabled  *bool             `json:"enabled"`
+	Value    json.Number       `json:"value,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Items    []testItem        `json:"items,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Extra    any               `json:"extra,omitempty"`
+	Next     *testRequest      `json:"next,omitempty"`
+	Untagged string
+	Skipped  string `json:"-"`
+	private  string
+}
+
+func Test_generator_schema(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       any
+		want    *Schema
+		schemas map[string]*Schema
+	}{
+		{
+			name:    "nil",
+			v:       nil,
+			want:    &Schema{},
+			schemas: map[string]*Schema{},
+		},
+		{
+			name:    "binary",
+			v:       Binary{},
+			want:    &Schema{Type: "string", Format: "binary"},
+			schemas: map[string]*Schema{},
+		},
+		{
+			name:    "int",
+			v:       0,
+			want:    &Schema{Type: "integer", Format: "int64"},
+			schemas: map[string]*Schema{},
+		},
+		{
+			name:    "slice of structs",
+			v:       []testItem{},
+			want:    &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/testItem"}},
+			schemas: map[string]*Schema{"testItem": {Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}, Required: []string{"name"}}},
+		},
+		{
+			name: "struct",
+			v:    testRequest{},
+			want: &Schema{Ref: "#/components/schemas/testRequest"},
+			schemas: map[string]*Schema{
+				"testItem": {Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}, Required: []string{"name"}},
+				"testRequest": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"id":       {Type: "integer", Format: "int64"},
+						"title":    {Type: "string"},
+						"count":    {Type: "integer", Format: "int32"},
+						"ratio":    {Type: "number", Format: "double"},
+						"enabled":  {Type: "boolean"},
+						"value":    {OneOf: []*Schema{{Type: "number"}, {Type: "string"}}},
+						"data":     {Type: "string", Format: "byte"},
+						"items":    {Type: "array", Items: &Schema{Ref: "#/components/schemas/testItem"}},
+						"labels":   {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+						"extra":    {},
+						"next":     {Ref: "#/components/schemas/testRequest"},
+						"Untagged": {Type: "string"},
+					},
+					Required: []string{"id", "title", "Untagged"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &generator{schemas: make(map[string]*Schema)}
+			assert.Equal(t, tt.want, g.schema(tt.v))
+			assert.Equal(t, tt.schemas, g.schemas)
+		})
+	}
+}
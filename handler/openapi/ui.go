@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	swaggerfiles "github.com/swaggo/files/v2"
+	"html/template"
+	"net/http"
+)
+
+//go:embed ui.html
+var _uiPage string
+
+var _uiTemplate = template.Must(template.New("ui").Parse(_uiPage))
+
+// UI serves the Swagger UI page of the document served at specURL, e.g. /openapi.json, with the bundled
+// Swagger UI assets. The page is expected to be mounted with http.StripPrefix, e.g. under /docs/.
+func UI(title, specURL string) http.Handler {
+	var page bytes.Buffer
+	// unreachable errors, cause the template is valid and the buffer never fails
+	_ = _uiTemplate.Execute(&page, struct{ Title, URL string }{Title: title, URL: specURL})
+	assets := http.FileServer(http.FS(swaggerfiles.FS))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "", "/", "/index.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write(page.Bytes())
+		default:
+			assets.ServeHTTP(w, r)
+		}
+	})
+}
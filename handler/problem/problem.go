@@ -0,0 +1,95 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"redis-postgres-service/entity"
+)
+
+// ContentType is the media type of the problem details
+const ContentType = "application/problem+json"
+
+// RequestIDHeader carries the id of the request, it is set on the responses by the validation.RequestID middleware
+const RequestIDHeader = "X-Request-Id"
+
+// _blankType is the problem type of the problems that are described by the http status only
+const _blankType = "about:blank"
+
+// Stable error codes of the problems
+const (
+	CodeInvalidArgument  = "invalid_argument"
+	CodeUnauthenticated  = "unauthenticated"
+	CodePermissionDenied = "permission_denied"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeNotAcceptable    = "not_acceptable"
+	CodeConflict         = "conflict"
+	CodeUnsupportedMedia = "unsupported_media_type"
+	CodeUnprocessable    = "unprocessable"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal"
+	CodeUnavailable      = "unavailable"
+)
+
+// _codes maps the http statuses into the error codes
+var _codes = map[int]string{
+	http.StatusBadRequest:           CodeInvalidArgument,
+	http.StatusUnauthorized:         CodeUnauthenticated,
+	http.StatusForbidden:            CodePermissionDenied,
+	http.StatusNotFound:             CodeNotFound,
+	http.StatusMethodNotAllowed:     CodeMethodNotAllowed,
+	http.StatusNotAcceptable:        CodeNotAcceptable,
+	http.StatusConflict:             CodeConflict,
+	http.StatusUnsupportedMediaType: CodeUnsupportedMedia,
+	http.StatusUnprocessableEntity:  CodeUnprocessable,
+	http.StatusTooManyRequests:      CodeRateLimited,
+	http.StatusInternalServerError:  CodeInternal,
+	http.StatusServiceUnavailable:   CodeUnavailable,
+}
+
+// _messages replace the messages of the server errors, so that the internal errors don't leak to the clients
+var _messages = map[int]string{
+	http.StatusInternalServerError: entity.InternalErrorMessage,
+	http.StatusServiceUnavailable:  entity.UnavailableMessage,
+}
+
+// Error writes the problem with the code of the http status, it is a drop-in replacement of http.Error.
+// Messages of the server errors are replaced by the generic ones, so the original message is expected to be logged.
+func Error(w http.ResponseWriter, message string, status int, details ...entity.ProblemDetail) {
+	code, ok := _codes[status]
+	if !ok {
+		code = CodeInternal
+	}
+	if status >= http.StatusInternalServerError {
+		message, ok = _messages[status]
+		if !ok {
+			message = _messages[http.StatusInternalServerError]
+		}
+	}
+	Write(w, &entity.Problem{
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+}
+
+// Write writes the problem as the response, the missing type, title and request id are filled in.
+// The request id is taken from the response header set by the validation.RequestID middleware.
+func Write(w http.ResponseWriter, problem *entity.Problem) {
+	if problem.Type == "" {
+		problem.Type = _blankType
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = w.Header().Get(RequestIDHeader)
+	}
+	data, _ := json.Marshal(problem) // problem can always be represented as json
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write(data)
+}
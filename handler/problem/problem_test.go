@@ -0,0 +1,71 @@
+package problem
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"redis-postgres-service/entity"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		status    int
+		details   []entity.ProblemDetail
+		requestID string
+		want      string
+	}{
+		{
+			name:    "Happy path",
+			message: "key in the path must not be empty",
+			status:  http.StatusBadRequest,
+			want: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_argument",` +
+				`"message":"key in the path must not be empty"}`,
+		},
+		{
+			name:      "Request id and details are included",
+			message:   "request is invalid",
+			status:    http.StatusBadRequest,
+			details:   []entity.ProblemDetail{{Field: "age", Reason: "must not be negative"}},
+			requestID: "some-id",
+			want: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_argument",` +
+				`"message":"request is invalid","details":[{"field":"age","reason":"must not be negative"}],` +
+				`"request_id":"some-id"}`,
+		},
+		{
+			name:    "Message of the internal error is hidden",
+			message: "dial tcp 10.0.0.1:5432: connection refused",
+			status:  http.StatusInternalServerError,
+			want: `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal",` +
+				`"message":"internal error, see the logs for the request id"}`,
+		},
+		{
+			name:    "Message of the downstream unavailability is hidden",
+			message: "dial tcp 10.0.0.1:6379: connection refused",
+			status:  http.StatusServiceUnavailable,
+			want: `{"type":"about:blank","title":"Service Unavailable","status":503,"code":"unavailable",` +
+				`"message":"downstream service is unavailable, retry later"}`,
+		},
+		{
+			name:    "Unknown server error is internal",
+			message: "upstream failed",
+			status:  http.StatusBadGateway,
+			want: `{"type":"about:blank","title":"Bad Gateway","status":502,"code":"internal",` +
+				`"message":"internal error, see the logs for the request id"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if tt.requestID != "" {
+				w.Header().Set(RequestIDHeader, tt.requestID)
+			}
+			Error(w, tt.message, tt.status, tt.details...)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+}
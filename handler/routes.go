@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"net/http"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/openapi"
+	"redis-postgres-service/handler/validation"
+	mapper "redis-postgres-service/mapper/common"
+)
+
+// Paths of the OpenAPI document and the Swagger UI page
+const (
+	OpenAPIPath = "/openapi.json"
+	DocsPath    = "/docs/"
+)
+
+const _title = "redis-postgres-service"
+
+// Tags of the routes in the OpenAPI document
+const (
+	_tagCounters   = "counters"
+	_tagUsers      = "users"
+	_tagSignatures = "signatures"
+	_tagTokens     = "tokens"
+)
+
+// codecContent returns the content of the body encoded by every codec of the content negotiation
+func codecContent(v any) openapi.Content {
+	return openapi.Content{
+		mapper.ContentTypeJSON:     v,
+		mapper.ContentTypeMsgPack:  v,
+		mapper.ContentTypeCBOR:     v,
+		mapper.ContentTypeProtobuf: v,
+	}
+}
+
+// jsonContent returns the content of the JSON only body
+func jsonContent(v any) openapi.Content {
+	return openapi.Content{mapper.ContentTypeJSON: v}
+}
+
+// signContent returns the content of the signature response, signatures with the raw encoding are the body
+func signContent(codecs openapi.Content) openapi.Content {
+	codecs["application/octet-stream"] = openapi.Binary{}
+	return codecs
+}
+
+var (
+	_keyParam       = openapi.Param{Name: "key", In: "path", Type: "", Description: "url-encoded key of the counter"}
+	_idParam        = openapi.Param{Name: "id", In: "path", Type: int64(0), Description: "id of the user"}
+	_algorithmParam = openapi.Param{Name: "algorithm", In: "path", Type: "", Description: "name of the algorithm, e.g. hmacsha512"}
+)
+
+// Routes returns the routes of the Handler endpoints, Handler of the route is the name of the Handler method
+// serving it. The routes are described here rather than derived from the handlers, so that app test checks
+// that every route is served by its Handler method with its scope.
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:   http.MethodPost,
+			Path:     "/redis/incr",
+			Handler:  "Incremental",
+			Tag:      _tagCounters,
+			Summary:  "Increment the counter by the value",
+			Scope:    validation.ScopeCountersWrite,
+			Request:  codecContent(entity.IncrementRequest{}),
+			Response: codecContent(entity.IncrementResponse{}),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/redis/incr/batch",
+			Handler: "IncrementalBatch",
+			Tag:     _tagCounters,
+			Summary: "Increment several counters in a single round trip",
+			Scope:   validation.ScopeCountersWrite,
+			Params: []openapi.Param{
+				{Name: "atomic", In: "query", Type: false, Description: "apply the increments in a transaction"},
+			},
+			Request:  codecContent(entity.BatchIncrementRequest{}),
+			Response: codecContent(entity.BatchIncrementResponse{}),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/redis/incr/{key}",
+			Handler:  "GetCounter",
+			Tag:      _tagCounters,
+			Summary:  "Read the counter",
+			Scope:    validation.ScopeCountersRead,
+			Params:   []openapi.Param{_keyParam},
+			Response: jsonContent(entity.IncrementResponse{}),
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/redis/incr/{key}",
+			Handler:  "ResetCounter",
+			Tag:      _tagCounters,
+			Summary:  "Reset the counter",
+			Scope:    validation.ScopeCountersWrite,
+			Params:   []openapi.Param{_keyParam},
+			Response: jsonContent(entity.IncrementResponse{}),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/postgres/users",
+			Handler:  "AddUser",
+			Tag:      _tagUsers,
+			Summary:  "Add the user",
+			Scope:    validation.ScopeUsersWrite,
+			Request:  codecContent(entity.AddUserRequest{}),
+			Response: codecContent(entity.AddUserResponse{}),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/postgres/users",
+			Handler: "ListUsers",
+			Tag:     _tagUsers,
+			Summary: "List the page of the users ordered by id",
+			Scope:   validation.ScopeUsersRead,
+			Params: []openapi.Param{
+				{Name: "limit", In: "query", Type: 0, Description: "size of the page, 50 by default, 1000 at most"},
+				{Name: "offset", In: "query", Type: 0, Description: "number of the users to skip"},
+			},
+			Response: jsonContent(entity.ListUsersResponse{}),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/postgres/users/bulk",
+			Handler:     "BulkAddUsers",
+			Tag:         _tagUsers,
+			Summary:     "Add many users at once",
+			Description: "The body is either the array of the users or, for application/x-ndjson, one user per line.",
+			Scope:       validation.ScopeUsersWrite,
+			Params: []openapi.Param{
+				{Name: "atomic", In: "query", Type: false, Description: "add either all the users or none of them"},
+			},
+			Request: openapi.Content{
+				mapper.ContentTypeJSON:     []entity.AddUserRequest{},
+				mapper.ContentTypeMsgPack:  []entity.AddUserRequest{},
+				mapper.ContentTypeCBOR:     []entity.AddUserRequest{},
+				mapper.ContentTypeProtobuf: []entity.AddUserRequest{},
+				_ndjsonContentType:         entity.AddUserRequest{},
+			},
+			Response: codecContent(entity.BulkAddUsersResponse{}),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/postgres/users/{id}",
+			Handler:  "GetUser",
+			Tag:      _tagUsers,
+			Summary:  "Read the user",
+			Scope:    validation.ScopeUsersRead,
+			Params:   []openapi.Param{_idParam},
+			Response: jsonContent(entity.User{}),
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/postgres/users/{id}",
+			Handler:  "UpdateUser",
+			Tag:      _tagUsers,
+			Summary:  "Replace the user, all the fields are required",
+			Scope:    validation.ScopeUsersWrite,
+			Params:   []openapi.Param{_idParam},
+			Request:  codecContent(entity.UpdateUserRequest{}),
+			Response: codecContent(entity.User{}),
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/postgres/users/{id}",
+			Handler:     "UpdateUser",
+			OperationID: "PatchUser",
+			Tag:         _tagUsers,
+			Summary:     "Update the provided fields of the user",
+			Scope:       validation.ScopeUsersWrite,
+			Params:      []openapi.Param{_idParam},
+			Request:     codecContent(entity.UpdateUserRequest{}),
+			Response:    codecContent(entity.User{}),
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/postgres/users/{id}",
+			Handler:  "DeleteUser",
+			Tag:      _tagUsers,
+			Summary:  "Delete the user and return it",
+			Scope:    validation.ScopeUsersWrite,
+			Params:   []openapi.Param{_idParam},
+			Response: jsonContent(entity.User{}),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/sign/{algorithm}",
+			Handler:  "Signature",
+			Tag:      _tagSignatures,
+			Summary:  "Sign the text",
+			Scope:    validation.ScopeSign,
+			Params:   []openapi.Param{_algorithmParam},
+			Request:  codecContent(entity.SignRequest{}),
+			Response: signContent(codecContent(entity.SignResponse{})),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/sign/{algorithm}/stream",
+			Handler: "SignatureStream",
+			Tag:     _tagSignatures,
+			Summary: "Sign the raw body of any size",
+			Scope:   validation.ScopeSign,
+			Params: []openapi.Param{
+				_algorithmParam,
+				{Name: SignatureKeyHeader, In: "header", Type: "", Description: "raw key"},
+				{Name: SignatureKeyIDHeader, In: "header", Type: "", Description: "id of the server-side key"},
+				{Name: SignatureKeyVersionHeader, In: "header", Type: 0, Description: "version of the server-side key"},
+				{Name: SignatureEncodingHeader, In: "header", Type: "", Description: "encoding of the signature, hex by default"},
+			},
+			Request:  openapi.Content{"application/octet-stream": openapi.Binary{}},
+			Response: signContent(jsonContent(entity.SignResponse{})),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/sign/{algorithm}/verify",
+			Handler:  "Verify",
+			Tag:      _tagSignatures,
+			Summary:  "Check the signature of the text",
+			Scope:    validation.ScopeSign,
+			Params:   []openapi.Param{_algorithmParam},
+			Request:  codecContent(entity.VerifyRequest{}),
+			Response: codecContent(entity.VerifyResponse{}),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/.well-known/jwks.json",
+			Handler:  "PublicKeys",
+			Tag:      _tagSignatures,
+			Summary:  "Publish the public keys of the server-side signing keys",
+			Response: jsonContent(entity.JWKS{}),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/httpsig/sign",
+			Handler:  "HTTPSign",
+			Tag:      _tagSignatures,
+			Summary:  "Sign the HTTP message with RFC 9421 HTTP Message Signatures",
+			Scope:    validation.ScopeSign,
+			Request:  codecContent(entity.HTTPSignRequest{}),
+			Response: codecContent(entity.HTTPSignResponse{}),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/httpsig/verify",
+			Handler:  "HTTPVerify",
+			Tag:      _tagSignatures,
+			Summary:  "Check the RFC 9421 signature of the HTTP message",
+			Scope:    validation.ScopeSign,
+			Request:  codecContent(entity.HTTPVerifyRequest{}),
+			Response: codecContent(entity.HTTPVerifyResponse{}),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/tokens/issue",
+			Handler:  "IssueToken",
+			Tag:      _tagTokens,
+			Summary:  "Issue the JWT signed by the server-side key",
+			Scope:    validation.ScopeTokensIssue,
+			Request:  codecContent(entity.IssueTokenRequest{}),
+			Response: codecContent(entity.IssueTokenResponse{}),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/tokens/verify",
+			Handler:  "VerifyToken",
+			Tag:      _tagTokens,
+			Summary:  "Validate the JWT",
+			Scope:    validation.ScopeTokensVerify,
+			Request:  codecContent(entity.VerifyTokenRequest{}),
+			Response: codecContent(entity.VerifyTokenResponse{}),
+		},
+	}
+}
+
+// OpenAPI returns the OpenAPI document of the Routes
+func OpenAPI() *openapi.Document {
+	return openapi.Build(
+		openapi.Info{
+			Title:   _title,
+			Version: "1.0.0",
+			Description: "Counters in Redis, users in Postgres, signatures and tokens. Bodies are encoded " +
+				"by the codec of the Content-Type and the responses by the codec negotiated by the Accept header.",
+		},
+		entity.Problem{},
+		map[string]*openapi.SecurityScheme{
+			"apiKey": {Type: "apiKey", In: "header", Name: validation.APIKeyHeader},
+			"bearer": {
+				Type:         "http",
+				Scheme:       "bearer",
+				BearerFormat: "JWT",
+				Description:  "Token issued by /tokens/issue with the space separated scopes in the `scope` claim",
+			},
+			"hmac": {
+				Type: "apiKey",
+				In:   "header",
+				Name: "Authorization",
+				Description: "`HMAC-SHA256 client=<client id>,timestamp=<unix seconds>,signature=<hex>` signature " +
+					"of the request made with the server-side key of the client",
+			},
+		},
+		Routes(),
+	)
+}
+
+// Docs returns the Swagger UI page of the OpenAPI document served at OpenAPIPath
+func Docs() http.Handler {
+	return http.StripPrefix(DocsPath[:len(DocsPath)-1], openapi.UI(_title, OpenAPIPath))
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const _openAPIFile = "../api/openapi.json"
+
+var _update = flag.Bool("update", false, "rewrite api/openapi.json with the generated OpenAPI document")
+
+// TestOpenAPI fails when the committed document drifts from the routes, run
+// go test ./handler -run TestOpenAPI -update to regenerate it
+func TestOpenAPI(t *testing.T) {
+	data, err := json.MarshalIndent(OpenAPI(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+	if *_update {
+		if err := os.WriteFile(_openAPIFile, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	committed, err := os.ReadFile(_openAPIFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(committed, data) {
+		t.Errorf("%s is outdated, run go test ./handler -run TestOpenAPI -update", _openAPIFile)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	methods := make(map[string]bool)
+	handlerType := reflect.TypeOf((*Handler)(nil)).Elem()
+	for i := 0; i < handlerType.NumMethod(); i++ {
+		methods[handlerType.Method(i).Name] = false
+	}
+	operations := make(map[string]bool)
+	for _, route := range Routes() {
+		if _, ok := methods[route.Handler]; !ok {
+			t.Errorf("%s %s is served by unknown Handler method %q", route.Method, route.Path, route.Handler)
+		}
+		methods[route.Handler] = true
+		operationID := route.OperationID
+		if operationID == "" {
+			operationID = route.Handler
+		}
+		assert.False(t, operations[operationID], "duplicate operationId %s", operationID)
+		operations[operationID] = true
+		assert.True(t, strings.HasPrefix(route.Path, "/"), route.Path)
+		assert.NotEmpty(t, route.Response, "%s %s has no response", route.Method, route.Path)
+	}
+	for method, documented := range methods {
+		assert.True(t, documented, "Handler method %s is missing in Routes", method)
+	}
+}
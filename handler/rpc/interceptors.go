@@ -0,0 +1,373 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"net"
+	apiv1 "redis-postgres-service/api/v1"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/controller/idempotency"
+	"redis-postgres-service/controller/ratelimit"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/validation"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata keys of the requests and the responses, they are the lowercase http headers of the same meaning
+const (
+	_requestIDKey          = "x-request-id"
+	_apiKeyKey             = "x-api-key"
+	_authorizationKey      = "authorization"
+	_idempotencyKeyKey     = "idempotency-key"
+	_idempotentReplayedKey = "idempotent-replayed"
+	_rateLimitLimitKey     = "x-ratelimit-limit"
+	_rateLimitRemainingKey = "x-ratelimit-remaining"
+	_rateLimitResetKey     = "x-ratelimit-reset"
+	_retryAfterKey         = "retry-after"
+)
+
+// _messageTypeKey is the header of the stored responses of the idempotent calls holding the type of the response
+const _messageTypeKey = "grpc-message-type"
+
+// _maxIdempotencyKeyLength limits the length of the idempotency keys stored in Redis the same way as over http
+const _maxIdempotencyKeyLength = 255
+
+// _scopes are the scopes the callers must have to call the methods of the service
+var _scopes = map[string]string{
+	apiv1.RedisPostgresService_Increment_FullMethodName: validation.ScopeCountersWrite,
+	apiv1.RedisPostgresService_Sign_FullMethodName:      validation.ScopeSign,
+	apiv1.RedisPostgresService_AddUser_FullMethodName:   validation.ScopeUsersWrite,
+}
+
+// _routes are the rate limit routes of the methods of the service, they are the routes of the respective http
+// endpoints, so that the calls count towards the same limits. Methods without the route are limited by their names.
+var _routes = map[string]string{
+	apiv1.RedisPostgresService_Increment_FullMethodName: "/redis/incr",
+	apiv1.RedisPostgresService_Sign_FullMethodName:      "/sign/",
+	apiv1.RedisPostgresService_AddUser_FullMethodName:   "/postgres/users",
+}
+
+// unaryLogging is an interceptor that assigns the request id the same way as the validation.RequestID
+// middleware, returns it in the x-request-id header and logs the outcome of the call
+func unaryLogging(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, id := requestID(ctx)
+		start := time.Now()
+		response, err := handler(ctx, req)
+		logCall(logger, info.FullMethod, id, start, err)
+		return response, err
+	}
+}
+
+// streamLogging is the unaryLogging interceptor of the streaming methods, e.g. the health checks watching
+func streamLogging(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := requestID(ss.Context())
+		start := time.Now()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		logCall(logger, info.FullMethod, id, start, err)
+		return err
+	}
+}
+
+// unaryAuth is an interceptor that authenticates the callers of the service methods with their scopes by the API key
+// or the bearer token in the metadata. Health checks and reflection are not authenticated.
+func unaryAuth(logger *zap.Logger, auth validation.Authenticator, rateLimitCtrl ratelimit.Controller) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, logger, auth, rateLimitCtrl, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuth is the unaryAuth interceptor of the streaming methods
+func streamAuth(logger *zap.Logger, auth validation.Authenticator, rateLimitCtrl ratelimit.Controller) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), logger, auth, rateLimitCtrl, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// unaryRateLimit is an interceptor that limits the rate of the calls of every caller to the methods of the service
+// the same way as the validation.RateLimiter, so it must be chained after unaryAuth. Calls over the limit are rejected
+// with ResourceExhausted, the limiter fails open if the counters can't be reached.
+func unaryRateLimit(logger *zap.Logger, rateLimitCtrl ratelimit.Controller) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !serviceMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		route, ok := _routes[info.FullMethod]
+		if !ok {
+			route = info.FullMethod
+		}
+		client := callerID(ctx)
+		res, err := rateLimitCtrl.Allow(ctx, &entity.RateLimitRequest{Route: route, Client: client})
+		if err != nil {
+			interceptorLogger(ctx, logger, "RateLimit").Errorf(entity.FailedToProcessTheRequest, err)
+			return handler(ctx, req)
+		}
+		if res.Limit == 0 {
+			return handler(ctx, req)
+		}
+		header, reset := rateLimitHeader(res)
+		if !res.Allowed {
+			header.Set(_retryAfterKey, reset)
+			_ = grpc.SetHeader(ctx, header) // fails only if the headers were sent already
+			return nil, status.Errorf(codes.ResourceExhausted, "%s exceeded %d requests to %s", client, res.Limit, route)
+		}
+		_ = grpc.SetHeader(ctx, header)
+		return handler(ctx, req)
+	}
+}
+
+// unaryIdempotency is an interceptor that processes the calls with the idempotency-key metadata at most once the same
+// way as the validation.Idempotency middleware, so it must be chained after unaryAuth. The first response for the key
+// is stored and replayed on the retries with the idempotent-replayed header, the retries made while the first call
+// is in flight are rejected with Aborted and the reuse of the key for another method or request with InvalidArgument.
+// Failed calls and the responses bigger than the configured limit are not stored, so that the call can be retried.
+func unaryIdempotency(
+	logger *zap.Logger,
+	idempotencyCtrl idempotency.Controller,
+	cfg internalconfig.IdempotencyConfig,
+) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		key := first(md, _idempotencyKeyKey)
+		message, ok := req.(proto.Message)
+		if !cfg.Enabled || key == "" || !ok || !serviceMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if len(key) > _maxIdempotencyKeyLength {
+			return nil, status.Errorf(
+				codes.InvalidArgument, "%s must not be longer than %d characters", _idempotencyKeyKey, _maxIdempotencyKeyLength,
+			)
+		}
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return nil, statusError(err) // unreachable, cause the request was unmarshalled
+		}
+		sum := sha256.Sum256(body)
+		request := &entity.IdempotencyRequest{
+			Client:      callerID(ctx),
+			Key:         key,
+			Fingerprint: "gRPC " + info.FullMethod + " " + hex.EncodeToString(sum[:]),
+		}
+		res, err := idempotencyCtrl.Begin(ctx, request)
+		if err != nil {
+			return nil, statusError(err)
+		}
+		if res.Replay != nil {
+			return replayResponse(ctx, res.Replay)
+		}
+		completed := false
+		defer func() {
+			// the key is released even if the handler panics, the call context may be already canceled
+			if !completed {
+				if err := idempotencyCtrl.Release(context.Background(), request); err != nil {
+					interceptorLogger(ctx, logger, "Idempotency").Errorf(entity.FailedToProcessTheRequest, err)
+				}
+			}
+		}()
+		response, err := handler(ctx, req)
+		if err != nil {
+			return response, err
+		}
+		stored, ok := storedResponse(response, cfg.MaxResponseSize)
+		if !ok {
+			return response, nil
+		}
+		if err = idempotencyCtrl.Complete(context.Background(), request, stored); err != nil {
+			interceptorLogger(ctx, logger, "Idempotency").Errorf(entity.FailedToProcessTheResponse, err)
+			return response, nil
+		}
+		completed = true
+		return response, nil
+	}
+}
+
+// storedResponse returns the response of the call to store with its type, responses bigger than the limit are not stored
+func storedResponse(response any, limit int64) (*entity.StoredResponse, bool) {
+	message, ok := response.(proto.Message)
+	if !ok {
+		return nil, false
+	}
+	body, err := proto.Marshal(message)
+	if err != nil || int64(len(body)) > limit {
+		return nil, false
+	}
+	return &entity.StoredResponse{
+		Status: int(codes.OK),
+		Header: map[string][]string{_messageTypeKey: {string(message.ProtoReflect().Descriptor().FullName())}},
+		Body:   body,
+	}, true
+}
+
+// replayResponse returns the stored response of the call and marks it by the idempotent-replayed header
+func replayResponse(ctx context.Context, stored *entity.StoredResponse) (any, error) {
+	var name string
+	if types := stored.Header[_messageTypeKey]; len(types) > 0 {
+		name = types[0]
+	}
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, statusError(fmt.Errorf("stored response is not a response of the call: %s", err))
+	}
+	message := messageType.New().Interface()
+	if err = proto.Unmarshal(stored.Body, message); err != nil {
+		return nil, statusError(fmt.Errorf("stored response is malformed: %s", err))
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(_idempotentReplayedKey, "true")) // fails only if the headers were sent already
+	return message, nil
+}
+
+// authorize returns the context with the caller of the method. Methods of the service without the scope
+// are denied, so that the new methods are not exposed unauthenticated by mistake. The failed attempts are limited
+// on validation.AuthRoute by the peer address the same way as over http, callers over the limit are rejected
+// with ResourceExhausted before the authentication. The limiter fails open if the counters can't be reached.
+func authorize(
+	ctx context.Context,
+	logger *zap.Logger,
+	auth validation.Authenticator,
+	rateLimitCtrl ratelimit.Controller,
+	method string,
+) (context.Context, error) {
+	if !serviceMethod(method) {
+		return ctx, nil
+	}
+	scope, ok := _scopes[method]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "method %s doesn't have a scope", method)
+	}
+	client := callerID(ctx)
+	res, err := rateLimitCtrl.Allow(ctx, &entity.RateLimitRequest{Route: validation.AuthRoute, Client: client, Peek: true})
+	if err != nil {
+		interceptorLogger(ctx, logger, "Auth").Errorf(entity.FailedToProcessTheRequest, err)
+	} else if !res.Allowed {
+		header, reset := rateLimitHeader(res)
+		header.Set(_retryAfterKey, reset)
+		_ = grpc.SetHeader(ctx, header) // fails only if the headers were sent already
+		return nil, status.Errorf(codes.ResourceExhausted, "%s exceeded %d failed authentication attempts", client, res.Limit)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorized, err := auth.Authorize(ctx, first(md, _apiKeyKey), first(md, _authorizationKey), scope)
+	switch {
+	case errors.Is(err, validation.ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		// only the failed attempts are counted, so that the callers sharing the address aren't limited
+		if _, err := rateLimitCtrl.Allow(ctx, &entity.RateLimitRequest{Route: validation.AuthRoute, Client: client}); err != nil {
+			interceptorLogger(ctx, logger, "Auth").Errorf(entity.FailedToProcessTheRequest, err)
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return authorized, nil
+}
+
+// rateLimitHeader returns the rate limit metadata of the limited route and the seconds until the reset
+func rateLimitHeader(res *entity.RateLimitResponse) (metadata.MD, string) {
+	reset := strconv.FormatInt(int64((res.Reset+time.Second-1)/time.Second), 10)
+	return metadata.Pairs(
+		_rateLimitLimitKey, strconv.FormatInt(res.Limit, 10),
+		_rateLimitRemainingKey, strconv.FormatInt(res.Remaining, 10),
+		_rateLimitResetKey, reset,
+	), reset
+}
+
+// serviceMethod reports whether the method belongs to the service rather than to the health checks or the reflection
+func serviceMethod(method string) bool {
+	return strings.HasPrefix(method, "/"+apiv1.RedisPostgresService_ServiceDesc.ServiceName+"/")
+}
+
+// callerID returns the caller authenticated by unaryAuth or its IP address the same way the http clients are identified,
+// so that the callers share the rate limits and the idempotency keys of the http endpoints
+func callerID(ctx context.Context) string {
+	if principal, ok := validation.PrincipalFromContext(ctx); ok {
+		return "principal:" + principal.ID
+	}
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return "ip:" + host
+}
+
+// interceptorLogger returns the logger of the interceptor with the request id of the call
+func interceptorLogger(ctx context.Context, logger *zap.Logger, function string) *zap.SugaredLogger {
+	return logger.With(
+		zap.String("scope", "rpc"),
+		zap.String("function", function),
+		zap.String("request_id", validation.RequestIDFromContext(ctx)),
+	).Sugar()
+}
+
+// requestID returns the context with the request id provided by the caller or the generated one,
+// the id is sent back in the response header
+func requestID(ctx context.Context) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, id := validation.WithRequestID(ctx, first(md, _requestIDKey))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(_requestIDKey, id)) // fails only if the headers were sent already
+	return ctx, id
+}
+
+// logCall logs the method call with its status code and duration, failed calls are logged as errors
+func logCall(logger *zap.Logger, method, id string, start time.Time, err error) {
+	st := status.Convert(err)
+	logger = logger.With(
+		zap.String("scope", "rpc"),
+		zap.String("function", method),
+		zap.String("request_id", id),
+		zap.String("code", st.Code().String()),
+		zap.Duration("duration", time.Since(start)),
+	)
+	if err != nil {
+		message := st.Message()
+		var cause *causeError
+		if errors.As(err, &cause) {
+			message = cause.cause.Error()
+		}
+		logger.Sugar().Errorf(entity.FailedToProcessTheRequest, message)
+		return
+	}
+	logger.Info("Request handled")
+}
+
+// first returns the first value of the metadata key or the empty string
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// serverStream overrides the context of the grpc.ServerStream with the one returned by the interceptor
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
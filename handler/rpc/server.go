@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"net"
+	apiv1 "redis-postgres-service/api/v1"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/controller/idempotency"
+	"redis-postgres-service/controller/incremental"
+	"redis-postgres-service/controller/ratelimit"
+	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/controller/users"
+	"redis-postgres-service/handler/validation"
+)
+
+const (
+	_configKey            = "grpc"
+	_idempotencyConfigKey = "idempotency"
+)
+
+// Server is the gRPC server of the apiv1.RedisPostgresService with the health service
+// and the server reflection, if it is enabled
+type Server interface {
+	// Start listens the configured address and serves the calls in the background
+	Start() error
+	// Stop stops accepting the calls and waits for the calls in flight, they are cancelled when ctx is done
+	Stop(ctx context.Context) error
+}
+
+// compile time check that server implements Server interface
+var _ Server = (*server)(nil)
+
+// Params is an fx container for all Server dependencies
+type Params struct {
+	fx.In
+
+	Logger          *zap.Logger
+	ConfigProvider  config.Provider
+	Auth            validation.Authenticator
+	IncrementalCtrl incremental.Controller
+	SignCtrl        sign.Controller
+	UsersCtrl       users.Controller
+	RateLimitCtrl   ratelimit.Controller
+	IdempotencyCtrl idempotency.Controller
+}
+
+// New is a constructor provided to the fx for creating a Server. Calls are logged, authenticated with the scopes,
+// rate limited and made idempotent the same way as the calls of the respective http routes.
+func New(p Params) (Server, error) {
+	var cfg internalconfig.GRPCConfig
+	err := p.ConfigProvider.Get(_configKey).Populate(&cfg)
+	if err != nil {
+		return nil, errors.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	var idempotencyCfg internalconfig.IdempotencyConfig
+	err = p.ConfigProvider.Get(_idempotencyConfigKey).Populate(&idempotencyCfg)
+	if err != nil {
+		return nil, errors.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			unaryLogging(p.Logger),
+			unaryAuth(p.Logger, p.Auth, p.RateLimitCtrl),
+			unaryRateLimit(p.Logger, p.RateLimitCtrl),
+			unaryIdempotency(p.Logger, p.IdempotencyCtrl, idempotencyCfg),
+		),
+		grpc.ChainStreamInterceptor(streamLogging(p.Logger), streamAuth(p.Logger, p.Auth, p.RateLimitCtrl)),
+	)
+	apiv1.RegisterRedisPostgresServiceServer(srv, &service{
+		incrementalCtrl: p.IncrementalCtrl,
+		signCtrl:        p.SignCtrl,
+		usersCtrl:       p.UsersCtrl,
+	})
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(apiv1.RedisPostgresService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	if cfg.Reflection {
+		reflection.Register(srv)
+	}
+	return &server{
+		logger:  p.Logger,
+		address: cfg.Address,
+		grpc:    srv,
+		health:  healthSrv,
+	}, nil
+}
+
+type server struct {
+	logger   *zap.Logger
+	address  string
+	grpc     *grpc.Server
+	health   *health.Server
+	listener net.Listener
+}
+
+// Start listens the address synchronously, so that the app fails to start if the address is taken
+func (s *server) Start() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return errors.Errorf("failed to listen %s: %s", s.address, err)
+	}
+	s.listener = listener
+	go func() {
+		if err := s.grpc.Serve(listener); err != nil {
+			s.logger.Error("gRPC server failed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Stop reports the services as not serving to the health checks and stops the server gracefully,
+// the calls in flight are cancelled if they don't complete until ctx is done
+func (s *server) Stop(ctx context.Context) error {
+	s.health.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,572 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"net"
+	apiv1 "redis-postgres-service/api/v1"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/validation"
+	mock_idempotency "redis-postgres-service/mocks/controller/idempotency"
+	mock_incremental "redis-postgres-service/mocks/controller/incremental"
+	mock_ratelimit "redis-postgres-service/mocks/controller/ratelimit"
+	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_users "redis-postgres-service/mocks/controller/users"
+	mock_validation "redis-postgres-service/mocks/handler/validation"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer returns the Server created by New with the mocked dependencies
+func newTestServer(t *testing.T, yaml string, p Params) *server {
+	provider, err := config.NewYAML(config.Source(strings.NewReader(yaml)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Logger = zap.NewNop()
+	p.ConfigProvider = provider
+	srv, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv.(*server)
+}
+
+// dial serves the server on the in-memory listener and returns the connection to it
+func dial(t *testing.T, srv *server) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = srv.grpc.Serve(listener)
+	}()
+	t.Cleanup(srv.grpc.Stop)
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	authMock := mock_validation.NewMockAuthenticator(ctrl)
+	authMock.EXPECT().
+		Authorize(gomock.Any(), "billing-api-key", "", validation.ScopeUsersWrite).
+		DoAndReturn(func(ctx context.Context, _, _, _ string) (context.Context, error) {
+			return ctx, nil
+		})
+	age := 21
+	usersCtrlMock := mock_users.NewMockController(ctrl)
+	usersCtrlMock.EXPECT().
+		Add(gomock.Any(), &entity.AddUserRequest{Name: "Alex", Age: &age}).
+		Return(&entity.AddUserResponse{Id: 7}, nil)
+	rateLimitCtrlMock := mock_ratelimit.NewMockController(ctrl)
+	rateLimitCtrlMock.EXPECT().
+		Allow(gomock.Any(), &entity.RateLimitRequest{Route: validation.AuthRoute, Client: "ip:bufconn", Peek: true}).
+		Return(&entity.RateLimitResponse{Allowed: true, Limit: 300, Remaining: 300}, nil)
+	rateLimitCtrlMock.EXPECT().
+		Allow(gomock.Any(), &entity.RateLimitRequest{Route: "/postgres/users", Client: "ip:bufconn"}).
+		Return(&entity.RateLimitResponse{Allowed: true, Limit: 10, Remaining: 9, Reset: 1500 * time.Millisecond}, nil)
+	srv := newTestServer(t, `{"grpc":{"address":":0","reflection":true}}`, Params{
+		Auth:            authMock,
+		IncrementalCtrl: mock_incremental.NewMockController(ctrl),
+		SignCtrl:        mock_sign.NewMockController(ctrl),
+		UsersCtrl:       usersCtrlMock,
+		RateLimitCtrl:   rateLimitCtrlMock,
+		IdempotencyCtrl: mock_idempotency.NewMockController(ctrl),
+	})
+	conn := dial(t, srv)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "billing-api-key", "x-request-id", "req-1")
+	var header metadata.MD
+	response, err := apiv1.NewRedisPostgresServiceClient(conn).
+		AddUser(ctx, &apiv1.AddUserRequest{Name: "Alex", Age: proto.Int32(21)}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), response.GetId())
+	assert.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+	assert.Equal(t, []string{"9"}, header.Get("x-ratelimit-remaining"))
+	assert.Equal(t, []string{"2"}, header.Get("x-ratelimit-reset"))
+
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: apiv1.RedisPostgresService_ServiceDesc.ServiceName,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	assert.NoError(t, err)
+	reflected, err := stream.Recv()
+	assert.NoError(t, err)
+	var services []string
+	for _, s := range reflected.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	assert.Contains(t, services, apiv1.RedisPostgresService_ServiceDesc.ServiceName)
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
+
+func TestNew_ReflectionDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := newTestServer(t, `{"grpc":{"address":":0"}}`, Params{
+		Auth:            mock_validation.NewMockAuthenticator(ctrl),
+		IncrementalCtrl: mock_incremental.NewMockController(ctrl),
+		SignCtrl:        mock_sign.NewMockController(ctrl),
+		UsersCtrl:       mock_users.NewMockController(ctrl),
+	})
+	stream, err := reflectionpb.NewServerReflectionClient(dial(t, srv)).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	assertStatus(t, err, codes.Unimplemented, "unknown service grpc.reflection.v1alpha.ServerReflection")
+}
+
+func Test_authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockAuth struct {
+		apiKey        string
+		authorization string
+		scope         string
+		err           error
+	}
+	type mockRateLimitCtrl struct {
+		res    *entity.RateLimitResponse
+		err    error
+		failed bool
+	}
+	allowed := &mockRateLimitCtrl{res: &entity.RateLimitResponse{Allowed: true, Limit: 300, Remaining: 300}}
+	tests := []struct {
+		name              string
+		method            string
+		md                metadata.MD
+		mockRateLimitCtrl *mockRateLimitCtrl
+		mockAuth          *mockAuth
+		expectedHeader    metadata.MD
+		expectedCode      codes.Code
+		expectedMessage   string
+	}{
+		{
+			name:              "Happy path, bearer token",
+			method:            apiv1.RedisPostgresService_Increment_FullMethodName,
+			md:                metadata.Pairs("authorization", "Bearer a.b.c"),
+			mockRateLimitCtrl: allowed,
+			mockAuth:          &mockAuth{authorization: "Bearer a.b.c", scope: validation.ScopeCountersWrite},
+		},
+		{
+			name:   "Happy path, health checks are not authenticated",
+			method: healthpb.Health_Check_FullMethodName,
+		},
+		{
+			name:              "Happy path, counters are unavailable",
+			method:            apiv1.RedisPostgresService_Increment_FullMethodName,
+			md:                metadata.Pairs("authorization", "Bearer a.b.c"),
+			mockRateLimitCtrl: &mockRateLimitCtrl{err: fmt.Errorf("connection refused: %w", entity.ErrUnavailable)},
+			mockAuth:          &mockAuth{authorization: "Bearer a.b.c", scope: validation.ScopeCountersWrite},
+		},
+		{
+			name:              "Credentials are missing",
+			method:            apiv1.RedisPostgresService_Sign_FullMethodName,
+			mockRateLimitCtrl: &mockRateLimitCtrl{res: allowed.res, failed: true},
+			mockAuth: &mockAuth{
+				scope: validation.ScopeSign,
+				err:   fmt.Errorf("%w: credentials are missing", validation.ErrUnauthenticated),
+			},
+			expectedCode:    codes.Unauthenticated,
+			expectedMessage: "unauthenticated: credentials are missing",
+		},
+		{
+			name:              "Client doesn't have the scope",
+			method:            apiv1.RedisPostgresService_AddUser_FullMethodName,
+			md:                metadata.Pairs("x-api-key", "reporting-api-key"),
+			mockRateLimitCtrl: allowed,
+			mockAuth: &mockAuth{
+				apiKey: "reporting-api-key",
+				scope:  validation.ScopeUsersWrite,
+				err:    fmt.Errorf("%w: client reporting doesn't have users:write scope", validation.ErrForbidden),
+			},
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "forbidden: client reporting doesn't have users:write scope",
+		},
+		{
+			name:   "Failed attempts are exceeded",
+			method: apiv1.RedisPostgresService_AddUser_FullMethodName,
+			md:     metadata.Pairs("x-api-key", "billing-api-key"),
+			mockRateLimitCtrl: &mockRateLimitCtrl{
+				res: &entity.RateLimitResponse{Limit: 300, Reset: 30 * time.Second},
+			},
+			expectedHeader: metadata.Pairs(
+				"x-ratelimit-limit", "300", "x-ratelimit-remaining", "0", "x-ratelimit-reset", "30", "retry-after", "30",
+			),
+			expectedCode:    codes.ResourceExhausted,
+			expectedMessage: "ip:10.0.0.1 exceeded 300 failed authentication attempts",
+		},
+		{
+			name:            "Method without the scope is denied",
+			method:          "/" + apiv1.RedisPostgresService_ServiceDesc.ServiceName + "/DeleteUser",
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "method /redis_postgres_service.v1.RedisPostgresService/DeleteUser doesn't have a scope",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMock := mock_validation.NewMockAuthenticator(ctrl)
+			if tt.mockAuth != nil {
+				authMock.EXPECT().
+					Authorize(gomock.Any(), tt.mockAuth.apiKey, tt.mockAuth.authorization, tt.mockAuth.scope).
+					DoAndReturn(func(ctx context.Context, _, _, _ string) (context.Context, error) {
+						if tt.mockAuth.err != nil {
+							return nil, tt.mockAuth.err
+						}
+						return ctx, nil
+					})
+			}
+			rateLimitCtrlMock := mock_ratelimit.NewMockController(ctrl)
+			if tt.mockRateLimitCtrl != nil {
+				rateLimitCtrlMock.EXPECT().
+					Allow(gomock.Any(), &entity.RateLimitRequest{Route: validation.AuthRoute, Client: "ip:10.0.0.1", Peek: true}).
+					Return(tt.mockRateLimitCtrl.res, tt.mockRateLimitCtrl.err)
+			}
+			if tt.mockRateLimitCtrl != nil && tt.mockRateLimitCtrl.failed {
+				rateLimitCtrlMock.EXPECT().
+					Allow(gomock.Any(), &entity.RateLimitRequest{Route: validation.AuthRoute, Client: "ip:10.0.0.1"}).
+					Return(&entity.RateLimitResponse{Allowed: true, Limit: 300, Remaining: 299}, nil)
+			}
+			ctx, stream := callContext(tt.md)
+			got, err := authorize(ctx, zap.NewNop(), authMock, rateLimitCtrlMock, tt.method)
+			assert.Equal(t, tt.expectedHeader, stream.header)
+			if tt.expectedCode != codes.OK {
+				assertStatus(t, err, tt.expectedCode, tt.expectedMessage)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, ctx, got)
+		})
+	}
+}
+
+func Test_server_StartAndStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := newTestServer(t, `{"grpc":{"address":"127.0.0.1:0"}}`, Params{
+		Auth:            mock_validation.NewMockAuthenticator(ctrl),
+		IncrementalCtrl: mock_incremental.NewMockController(ctrl),
+		SignCtrl:        mock_sign.NewMockController(ctrl),
+		UsersCtrl:       mock_users.NewMockController(ctrl),
+	})
+	assert.NoError(t, srv.Start())
+	conn, err := grpc.Dial(srv.listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+
+	taken := newTestServer(t, `{"grpc":{"address":"`+srv.listener.Addr().String()+`"}}`, Params{})
+	assert.ErrorContains(t, taken.Start(), "failed to listen")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, srv.Stop(ctx))
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+}
+
+// headerStream is the grpc.ServerTransportStream recording the headers set by the interceptors
+type headerStream struct {
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string {
+	return ""
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *headerStream) SetTrailer(metadata.MD) error {
+	return nil
+}
+
+// callContext returns the context of the call from the peer with the metadata and the stream recording the headers
+func callContext(md metadata.MD) (context.Context, *headerStream) {
+	stream := &headerStream{}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, md)
+	return grpc.NewContextWithServerTransportStream(ctx, stream), stream
+}
+
+func Test_unaryRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockRateLimitCtrl struct {
+		req *entity.RateLimitRequest
+		res *entity.RateLimitResponse
+		err error
+	}
+	tests := []struct {
+		name              string
+		method            string
+		mockRateLimitCtrl *mockRateLimitCtrl
+		expectedCalled    bool
+		expectedHeader    metadata.MD
+		expectedCode      codes.Code
+		expectedMessage   string
+	}{
+		{
+			name:   "Happy path, calls count towards the limit of the http route",
+			method: apiv1.RedisPostgresService_Increment_FullMethodName,
+			mockRateLimitCtrl: &mockRateLimitCtrl{
+				req: &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:10.0.0.1"},
+				res: &entity.RateLimitResponse{Allowed: true, Limit: 2, Remaining: 1, Reset: 30 * time.Second},
+			},
+			expectedCalled: true,
+			expectedHeader: metadata.Pairs("x-ratelimit-limit", "2", "x-ratelimit-remaining", "1", "x-ratelimit-reset", "30"),
+		},
+		{
+			name:   "Limit is exceeded",
+			method: apiv1.RedisPostgresService_Sign_FullMethodName,
+			mockRateLimitCtrl: &mockRateLimitCtrl{
+				req: &entity.RateLimitRequest{Route: "/sign/", Client: "ip:10.0.0.1"},
+				res: &entity.RateLimitResponse{Limit: 2, Reset: 30 * time.Second},
+			},
+			expectedHeader: metadata.Pairs(
+				"x-ratelimit-limit", "2", "x-ratelimit-remaining", "0", "x-ratelimit-reset", "30", "retry-after", "30",
+			),
+			expectedCode:    codes.ResourceExhausted,
+			expectedMessage: "ip:10.0.0.1 exceeded 2 requests to /sign/",
+		},
+		{
+			name:   "Method without the limit",
+			method: apiv1.RedisPostgresService_AddUser_FullMethodName,
+			mockRateLimitCtrl: &mockRateLimitCtrl{
+				req: &entity.RateLimitRequest{Route: "/postgres/users", Client: "ip:10.0.0.1"},
+				res: &entity.RateLimitResponse{Allowed: true},
+			},
+			expectedCalled: true,
+		},
+		{
+			name:   "Counters are unavailable",
+			method: apiv1.RedisPostgresService_AddUser_FullMethodName,
+			mockRateLimitCtrl: &mockRateLimitCtrl{
+				req: &entity.RateLimitRequest{Route: "/postgres/users", Client: "ip:10.0.0.1"},
+				err: fmt.Errorf("connection refused: %w", entity.ErrUnavailable),
+			},
+			expectedCalled: true,
+		},
+		{
+			name:           "Health checks are not limited",
+			method:         healthpb.Health_Check_FullMethodName,
+			expectedCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rateLimitCtrlMock := mock_ratelimit.NewMockController(ctrl)
+			if tt.mockRateLimitCtrl != nil {
+				rateLimitCtrlMock.EXPECT().
+					Allow(gomock.Any(), tt.mockRateLimitCtrl.req).
+					Return(tt.mockRateLimitCtrl.res, tt.mockRateLimitCtrl.err)
+			}
+			ctx, stream := callContext(nil)
+			called := false
+			_, err := unaryRateLimit(zap.NewNop(), rateLimitCtrlMock)(
+				ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, req any) (any, error) {
+					called = true
+					return nil, nil
+				},
+			)
+			assert.Equal(t, tt.expectedCalled, called)
+			assert.Equal(t, tt.expectedHeader, stream.header)
+			if tt.expectedCode != codes.OK {
+				assertStatus(t, err, tt.expectedCode, tt.expectedMessage)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_unaryIdempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	request := &apiv1.IncrementRequest{Key: "Alex", Value: "5"}
+	response := &apiv1.IncrementResponse{Value: "10"}
+	body, _ := proto.Marshal(response)
+	stored := &entity.StoredResponse{
+		Header: map[string][]string{"grpc-message-type": {"redis_postgres_service.v1.IncrementResponse"}},
+		Body:   body,
+	}
+	requestBody, _ := proto.MarshalOptions{Deterministic: true}.Marshal(request)
+	sum := sha256.Sum256(requestBody)
+	idempotencyRequest := &entity.IdempotencyRequest{
+		Client:      "ip:10.0.0.1",
+		Key:         "key-1",
+		Fingerprint: "gRPC " + apiv1.RedisPostgresService_Increment_FullMethodName + " " + hex.EncodeToString(sum[:]),
+	}
+	type mockIdempotencyCtrl struct {
+		beginRes    *entity.IdempotencyResponse
+		beginErr    error
+		complete    *entity.StoredResponse
+		completeErr error
+		release     bool
+	}
+	tests := []struct {
+		name                string
+		md                  metadata.MD
+		maxResponseSize     int64
+		handlerErr          error
+		mockIdempotencyCtrl *mockIdempotencyCtrl
+		expected            any
+		expectedCalled      bool
+		expectedHeader      metadata.MD
+		expectedCode        codes.Code
+		expectedMessage     string
+	}{
+		{
+			name:                "Happy path, response is stored",
+			md:                  metadata.Pairs("idempotency-key", "key-1"),
+			maxResponseSize:     1024,
+			mockIdempotencyCtrl: &mockIdempotencyCtrl{beginRes: &entity.IdempotencyResponse{}, complete: stored},
+			expected:            response,
+			expectedCalled:      true,
+		},
+		{
+			name:                "Happy path, stored response is replayed",
+			md:                  metadata.Pairs("idempotency-key", "key-1"),
+			maxResponseSize:     1024,
+			mockIdempotencyCtrl: &mockIdempotencyCtrl{beginRes: &entity.IdempotencyResponse{Replay: stored}},
+			expected:            response,
+			expectedHeader:      metadata.Pairs("idempotent-replayed", "true"),
+		},
+		{
+			name:           "Call without the key",
+			expected:       response,
+			expectedCalled: true,
+		},
+		{
+			name:            "Key is too long",
+			md:              metadata.Pairs("idempotency-key", strings.Repeat("k", 256)),
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "idempotency-key must not be longer than 255 characters",
+		},
+		{
+			name: "Call with the key is in flight",
+			md:   metadata.Pairs("idempotency-key", "key-1"),
+			mockIdempotencyCtrl: &mockIdempotencyCtrl{
+				beginErr: &entity.PublicError{
+					Message: "request with the idempotency key is in flight, retry later",
+					Err:     fmt.Errorf("request with idempotency key key-1 is in flight: %w", entity.ErrConflict),
+				},
+			},
+			expectedCode:    codes.Aborted,
+			expectedMessage: "request with the idempotency key is in flight, retry later",
+		},
+		{
+			name:                "Failed call releases the key",
+			md:                  metadata.Pairs("idempotency-key", "key-1"),
+			maxResponseSize:     1024,
+			handlerErr:          statusError(fmt.Errorf("connection refused: %w", entity.ErrUnavailable)),
+			mockIdempotencyCtrl: &mockIdempotencyCtrl{beginRes: &entity.IdempotencyResponse{}, release: true},
+			expectedCalled:      true,
+			expectedCode:        codes.Unavailable,
+			expectedMessage:     "downstream service is unavailable, retry later",
+		},
+		{
+			name:                "Response is too big to be stored",
+			md:                  metadata.Pairs("idempotency-key", "key-1"),
+			maxResponseSize:     1,
+			mockIdempotencyCtrl: &mockIdempotencyCtrl{beginRes: &entity.IdempotencyResponse{}, release: true},
+			expected:            response,
+			expectedCalled:      true,
+		},
+		{
+			name:            "Response is not stored",
+			md:              metadata.Pairs("idempotency-key", "key-1"),
+			maxResponseSize: 1024,
+			mockIdempotencyCtrl: &mockIdempotencyCtrl{
+				beginRes:    &entity.IdempotencyResponse{},
+				complete:    stored,
+				completeErr: fmt.Errorf("connection refused: %w", entity.ErrUnavailable),
+				release:     true,
+			},
+			expected:       response,
+			expectedCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idempotencyCtrlMock := mock_idempotency.NewMockController(ctrl)
+			if m := tt.mockIdempotencyCtrl; m != nil {
+				idempotencyCtrlMock.EXPECT().Begin(gomock.Any(), idempotencyRequest).Return(m.beginRes, m.beginErr)
+				if m.complete != nil {
+					idempotencyCtrlMock.EXPECT().Complete(gomock.Any(), idempotencyRequest, m.complete).Return(m.completeErr)
+				}
+				if m.release {
+					idempotencyCtrlMock.EXPECT().Release(gomock.Any(), idempotencyRequest).Return(nil)
+				}
+			}
+			cfg := internalconfig.IdempotencyConfig{Enabled: true, MaxResponseSize: tt.maxResponseSize}
+			ctx, stream := callContext(tt.md)
+			called := false
+			got, err := unaryIdempotency(zap.NewNop(), idempotencyCtrlMock, cfg)(
+				ctx, request, &grpc.UnaryServerInfo{FullMethod: apiv1.RedisPostgresService_Increment_FullMethodName},
+				func(ctx context.Context, req any) (any, error) {
+					called = true
+					if tt.handlerErr != nil {
+						return nil, tt.handlerErr
+					}
+					return response, nil
+				},
+			)
+			assert.Equal(t, tt.expectedCalled, called)
+			assert.Equal(t, tt.expectedHeader, stream.header)
+			if tt.expectedCode != codes.OK {
+				assertStatus(t, err, tt.expectedCode, tt.expectedMessage)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(tt.expected.(proto.Message), got.(proto.Message)))
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apiv1 "redis-postgres-service/api/v1"
+	"redis-postgres-service/controller/incremental"
+	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/controller/users"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/gateway/signer"
+)
+
+// compile time check that service implements apiv1.RedisPostgresServiceServer interface
+var _ apiv1.RedisPostgresServiceServer = (*service)(nil)
+
+// service maps the gRPC messages to the entities of the controllers and back, the same way the http handler does
+type service struct {
+	apiv1.UnimplementedRedisPostgresServiceServer
+
+	incrementalCtrl incremental.Controller
+	signCtrl        sign.Controller
+	usersCtrl       users.Controller
+}
+
+// Increment increments the counter the same way as the POST /redis/incr endpoint
+func (s *service) Increment(ctx context.Context, req *apiv1.IncrementRequest) (*apiv1.IncrementResponse, error) {
+	request := &entity.IncrementRequest{
+		Key:        req.GetKey(),
+		Value:      json.Number(req.GetValue()),
+		Mode:       req.GetMode(),
+		TTLSeconds: req.GetTtlSeconds(),
+		Min:        req.Min,
+		Max:        req.Max,
+	}
+	if err := request.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+	response, err := s.incrementalCtrl.Inc(ctx, request)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &apiv1.IncrementResponse{
+		Value:      response.Value.String(),
+		TtlSeconds: response.TTLSeconds,
+	}, nil
+}
+
+// Sign signs the text the same way as the POST /sign/{algorithm} endpoint. Raw signatures are returned
+// in the raw_signature field, cause protobuf strings must be valid UTF-8.
+func (s *service) Sign(ctx context.Context, req *apiv1.SignRequest) (*apiv1.SignResponse, error) {
+	request := &entity.SignRequest{
+		Algorithm:  req.GetAlgorithm(),
+		Text:       req.GetText(),
+		Key:        req.GetKey(),
+		KeyID:      req.GetKeyId(),
+		KeyVersion: int(req.GetKeyVersion()),
+		Encoding:   req.GetEncoding(),
+	}
+	if err := request.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+	response, err := s.signCtrl.Sign(ctx, request)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	signature := &apiv1.SignResponse{
+		Algorithm:  response.Algorithm,
+		Encoding:   response.Encoding,
+		Signature:  response.Signature,
+		KeyId:      response.KeyID,
+		KeyVersion: int32(response.KeyVersion),
+	}
+	if response.Encoding == signer.EncodingRaw {
+		signature.Signature, signature.RawSignature = "", []byte(response.Signature)
+	}
+	return signature, nil
+}
+
+// AddUser adds the user the same way as the POST /postgres/users endpoint
+func (s *service) AddUser(ctx context.Context, req *apiv1.AddUserRequest) (*apiv1.AddUserResponse, error) {
+	request := &entity.AddUserRequest{
+		Name: req.GetName(),
+	}
+	if req.Age != nil {
+		age := int(req.GetAge())
+		request.Age = &age
+	}
+	if err := request.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+	response, err := s.usersCtrl.Add(ctx, request)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &apiv1.AddUserResponse{Id: response.Id}, nil
+}
+
+// _messages replace the messages of the server errors the same way the problem details do,
+// so that the internal errors don't leak to the callers
+var _messages = map[codes.Code]string{
+	codes.Internal:    entity.InternalErrorMessage,
+	codes.Unavailable: entity.UnavailableMessage,
+}
+
+// statusError maps the error returned by a controller to the gRPC status the same way the http handler maps it
+// to the http status. Invalid fields of entity.ValidationError are reported in the google.rpc.BadRequest details.
+// Messages of the server errors are replaced by the generic ones, the error is kept for the logs as the cause.
+func statusError(err error) error {
+	code := statusCode(err)
+	if message, ok := _messages[code]; ok {
+		return &causeError{status: status.New(code, message), cause: err}
+	}
+	st := status.New(code, entity.PublicMessage(err))
+	var verr *entity.ValidationError
+	if !errors.As(err, &verr) {
+		return st.Err()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, detail := range verr.Details {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       detail.Field,
+			Description: detail.Reason,
+		})
+	}
+	withDetails, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st.Err() // unreachable, cause the details are always serializable
+	}
+	return withDetails.Err()
+}
+
+// causeError is the status error sent to the caller instead of its cause, the cause is logged by the interceptor
+type causeError struct {
+	status *status.Status
+	cause  error
+}
+
+func (e *causeError) Error() string {
+	return e.cause.Error()
+}
+
+// GRPCStatus returns the status sent to the caller
+func (e *causeError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+func (e *causeError) Unwrap() error {
+	return e.cause
+}
+
+// statusCode returns the gRPC code of the controller error, errors that are not caused by the request
+// nor by the downstream availability are internal
+func statusCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, entity.ErrInvalidArgument):
+		return codes.InvalidArgument
+	case errors.Is(err, entity.ErrConflict):
+		return codes.Aborted
+	case errors.Is(err, entity.ErrOverflow):
+		return codes.OutOfRange
+	case errors.Is(err, entity.ErrUnavailable):
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
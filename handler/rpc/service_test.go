@@ -0,0 +1,307 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	apiv1 "redis-postgres-service/api/v1"
+	"redis-postgres-service/entity"
+	mock_incremental "redis-postgres-service/mocks/controller/incremental"
+	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_users "redis-postgres-service/mocks/controller/users"
+	"testing"
+)
+
+// assertStatus checks the code and the message of the status error and the field violations of its details
+func assertStatus(t *testing.T, err error, code codes.Code, message string, violations ...string) {
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("%v is not a status error", err)
+	}
+	assert.Equal(t, code, st.Code())
+	assert.Equal(t, message, st.Message())
+	var got []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.FieldViolations {
+				got = append(got, v.Field+" "+v.Description)
+			}
+		}
+	}
+	assert.Equal(t, violations, got)
+}
+
+func Test_service_Increment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	min, max := int64(0), int64(10)
+	type mockIncrementalCtrl struct {
+		req *entity.IncrementRequest
+		res *entity.IncrementResponse
+		err error
+	}
+	tests := []struct {
+		name                string
+		req                 *apiv1.IncrementRequest
+		mockIncrementalCtrl *mockIncrementalCtrl
+		expected            *apiv1.IncrementResponse
+		expectedCode        codes.Code
+		expectedMessage     string
+		expectedViolations  []string
+	}{
+		{
+			name: "Happy path",
+			req:  &apiv1.IncrementRequest{Key: "Alex", Value: "5", TtlSeconds: 60, Min: &min, Max: &max},
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.IncrementRequest{Key: "Alex", Value: "5", TTLSeconds: 60, Min: &min, Max: &max},
+				res: &entity.IncrementResponse{Value: "10", TTLSeconds: 60},
+			},
+			expected: &apiv1.IncrementResponse{Value: "10", TtlSeconds: 60},
+		},
+		{
+			name:         "Invalid request",
+			req:          &apiv1.IncrementRequest{Value: "5", Mode: "hex", TtlSeconds: -1},
+			expectedCode: codes.InvalidArgument,
+			expectedMessage: "invalid request: key must not be empty, mode must be one of int, float, decimal, " +
+				"ttl_seconds must not be negative",
+			expectedViolations: []string{
+				"key must not be empty",
+				"mode must be one of int, float, decimal",
+				"ttl_seconds must not be negative",
+			},
+		},
+		{
+			name: "Counter overflows",
+			req:  &apiv1.IncrementRequest{Key: "Alex", Value: "5"},
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.IncrementRequest{Key: "Alex", Value: "5"},
+				err: fmt.Errorf("increment would overflow: %w", entity.ErrOverflow),
+			},
+			expectedCode:    codes.OutOfRange,
+			expectedMessage: "increment would overflow: overflow",
+		},
+		{
+			name: "Counter overflows the stored value",
+			req:  &apiv1.IncrementRequest{Key: "Alex", Value: "5"},
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.IncrementRequest{Key: "Alex", Value: "5"},
+				err: &entity.PublicError{
+					Message: "increment overflows the stored value",
+					Err:     fmt.Errorf("ERR increment or decrement would overflow: %w", entity.ErrOverflow),
+				},
+			},
+			expectedCode:    codes.OutOfRange,
+			expectedMessage: "increment overflows the stored value",
+		},
+		{
+			name: "Redis fails",
+			req:  &apiv1.IncrementRequest{Key: "Alex", Value: "5"},
+			mockIncrementalCtrl: &mockIncrementalCtrl{
+				req: &entity.IncrementRequest{Key: "Alex", Value: "5"},
+				err: fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value"),
+			},
+			expectedCode:    codes.Internal,
+			expectedMessage: "internal error, see the logs for the request id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incrementalCtrlMock := mock_incremental.NewMockController(ctrl)
+			if tt.mockIncrementalCtrl != nil {
+				incrementalCtrlMock.EXPECT().
+					Inc(gomock.Any(), tt.mockIncrementalCtrl.req).
+					Return(tt.mockIncrementalCtrl.res, tt.mockIncrementalCtrl.err)
+			}
+			s := &service{incrementalCtrl: incrementalCtrlMock}
+			got, err := s.Increment(context.Background(), tt.req)
+			if tt.expectedCode != codes.OK {
+				assertStatus(t, err, tt.expectedCode, tt.expectedMessage, tt.expectedViolations...)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_service_Sign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockSignCtrl struct {
+		req *entity.SignRequest
+		res *entity.SignResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		req                *apiv1.SignRequest
+		mockSignCtrl       *mockSignCtrl
+		expected           *apiv1.SignResponse
+		expectedCode       codes.Code
+		expectedMessage    string
+		expectedViolations []string
+	}{
+		{
+			name: "Happy path, server-side key",
+			req:  &apiv1.SignRequest{Algorithm: "hmacsha512", Text: "hello", KeyId: "billing", KeyVersion: 2},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignRequest{Algorithm: "hmacsha512", Text: "hello", KeyID: "billing", KeyVersion: 2},
+				res: &entity.SignResponse{
+					Algorithm:  "hmacsha512",
+					Encoding:   "hex",
+					Signature:  "12ab",
+					Hex:        "12ab",
+					KeyID:      "billing",
+					KeyVersion: 2,
+				},
+			},
+			expected: &apiv1.SignResponse{
+				Algorithm:  "hmacsha512",
+				Encoding:   "hex",
+				Signature:  "12ab",
+				KeyId:      "billing",
+				KeyVersion: 2,
+			},
+		},
+		{
+			name: "Happy path, raw signature",
+			req:  &apiv1.SignRequest{Algorithm: "hmacsha512", Text: "hello", Key: "secret", Encoding: "raw"},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignRequest{Algorithm: "hmacsha512", Text: "hello", Key: "secret", Encoding: "raw"},
+				res: &entity.SignResponse{Algorithm: "hmacsha512", Encoding: "raw", Signature: "\x12\xab\xff"},
+			},
+			expected: &apiv1.SignResponse{Algorithm: "hmacsha512", Encoding: "raw", RawSignature: []byte{0x12, 0xab, 0xff}},
+		},
+		{
+			name:               "Invalid request",
+			req:                &apiv1.SignRequest{Text: "hello", Key: "secret", KeyId: "billing"},
+			expectedCode:       codes.InvalidArgument,
+			expectedMessage:    "invalid request: algorithm must not be empty, key must not be provided with key_id",
+			expectedViolations: []string{"algorithm must not be empty", "key must not be provided with key_id"},
+		},
+		{
+			name: "Server-side key is not found",
+			req:  &apiv1.SignRequest{Algorithm: "hmacsha512", Text: "hello", KeyId: "billing"},
+			mockSignCtrl: &mockSignCtrl{
+				req: &entity.SignRequest{Algorithm: "hmacsha512", Text: "hello", KeyID: "billing"},
+				err: fmt.Errorf("key billing: %w", entity.ErrNotFound),
+			},
+			expectedCode:    codes.NotFound,
+			expectedMessage: "key billing: not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signCtrlMock := mock_sign.NewMockController(ctrl)
+			if tt.mockSignCtrl != nil {
+				signCtrlMock.EXPECT().
+					Sign(gomock.Any(), tt.mockSignCtrl.req).
+					Return(tt.mockSignCtrl.res, tt.mockSignCtrl.err)
+			}
+			s := &service{signCtrl: signCtrlMock}
+			got, err := s.Sign(context.Background(), tt.req)
+			if tt.expectedCode != codes.OK {
+				assertStatus(t, err, tt.expectedCode, tt.expectedMessage, tt.expectedViolations...)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_service_AddUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	age := 21
+	type mockUsersCtrl struct {
+		req *entity.AddUserRequest
+		res *entity.AddUserResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		req                *apiv1.AddUserRequest
+		mockUsersCtrl      *mockUsersCtrl
+		expected           *apiv1.AddUserResponse
+		expectedCode       codes.Code
+		expectedMessage    string
+		expectedViolations []string
+	}{
+		{
+			name: "Happy path",
+			req:  &apiv1.AddUserRequest{Name: "Alex", Age: proto.Int32(21)},
+			mockUsersCtrl: &mockUsersCtrl{
+				req: &entity.AddUserRequest{Name: "Alex", Age: &age},
+				res: &entity.AddUserResponse{Id: 7},
+			},
+			expected: &apiv1.AddUserResponse{Id: 7},
+		},
+		{
+			name:               "Invalid request",
+			req:                &apiv1.AddUserRequest{Age: proto.Int32(151)},
+			expectedCode:       codes.InvalidArgument,
+			expectedMessage:    "invalid request: name must not be empty, age must be between 0 and 150",
+			expectedViolations: []string{"name must not be empty", "age must be between 0 and 150"},
+		},
+		{
+			name:               "Age is missing",
+			req:                &apiv1.AddUserRequest{Name: "Alex"},
+			expectedCode:       codes.InvalidArgument,
+			expectedMessage:    "invalid request: age must be provided",
+			expectedViolations: []string{"age must be provided"},
+		},
+		{
+			name: "Postgres is unavailable",
+			req:  &apiv1.AddUserRequest{Name: "Alex", Age: proto.Int32(21)},
+			mockUsersCtrl: &mockUsersCtrl{
+				req: &entity.AddUserRequest{Name: "Alex", Age: &age},
+				err: fmt.Errorf("connection refused: %w", entity.ErrUnavailable),
+			},
+			expectedCode:    codes.Unavailable,
+			expectedMessage: "downstream service is unavailable, retry later",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usersCtrlMock := mock_users.NewMockController(ctrl)
+			if tt.mockUsersCtrl != nil {
+				usersCtrlMock.EXPECT().
+					Add(gomock.Any(), tt.mockUsersCtrl.req).
+					Return(tt.mockUsersCtrl.res, tt.mockUsersCtrl.err)
+			}
+			s := &service{usersCtrl: usersCtrlMock}
+			got, err := s.AddUser(context.Background(), tt.req)
+			if tt.expectedCode != codes.OK {
+				assertStatus(t, err, tt.expectedCode, tt.expectedMessage, tt.expectedViolations...)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_statusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{err: entity.ErrNotFound, want: codes.NotFound},
+		{err: entity.ErrInvalidArgument, want: codes.InvalidArgument},
+		{err: entity.ErrConflict, want: codes.Aborted},
+		{err: entity.ErrOverflow, want: codes.OutOfRange},
+		{err: entity.ErrUnavailable, want: codes.Unavailable},
+		{err: fmt.Errorf("test error"), want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.want, statusCode(fmt.Errorf("wrapped: %w", tt.err)))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/validation"
+)
+
+// IssueToken is a POST endpoint that issues the JWT signed by the server-side key with the claims, expiration
+// and audience provided in the request body, e.g. /tokens/issue. The `scope` claim is limited to the scopes of the caller.
+// expected JSON request is defined by entity.IssueTokenRequest
+// expected JSON response is defined by entity.IssueTokenResponse
+func (h *handler) IssueToken(w http.ResponseWriter, req *http.Request) {
+	e := endpoint(h, "IssueToken", h.tokensCtrl.Issue)
+	e.Bind = func(req *http.Request, request *entity.IssueTokenRequest) error {
+		if principal, ok := validation.PrincipalFromContext(req.Context()); ok {
+			// the scopes are never nil for the authenticated callers, so that the callers without the scopes can't grant any
+			request.CallerScopes = append([]string{}, principal.Scopes...)
+		}
+		return nil
+	}
+	e.ServeHTTP(w, req)
+}
+
+// VerifyToken is a POST endpoint that validates the signature and the registered claims of the JWT
+// provided in the request body, e.g. /tokens/verify. Tokens that are expired, issued for another audience
+// or don't match the signature are reported as invalid with the reason rather than as errors.
+// expected JSON request is defined by entity.VerifyTokenRequest
+// expected JSON response is defined by entity.VerifyTokenResponse
+func (h *handler) VerifyToken(w http.ResponseWriter, req *http.Request) {
+	endpoint(h, "VerifyToken", h.tokensCtrl.Verify).ServeHTTP(w, req)
+}
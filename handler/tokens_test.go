@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	"redis-postgres-service/handler/validation"
+	mock_incremental "redis-postgres-service/mocks/controller/incremental"
+	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_tokens "redis-postgres-service/mocks/controller/tokens"
+	mock_users "redis-postgres-service/mocks/controller/users"
+	"strings"
+	"testing"
+)
+
+func newTokensTestHandler(ctrl *gomock.Controller, tokensCtrl *mock_tokens.MockController, limit int64) *handler {
+	return &handler{
+		logger:          zap.NewNop(),
+		usersCtrl:       mock_users.NewMockController(ctrl),
+		incrementalCtrl: mock_incremental.NewMockController(ctrl),
+		signCtrl:        mock_sign.NewMockController(ctrl),
+		tokensCtrl:      tokensCtrl,
+		config: internalconfig.HandlerConfig{
+			RequestBodyLimit: limit,
+		},
+	}
+}
+
+// issuerContext returns the context of the caller authenticated by the API key with the tokens:issue scope only
+func issuerContext(t *testing.T, ctrl *gomock.Controller) context.Context {
+	hash := sha256.Sum256([]byte("issuer-api-key"))
+	provider, err := config.NewYAML(config.Source(strings.NewReader(
+		`{"auth":{"enabled":true},"auth_clients":{"issuer":{"scopes":["tokens:issue"],` +
+			`"api_key_sha256":"` + hex.EncodeToString(hash[:]) + `"}}}`,
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := validation.NewAuthenticator(validation.AuthParams{
+		ConfigProvider: provider,
+		SignCtrl:       mock_sign.NewMockController(ctrl),
+		TokensCtrl:     mock_tokens.NewMockController(ctrl),
+		Logger:         zap.NewNop(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := auth.Authorize(context.Background(), "issuer-api-key", "", validation.ScopeTokensIssue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func Test_handler_IssueToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockTokensCtrl struct {
+		req *entity.IssueTokenRequest
+		res *entity.IssueTokenResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		body               string
+		authenticated      bool
+		requestBodyLimit   int64
+		mockTokensCtrl     *mockTokensCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:             "Happy path",
+			body:             `{"subject":"alice","audience":["billing"],"expires_in":60,"claims":{"role":"admin"}}`,
+			requestBodyLimit: 1048576,
+			mockTokensCtrl: &mockTokensCtrl{
+				req: &entity.IssueTokenRequest{
+					Subject:   "alice",
+					Audience:  []string{"billing"},
+					ExpiresIn: 60,
+					Claims:    map[string]any{"role": "admin"},
+				},
+				res: &entity.IssueTokenResponse{Token: "a.b.c", ExpiresAt: 1700000060, KeyID: "tokens", KeyVersion: 2},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"token":"a.b.c","expires_at":1700000060,"key_id":"tokens","key_version":2}`,
+		},
+		{
+			name:             "scopes of the authenticated caller are passed to the controller",
+			body:             `{"subject":"alice","claims":{"scope":"counters:read"}}`,
+			authenticated:    true,
+			requestBodyLimit: 1048576,
+			mockTokensCtrl: &mockTokensCtrl{
+				req: &entity.IssueTokenRequest{
+					Subject:      "alice",
+					Claims:       map[string]any{"scope": "counters:read"},
+					CallerScopes: []string{"tokens:issue"},
+				},
+				err: fmt.Errorf("claim scope can't grant counters:read scope the caller doesn't have: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"claim scope can't grant counters:read scope the caller doesn't have: invalid argument",
+			),
+		},
+		{
+			name:               "request body too big",
+			body:               `{"subject":"alice"}`,
+			requestBodyLimit:   1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
+		},
+		{
+			name:               "body is not a valid json",
+			body:               `{"subject":_`,
+			requestBodyLimit:   1048576,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of value",
+			),
+		},
+		{
+			name:             "signing key is not found",
+			body:             `{"key_id":"unknown"}`,
+			requestBodyLimit: 1048576,
+			mockTokensCtrl: &mockTokensCtrl{
+				req: &entity.IssueTokenRequest{KeyID: "unknown"},
+				err: fmt.Errorf("signing key unknown: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: problemBody(
+				http.StatusNotFound,
+				problem.CodeNotFound,
+				"signing key unknown: not found",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, "/tokens/issue", bytes.NewReader([]byte(tt.body)))
+			if tt.authenticated {
+				httpreq = httpreq.WithContext(issuerContext(t, ctrl))
+			}
+			tokensCtrlMock := mock_tokens.NewMockController(ctrl)
+			if tt.mockTokensCtrl != nil {
+				tokensCtrlMock.
+					EXPECT().
+					Issue(httpreq.Context(), tt.mockTokensCtrl.req).
+					Return(tt.mockTokensCtrl.res, tt.mockTokensCtrl.err)
+			}
+			h := newTokensTestHandler(ctrl, tokensCtrlMock, tt.requestBodyLimit)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.IssueToken).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_VerifyToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockTokensCtrl struct {
+		req *entity.VerifyTokenRequest
+		res *entity.VerifyTokenResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		body               string
+		requestBodyLimit   int64
+		mockTokensCtrl     *mockTokensCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:             "Happy path",
+			body:             `{"token":"a.b.c","audience":"billing"}`,
+			requestBodyLimit: 1048576,
+			mockTokensCtrl: &mockTokensCtrl{
+				req: &entity.VerifyTokenRequest{Token: "a.b.c", Audience: "billing"},
+				res: &entity.VerifyTokenResponse{
+					Valid:      true,
+					Claims:     map[string]any{"sub": "alice"},
+					KeyID:      "tokens",
+					KeyVersion: 2,
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"valid":true,"claims":{"sub":"alice"},"key_id":"tokens","key_version":2}`,
+		},
+		{
+			name:             "Happy path, token has expired",
+			body:             `{"token":"a.b.c"}`,
+			requestBodyLimit: 1048576,
+			mockTokensCtrl: &mockTokensCtrl{
+				req: &entity.VerifyTokenRequest{Token: "a.b.c"},
+				res: &entity.VerifyTokenResponse{
+					Claims:     map[string]any{"sub": "alice"},
+					KeyID:      "tokens",
+					KeyVersion: 2,
+					Reason:     "token has expired",
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"valid":false,"claims":{"sub":"alice"},"key_id":"tokens","key_version":2,"reason":"token has expired"}`,
+		},
+		{
+			name:               "request body too big",
+			body:               `{"token":"a.b.c"}`,
+			requestBodyLimit:   1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"request body is too big",
+			),
+		},
+		{
+			name:             "token is malformed",
+			body:             `{"token":"a.b"}`,
+			requestBodyLimit: 1048576,
+			mockTokensCtrl: &mockTokensCtrl{
+				req: &entity.VerifyTokenRequest{Token: "a.b"},
+				err: fmt.Errorf("token must consist of 3 segments: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"token must consist of 3 segments: invalid argument",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, "/tokens/verify", bytes.NewReader([]byte(tt.body)))
+			tokensCtrlMock := mock_tokens.NewMockController(ctrl)
+			if tt.mockTokensCtrl != nil {
+				tokensCtrlMock.
+					EXPECT().
+					Verify(httpreq.Context(), tt.mockTokensCtrl.req).
+					Return(tt.mockTokensCtrl.res, tt.mockTokensCtrl.err)
+			}
+			h := newTokensTestHandler(ctrl, tokensCtrlMock, tt.requestBodyLimit)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.VerifyToken).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
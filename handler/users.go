@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	"redis-postgres-service/handler/validation"
+	mapper "redis-postgres-service/mapper/common"
+	"strconv"
+	"strings"
+)
+
+const (
+	_defaultUsersLimit = 50
+	_maxUsersLimit     = 1000
+)
+
+// _usersPathPrefix is the path prefix of the user endpoints followed by the user id
+const _usersPathPrefix = "/postgres/users/"
+
+// _ndjsonContentType is the content type of the bulk requests with one JSON object per line
+const _ndjsonContentType = "application/x-ndjson"
+
+// GetUser is a GET endpoint that returns the row of the `users` table with the id provided in the path,
+// e.g. /postgres/users/{id}
+// expected JSON response is defined by entity.User
+func (h *handler) GetUser(w http.ResponseWriter, req *http.Request) {
+	logger := h.logger.With(
+		zap.String("scope", "handler"),
+		zap.String("function", "GetUser"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
+	).Sugar()
+	logger.Info("Request received")
+	id, err := idFromPath(req)
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	response, err := h.usersCtrl.Get(req.Context(), &entity.GetUserRequest{Id: id})
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	writeResponse(w, logger, response)
+}
+
+// ListUsers is a GET endpoint that returns the page of rows of the `users` table ordered by id.
+// Page is controlled by `limit` and `offset` query params.
+// expected JSON response is defined by entity.ListUsersResponse
+func (h *handler) ListUsers(w http.ResponseWriter, req *http.Request) {
+	logger := h.logger.With(
+		zap.String("scope", "handler"),
+		zap.String("function", "ListUsers"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
+	).Sugar()
+	logger.Info("Request received")
+	request, err := listUsersRequestFromQuery(req)
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	response, err := h.usersCtrl.List(req.Context(), request)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	writeResponse(w, logger, response)
+}
+
+// UpdateUser is a PUT/PATCH endpoint that updates the row of the `users` table with the id provided in the path,
+// e.g. /postgres/users/{id}. PUT requires all the fields to be provided, PATCH updates only the provided ones.
+// expected JSON request is defined by entity.UpdateUserRequest
+// expected JSON response is defined by entity.User
+func (h *handler) UpdateUser(w http.ResponseWriter, req *http.Request) {
+	e := endpoint(h, "UpdateUser", h.usersCtrl.Update)
+	e.Bind = func(req *http.Request, request *entity.UpdateUserRequest) (err error) {
+		request.Id, err = idFromPath(req)
+		if err != nil {
+			return err
+		}
+		request.Replace = req.Method == http.MethodPut
+		return checkUpdateUserRequest(request)
+	}
+	e.ServeHTTP(w, req)
+}
+
+// DeleteUser is a DELETE endpoint that removes the row of the `users` table with the id provided in the path,
+// e.g. /postgres/users/{id}
+// expected JSON response is defined by entity.User and contains the removed row
+func (h *handler) DeleteUser(w http.ResponseWriter, req *http.Request) {
+	logger := h.logger.With(
+		zap.String("scope", "handler"),
+		zap.String("function", "DeleteUser"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
+	).Sugar()
+	logger.Info("Request received")
+	id, err := idFromPath(req)
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	response, err := h.usersCtrl.Delete(req.Context(), &entity.DeleteUserRequest{Id: id})
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	writeResponse(w, logger, response)
+}
+
+// BulkAddUsers is a POST endpoint that appends many rows to `users` table in postgres and returns their ids.
+// The body is either a JSON array of entity.AddUserRequest or, if the Content-Type is application/x-ndjson,
+// one entity.AddUserRequest per line. `atomic=true` query param makes the import all-or-nothing.
+// expected JSON response is defined by entity.BulkAddUsersResponse
+func (h *handler) BulkAddUsers(w http.ResponseWriter, req *http.Request) {
+	logger := h.logger.With(
+		zap.String("scope", "handler"),
+		zap.String("function", "BulkAddUsers"),
+		zap.String("request_id", validation.RequestIDFromContext(req.Context())),
+	).Sugar()
+	logger.Info("Request received")
+	defer req.Body.Close()
+	w.Header().Add("Vary", "Accept")
+	codec, err := mapper.Negotiate(req.Header.Get("Accept"))
+	if err != nil {
+		problem.Error(w, err.Error(), http.StatusNotAcceptable)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	bodyLimit := h.config.BulkRequestBodyLimit
+	if bodyLimit <= 0 {
+		bodyLimit = h.config.RequestBodyLimit
+	}
+	data, ok := readBody(w, req, bodyLimit, logger)
+	if !ok {
+		return
+	}
+	request := &entity.BulkAddUsersRequest{}
+	request.Atomic, err = atomicFromQuery(req)
+	if err == nil {
+		opts := decodeOptions(req, h.strictDecoding("BulkAddUsers"))
+		request.Users, err = bulkUsersFromBody(req.Header.Get("Content-Type"), data, opts...)
+	}
+	if err == nil {
+		err = request.Validate()
+	}
+	if err != nil {
+		problem.Error(w, err.Error(), decodingStatus(err), problemDetails(err)...)
+		logger.Errorf(entity.BadRequest, err)
+		return
+	}
+	response, err := h.usersCtrl.BulkAdd(req.Context(), request)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	writeEncodedResponse(w, logger, response, codec)
+}
+
+// idFromPath reads the positive integer id from the request path, the id must be the only segment
+// after the prefix, so that e.g. /postgres/users/foo/1 is rejected
+func idFromPath(req *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(req.URL.Path, _usersPathPrefix), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("id in the path must be a positive integer")
+	}
+	return id, nil
+}
+
+// listUsersRequestFromQuery reads the pagination params from the request query applying defaults
+func listUsersRequestFromQuery(req *http.Request) (*entity.ListUsersRequest, error) {
+	request := &entity.ListUsersRequest{
+		Limit: _defaultUsersLimit,
+	}
+	query := req.URL.Query()
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > _maxUsersLimit {
+			return nil, fmt.Errorf("limit must be an integer between 1 and %d", _maxUsersLimit)
+		}
+		request.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return nil, errors.New("offset must be a non-negative integer")
+		}
+		request.Offset = value
+	}
+	return request, nil
+}
+
+// checkUpdateUserRequest makes sure PATCH requests change at least one field, the fields missing
+// in PUT requests are reported by entity.UpdateUserRequest.Validate
+func checkUpdateUserRequest(request *entity.UpdateUserRequest) error {
+	if !request.Replace && request.Name == nil && request.Age == nil {
+		return errors.New("nothing to update")
+	}
+	return nil
+}
+
+// bulkUsersFromBody decodes the users from either the array encoded by the codec of the content type
+// or NDJSON body
+func bulkUsersFromBody(contentType string, data []byte, opts ...mapper.Option) ([]entity.AddUserRequest, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != _ndjsonContentType {
+		codec, err := mapper.CodecFor(contentType)
+		if err != nil {
+			return nil, err
+		}
+		users, err := mapper.BytesToType[[]entity.AddUserRequest](data, append(opts, mapper.WithCodec(codec))...)
+		if err != nil {
+			return nil, err
+		}
+		return *users, nil
+	}
+	var users []entity.AddUserRequest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var line json.RawMessage
+		err := decoder.Decode(&line)
+		if err == io.EOF {
+			return users, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal user %d: %s", len(users)+1, err)
+		}
+		user, err := mapper.BytesToType[entity.AddUserRequest](line, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("user %d: %s", len(users)+1, err)
+		}
+		users = append(users, *user)
+	}
+}
@@ -0,0 +1,633 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	mock_incremental "redis-postgres-service/mocks/controller/incremental"
+	mock_sign "redis-postgres-service/mocks/controller/sign"
+	mock_users "redis-postgres-service/mocks/controller/users"
+	"testing"
+)
+
+func newUsersTestHandler(ctrl *gomock.Controller, usersCtrl *mock_users.MockController) *handler {
+	return &handler{
+		logger:          zap.NewNop(),
+		usersCtrl:       usersCtrl,
+		incrementalCtrl: mock_incremental.NewMockController(ctrl),
+		signCtrl:        mock_sign.NewMockController(ctrl),
+		config: internalconfig.HandlerConfig{
+			RequestBodyLimit: 1048576,
+		},
+	}
+}
+
+func Test_handler_GetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockUserCtrl struct {
+		req *entity.GetUserRequest
+		res *entity.User
+		err error
+	}
+	tests := []struct {
+		name               string
+		url                string
+		mockUserCtrl       *mockUserCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "Happy path",
+			url:  "/postgres/users/12",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.GetUserRequest{Id: 12},
+				res: &entity.User{Id: 12, Name: "Alex", Age: 23},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"id":12,"name":"Alex","age":23}`,
+		},
+		{
+			name:               "id is not a number",
+			url:                "/postgres/users/abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"id in the path must be a positive integer",
+			),
+		},
+		{
+			name:               "id is not the only segment of the path",
+			url:                "/postgres/users/foo/1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"id in the path must be a positive integer",
+			),
+		},
+		{
+			name: "user not found",
+			url:  "/postgres/users/12",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.GetUserRequest{Id: 12},
+				err: fmt.Errorf("user 12: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "user 12: not found"),
+		},
+		{
+			name: "controller fails",
+			url:  "/postgres/users/12",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.GetUserRequest{Id: 12},
+				err: errors.New("some error"),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			usersCtrlMock := mock_users.NewMockController(ctrl)
+			if tt.mockUserCtrl != nil {
+				usersCtrlMock.
+					EXPECT().
+					Get(httpreq.Context(), tt.mockUserCtrl.req).
+					Return(tt.mockUserCtrl.res, tt.mockUserCtrl.err)
+			}
+			h := newUsersTestHandler(ctrl, usersCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.GetUser).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_ListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockUserCtrl struct {
+		req *entity.ListUsersRequest
+		res *entity.ListUsersResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		url                string
+		mockUserCtrl       *mockUserCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "Happy path with defaults",
+			url:  "/postgres/users",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.ListUsersRequest{Limit: 50, Offset: 0},
+				res: &entity.ListUsersResponse{
+					Users: []entity.User{{Id: 12, Name: "Alex", Age: 23}},
+					Limit: 50,
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"users":[{"id":12,"name":"Alex","age":23}],"limit":50,"offset":0}`,
+		},
+		{
+			name: "Happy path with pagination",
+			url:  "/postgres/users?limit=1&offset=5",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.ListUsersRequest{Limit: 1, Offset: 5},
+				res: &entity.ListUsersResponse{
+					Users:  []entity.User{},
+					Limit:  1,
+					Offset: 5,
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"users":[],"limit":1,"offset":5}`,
+		},
+		{
+			name:               "limit is too big",
+			url:                "/postgres/users?limit=100000",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"limit must be an integer between 1 and 1000",
+			),
+		},
+		{
+			name:               "offset is negative",
+			url:                "/postgres/users?offset=-1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"offset must be a non-negative integer",
+			),
+		},
+		{
+			name: "controller fails",
+			url:  "/postgres/users",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.ListUsersRequest{Limit: 50, Offset: 0},
+				err: errors.New("some error"),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			usersCtrlMock := mock_users.NewMockController(ctrl)
+			if tt.mockUserCtrl != nil {
+				usersCtrlMock.
+					EXPECT().
+					List(httpreq.Context(), tt.mockUserCtrl.req).
+					Return(tt.mockUserCtrl.res, tt.mockUserCtrl.err)
+			}
+			h := newUsersTestHandler(ctrl, usersCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.ListUsers).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_UpdateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	name := "Bob"
+	age := 30
+	type mockUserCtrl struct {
+		req *entity.UpdateUserRequest
+		res *entity.User
+		err error
+	}
+	tests := []struct {
+		name               string
+		method             string
+		url                string
+		body               []byte
+		mockUserCtrl       *mockUserCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "PATCH happy path",
+			method: http.MethodPatch,
+			url:    "/postgres/users/12",
+			body:   []byte(`{"age":30}`),
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.UpdateUserRequest{Id: 12, Age: &age},
+				res: &entity.User{Id: 12, Name: "Alex", Age: 30},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"id":12,"name":"Alex","age":30}`,
+		},
+		{
+			name:   "PUT happy path",
+			method: http.MethodPut,
+			url:    "/postgres/users/12",
+			body:   []byte(`{"name":"Bob","age":30}`),
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.UpdateUserRequest{Id: 12, Name: &name, Age: &age, Replace: true},
+				res: &entity.User{Id: 12, Name: "Bob", Age: 30},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"id":12,"name":"Bob","age":30}`,
+		},
+		{
+			name:               "PUT misses a field",
+			method:             http.MethodPut,
+			url:                "/postgres/users/12",
+			body:               []byte(`{"age":30}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: name must be provided",
+				entity.ProblemDetail{Field: "name", Reason: "must be provided"},
+			),
+		},
+		{
+			name:               "PATCH has nothing to update",
+			method:             http.MethodPatch,
+			url:                "/postgres/users/12",
+			body:               []byte(`{}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   problemBody(http.StatusBadRequest, problem.CodeInvalidArgument, "nothing to update"),
+		},
+		{
+			name:               "body is not a valid json",
+			method:             http.MethodPatch,
+			url:                "/postgres/users/12",
+			body:               []byte(`{"age":_`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: invalid character '_' looking for beginning of value",
+			),
+		},
+		{
+			name:               "id is not positive",
+			method:             http.MethodPatch,
+			url:                "/postgres/users/0",
+			body:               []byte(`{"age":30}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"id in the path must be a positive integer",
+			),
+		},
+		{
+			name:   "user not found",
+			method: http.MethodPatch,
+			url:    "/postgres/users/12",
+			body:   []byte(`{"age":30}`),
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.UpdateUserRequest{Id: 12, Age: &age},
+				err: fmt.Errorf("user 12: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "user 12: not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(tt.method, tt.url, bytes.NewReader(tt.body))
+			usersCtrlMock := mock_users.NewMockController(ctrl)
+			if tt.mockUserCtrl != nil {
+				usersCtrlMock.
+					EXPECT().
+					Update(httpreq.Context(), tt.mockUserCtrl.req).
+					Return(tt.mockUserCtrl.res, tt.mockUserCtrl.err)
+			}
+			h := newUsersTestHandler(ctrl, usersCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.UpdateUser).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_DeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockUserCtrl struct {
+		req *entity.DeleteUserRequest
+		res *entity.User
+		err error
+	}
+	tests := []struct {
+		name               string
+		url                string
+		mockUserCtrl       *mockUserCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "Happy path",
+			url:  "/postgres/users/12",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.DeleteUserRequest{Id: 12},
+				res: &entity.User{Id: 12, Name: "Alex", Age: 23},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"id":12,"name":"Alex","age":23}`,
+		},
+		{
+			name:               "id is missing",
+			url:                "/postgres/users/",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"id in the path must be a positive integer",
+			),
+		},
+		{
+			name: "user not found",
+			url:  "/postgres/users/12",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.DeleteUserRequest{Id: 12},
+				err: fmt.Errorf("user 12: %w", entity.ErrNotFound),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   problemBody(http.StatusNotFound, problem.CodeNotFound, "user 12: not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodDelete, tt.url, nil)
+			usersCtrlMock := mock_users.NewMockController(ctrl)
+			if tt.mockUserCtrl != nil {
+				usersCtrlMock.
+					EXPECT().
+					Delete(httpreq.Context(), tt.mockUserCtrl.req).
+					Return(tt.mockUserCtrl.res, tt.mockUserCtrl.err)
+			}
+			h := newUsersTestHandler(ctrl, usersCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.DeleteUser).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_BulkAddUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	alexAge, bobAge := 25, 30
+	type mockUserCtrl struct {
+		req *entity.BulkAddUsersRequest
+		res *entity.BulkAddUsersResponse
+		err error
+	}
+	tests := []struct {
+		name               string
+		url                string
+		contentType        string
+		body               string
+		mockUserCtrl       *mockUserCtrl
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "Happy path, JSON array",
+			url:  "/postgres/users/bulk?atomic=true",
+			body: `[{"name":"Alex","age":25},{"name":"Bob","age":30}]`,
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.BulkAddUsersRequest{
+					Users:  []entity.AddUserRequest{{Name: "Alex", Age: &alexAge}, {Name: "Bob", Age: &bobAge}},
+					Atomic: true,
+				},
+				res: &entity.BulkAddUsersResponse{Ids: []int64{1, 2}},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"ids":[1,2]}`,
+		},
+		{
+			name:        "Happy path, NDJSON",
+			url:         "/postgres/users/bulk",
+			contentType: "application/x-ndjson; charset=utf-8",
+			body:        "{\"name\":\"Alex\",\"age\":25}\n{\"name\":\"Bob\",\"age\":30}\n",
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.BulkAddUsersRequest{
+					Users: []entity.AddUserRequest{{Name: "Alex", Age: &alexAge}, {Name: "Bob", Age: &bobAge}},
+				},
+				res: &entity.BulkAddUsersResponse{
+					Ids:    []int64{0, 2},
+					Errors: []entity.BulkAddUsersError{{Offset: 0, Count: 1, Error: "some error"}},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"ids":[0,2],"errors":[{"offset":0,"count":1,"error":"some error"}]}`,
+		},
+		{
+			name:               "user is not valid",
+			url:                "/postgres/users/bulk",
+			body:               `[{"name":"Alex","age":25},{"name":"Bob","age":151}]`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: users[1].age must be between 0 and 150",
+				entity.ProblemDetail{Field: "users[1].age", Reason: "must be between 0 and 150"},
+			),
+		},
+		{
+			name:               "user has no age",
+			url:                "/postgres/users/bulk",
+			body:               `[{"name":"Alex","age":25},{"name":"Bob"}]`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"invalid request: users[1].age must be provided",
+				entity.ProblemDetail{Field: "users[1].age", Reason: "must be provided"},
+			),
+		},
+		{
+			name:               "NDJSON line has unknown fields",
+			url:                "/postgres/users/bulk",
+			contentType:        "application/x-ndjson",
+			body:               "{\"name\":\"Alex\"}\n{\"name\":\"Bob\",\"agee\":25}\n",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				`user 2: failed to unmarshal: json: unknown field "agee"`,
+			),
+		},
+		{
+			name:               "malformed NDJSON line",
+			url:                "/postgres/users/bulk",
+			contentType:        "application/x-ndjson",
+			body:               "{\"name\":\"Alex\"}\n{\"name\":\n",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal user 2: unexpected EOF",
+			),
+		},
+		{
+			name:               "malformed JSON array",
+			url:                "/postgres/users/bulk",
+			body:               `{"name":"Alex"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"failed to unmarshal: json: cannot unmarshal object into Go value of type []entity.AddUserRequest",
+			),
+		},
+		{
+			name:               "atomic is not a boolean",
+			url:                "/postgres/users/bulk?atomic=yes",
+			body:               `[{"name":"Alex"}]`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"atomic must be a boolean",
+			),
+		},
+		{
+			name: "controller rejects the request",
+			url:  "/postgres/users/bulk",
+			body: `[]`,
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.BulkAddUsersRequest{Users: []entity.AddUserRequest{}},
+				err: fmt.Errorf("users must not be empty: %w", entity.ErrInvalidArgument),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: problemBody(
+				http.StatusBadRequest,
+				problem.CodeInvalidArgument,
+				"users must not be empty: invalid argument",
+			),
+		},
+		{
+			name: "controller fails",
+			url:  "/postgres/users/bulk?atomic=1",
+			body: `[{"name":"Alex","age":25}]`,
+			mockUserCtrl: &mockUserCtrl{
+				req: &entity.BulkAddUsersRequest{Users: []entity.AddUserRequest{{Name: "Alex", Age: &alexAge}}, Atomic: true},
+				err: errors.New("some error"),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: problemBody(
+				http.StatusInternalServerError,
+				problem.CodeInternal,
+				"internal error, see the logs for the request id",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, tt.url, bytes.NewReader([]byte(tt.body)))
+			if tt.contentType != "" {
+				httpreq.Header.Set("Content-Type", tt.contentType)
+			}
+			usersCtrlMock := mock_users.NewMockController(ctrl)
+			if tt.mockUserCtrl != nil {
+				usersCtrlMock.
+					EXPECT().
+					BulkAdd(httpreq.Context(), tt.mockUserCtrl.req).
+					Return(tt.mockUserCtrl.res, tt.mockUserCtrl.err)
+			}
+			h := newUsersTestHandler(ctrl, usersCtrlMock)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.BulkAddUsers).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+func Test_handler_BulkAddUsers_BodyLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	body := []byte(`[{"name":"Alex","age":25}]`)
+	tests := []struct {
+		name               string
+		config             internalconfig.HandlerConfig
+		chunked            bool
+		expectedStatusCode int
+	}{
+		{
+			name:               "bulk limit overrides the request limit",
+			config:             internalconfig.HandlerConfig{RequestBodyLimit: 5, BulkRequestBodyLimit: 1024},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "bulk limit is exceeded",
+			config:             internalconfig.HandlerConfig{RequestBodyLimit: 1024, BulkRequestBodyLimit: 5},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "request limit is used if bulk limit is not configured",
+			config:             internalconfig.HandlerConfig{RequestBodyLimit: 5},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "chunked body within the limit",
+			config:             internalconfig.HandlerConfig{BulkRequestBodyLimit: int64(len(body))},
+			chunked:            true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "chunked body exceeds the limit",
+			config:             internalconfig.HandlerConfig{BulkRequestBodyLimit: 5},
+			chunked:            true,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpreq, _ := http.NewRequest(http.MethodPost, "/postgres/users/bulk", bytes.NewReader(body))
+			if tt.chunked {
+				// the length of the chunked body is unknown until it's read
+				httpreq.ContentLength = -1
+			}
+			usersCtrlMock := mock_users.NewMockController(ctrl)
+			if tt.expectedStatusCode == http.StatusOK {
+				usersCtrlMock.EXPECT().
+					BulkAdd(httpreq.Context(), gomock.Any()).
+					Return(&entity.BulkAddUsersResponse{Ids: []int64{1}}, nil)
+			}
+			h := newUsersTestHandler(ctrl, usersCtrlMock)
+			h.config = tt.config
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.BulkAddUsers).ServeHTTP(rr, httpreq)
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+		})
+	}
+}
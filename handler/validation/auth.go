@@ -0,0 +1,338 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/controller/ratelimit"
+	"redis-postgres-service/controller/sign"
+	"redis-postgres-service/controller/tokens"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/gateway/signer"
+	"redis-postgres-service/handler/problem"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	_authConfigKey = "auth"
+	_clientsKey    = "auth_clients"
+)
+
+// Authentication schemes of the Authorization header, API keys are provided in the X-Api-Key header
+const (
+	APIKeyHeader = "X-Api-Key"
+	BearerScheme = "Bearer"
+	HMACScheme   = "HMAC-SHA256"
+)
+
+// AuthRoute is the route the failed authentication attempts are rate limited on by the IP address
+const AuthRoute = "auth"
+
+// Scopes of the routes, the clients are allowed to call the routes with the scopes listed in their credentials
+const (
+	ScopeCountersRead  = "counters:read"
+	ScopeCountersWrite = "counters:write"
+	ScopeUsersRead     = "users:read"
+	ScopeUsersWrite    = "users:write"
+	ScopeSign          = "sign"
+	ScopeTokensIssue   = "tokens:issue"
+	ScopeTokensVerify  = "tokens:verify"
+)
+
+// TokenSubjectPrefix namespaces the ids of the bearer token subjects, the subjects are chosen by the token issuers,
+// so that they can't share the rate limits and the idempotency keys of the clients
+const TokenSubjectPrefix = "token:"
+
+// Principal is the authenticated caller of the endpoint. ID is the client id for the API keys and HMAC signed
+// requests and the subject of the bearer tokens prefixed by TokenSubjectPrefix, Method is the authentication scheme used.
+type Principal struct {
+	ID     string
+	Method string
+	Scopes []string
+}
+
+type principalKey struct{}
+
+var (
+	// ErrUnauthenticated is returned by Authenticator.Authorize when the caller can't be authenticated
+	ErrUnauthenticated = stderrors.New("unauthenticated")
+	// ErrForbidden is returned by Authenticator.Authorize when the caller doesn't have the scope
+	ErrForbidden = stderrors.New("forbidden")
+)
+
+// PrincipalFromContext returns the caller authenticated by the Authenticator middleware
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+type Authenticator interface {
+	Require(scope string) func(next http.Handler) http.Handler
+	Authorize(ctx context.Context, apiKey, authorization, scope string) (context.Context, error)
+}
+
+// compile time check that authenticator implements Authenticator interface
+var _ Authenticator = (*authenticator)(nil)
+
+// AuthParams is an fx container for all Authenticator dependencies
+type AuthParams struct {
+	fx.In
+
+	ConfigProvider config.Provider
+	SignCtrl       sign.Controller
+	TokensCtrl     tokens.Controller
+	RateLimitCtrl  ratelimit.Controller
+	Logger         *zap.Logger
+}
+
+// NewAuthenticator is a constructor provided to the fx for creating an Authenticator. Clients are loaded
+// from the `auth_clients` section of the secrets.
+func NewAuthenticator(p AuthParams) (Authenticator, error) {
+	var cfg internalconfig.AuthConfig
+	err := p.ConfigProvider.Get(_authConfigKey).Populate(&cfg)
+	if err != nil {
+		return nil, errors.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	var clients map[string]internalconfig.AuthClientSecrets
+	err = p.ConfigProvider.Get(_clientsKey).Populate(&clients)
+	if err != nil {
+		return nil, errors.Errorf("failed to populate secrets: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	apiKeys := make(map[string]string, len(clients))
+	for id, client := range clients {
+		if strings.HasPrefix(id, TokenSubjectPrefix) {
+			return nil, errors.Errorf("client id %s can't start with %s", id, TokenSubjectPrefix)
+		}
+		if client.APIKeySHA256 == "" {
+			continue
+		}
+		hash, err := hex.DecodeString(client.APIKeySHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, errors.Errorf("api_key_sha256 of the client %s must be a hex encoded sha256", id)
+		}
+		apiKeys[hex.EncodeToString(hash)] = id
+	}
+	return &authenticator{
+		logger:        p.Logger,
+		signCtrl:      p.SignCtrl,
+		tokensCtrl:    p.TokensCtrl,
+		rateLimitCtrl: p.RateLimitCtrl,
+		config:        cfg,
+		clients:       clients,
+		apiKeys:       apiKeys,
+		now:           time.Now,
+	}, nil
+}
+
+type authenticator struct {
+	logger        *zap.Logger
+	signCtrl      sign.Controller
+	tokensCtrl    tokens.Controller
+	rateLimitCtrl ratelimit.Controller
+	config        internalconfig.AuthConfig
+	clients       map[string]internalconfig.AuthClientSecrets
+	// apiKeys maps the lowercase hex sha256 of the API keys to the client ids
+	apiKeys map[string]string
+	now     func() time.Time
+}
+
+// Require is a middleware that authenticates the caller and checks that it has the scope. Callers are authenticated
+// 1. by the static API key in the X-Api-Key header
+// 2. by the bearer token issued by the /tokens/issue endpoint, scopes are read from the `scope` claim
+// 3. by the HMAC-SHA256 signature of the request made with the client's server-side signing key
+// Unauthenticated requests are rejected with 401 and the requests without the scope with 403.
+// The failed attempts are limited on AuthRoute by the IP address, so that the credentials can't be brute forced,
+// callers over the limit are rejected with 429 before the authentication. Every request passes through
+// if the authentication is disabled.
+func (a *authenticator) Require(scope string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !a.config.Enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := a.logger.With(
+				zap.String("scope", "validation"),
+				zap.String("function", "Require"),
+				zap.String("request_id", RequestIDFromContext(r.Context())),
+			).Sugar()
+			client := clientID(r)
+			res, err := a.rateLimitCtrl.Allow(r.Context(), &entity.RateLimitRequest{Route: AuthRoute, Client: client, Peek: true})
+			if err != nil {
+				logger.Errorf(entity.FailedToProcessTheRequest, err)
+			} else if !res.Allowed {
+				err = errors.Errorf("%s exceeded %d failed authentication attempts", client, res.Limit)
+				w.Header().Set(RetryAfterHeader, rateLimitHeaders(w, res))
+				problem.Error(w, err.Error(), http.StatusTooManyRequests)
+				logger.Errorf(entity.TooManyRequests, err)
+				return
+			}
+			principal, err := a.authenticate(r)
+			if err != nil {
+				// only the failed attempts are counted, so that the clients sharing the address aren't limited
+				if _, err := a.rateLimitCtrl.Allow(r.Context(), &entity.RateLimitRequest{Route: AuthRoute, Client: client}); err != nil {
+					logger.Errorf(entity.FailedToProcessTheRequest, err)
+				}
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("%s, %s", BearerScheme, HMACScheme))
+				problem.Error(w, err.Error(), http.StatusUnauthorized)
+				logger.Errorf(entity.Unauthorized, err)
+				return
+			}
+			if !hasScope(principal.Scopes, scope) {
+				err = errors.Errorf("client %s doesn't have %s scope", principal.ID, scope)
+				problem.Error(w, err.Error(), http.StatusForbidden)
+				logger.Errorf(entity.Forbidden, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+		})
+	}
+}
+
+// Authorize authenticates the caller of the non-HTTP transports, e.g. gRPC, by the API key or the value
+// of the Authorization header and checks that it has the scope. HMAC signed requests are not supported, cause
+// the signature covers the http request. The context is returned with the Principal, errors wrap ErrUnauthenticated
+// and ErrForbidden. The context is returned as is if the authentication is disabled.
+func (a *authenticator) Authorize(ctx context.Context, apiKey, authorization, scope string) (context.Context, error) {
+	if !a.config.Enabled {
+		return ctx, nil
+	}
+	principal, err := a.credentials(ctx, apiKey, authorization)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err)
+	}
+	if !hasScope(principal.Scopes, scope) {
+		return nil, fmt.Errorf("%w: client %s doesn't have %s scope", ErrForbidden, principal.ID, scope)
+	}
+	return context.WithValue(ctx, principalKey{}, principal), nil
+}
+
+// authenticate returns the caller of the request by the credentials it provides
+func (a *authenticator) authenticate(r *http.Request) (*Principal, error) {
+	apiKey, authorization := r.Header.Get(APIKeyHeader), r.Header.Get("Authorization")
+	if scheme, credentials, _ := strings.Cut(authorization, " "); apiKey == "" && strings.EqualFold(scheme, HMACScheme) {
+		return a.hmac(r, credentials)
+	}
+	return a.credentials(r.Context(), apiKey, authorization)
+}
+
+// credentials returns the caller by the API key or the bearer token of the Authorization header value
+func (a *authenticator) credentials(ctx context.Context, apiKey, authorization string) (*Principal, error) {
+	if apiKey != "" {
+		return a.apiKey(apiKey)
+	}
+	scheme, credentials, _ := strings.Cut(authorization, " ")
+	switch {
+	case strings.EqualFold(scheme, BearerScheme):
+		return a.bearer(ctx, strings.TrimSpace(credentials))
+	case scheme == "":
+		return nil, errors.New("credentials are missing")
+	default:
+		return nil, errors.Errorf("unsupported authorization scheme %s", scheme)
+	}
+}
+
+// apiKey returns the client with the API key, keys are compared by their sha256, so that only the hashes are stored
+func (a *authenticator) apiKey(apiKey string) (*Principal, error) {
+	hash := sha256.Sum256([]byte(apiKey))
+	id, ok := a.apiKeys[hex.EncodeToString(hash[:])]
+	if !ok {
+		return nil, errors.New("unknown api key")
+	}
+	return &Principal{ID: id, Method: "api_key", Scopes: a.clients[id].Scopes}, nil
+}
+
+// bearer returns the subject of the valid bearer token with the scopes from its space separated `scope` claim.
+// The token must be issued for the configured audience, so that the tokens issued for other consumers
+// by the same keys are not accepted.
+func (a *authenticator) bearer(ctx context.Context, token string) (*Principal, error) {
+	verified, err := a.tokensCtrl.Verify(ctx, &entity.VerifyTokenRequest{Token: token, Audience: a.config.Audience})
+	if err != nil {
+		return nil, errors.Errorf("invalid bearer token: %s", err)
+	}
+	if !verified.Valid {
+		return nil, errors.Errorf("invalid bearer token: %s", verified.Reason)
+	}
+	if _, ok := verified.Claims["aud"]; !ok {
+		return nil, errors.New("invalid bearer token: token is not issued for the audience")
+	}
+	subject, _ := verified.Claims["sub"].(string)
+	scopes, _ := verified.Claims["scope"].(string)
+	return &Principal{ID: TokenSubjectPrefix + subject, Method: "bearer", Scopes: strings.Fields(scopes)}, nil
+}
+
+// hmac returns the client that signed the request with its server-side signing key. Credentials are provided
+// as `client=<id>,timestamp=<unix seconds>,signature=<hex>`, the signature is made over the string returned
+// by StringToSign. Timestamps outside of the allowed clock skew are rejected to limit the replays.
+func (a *authenticator) hmac(r *http.Request, credentials string) (*Principal, error) {
+	params := make(map[string]string, 3)
+	for _, param := range strings.Split(credentials, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		params[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	id := params["client"]
+	client, ok := a.clients[id]
+	if !ok || client.SigningKeyID == "" {
+		return nil, errors.Errorf("unknown client %s", id)
+	}
+	timestamp, err := strconv.ParseInt(params["timestamp"], 10, 64)
+	if err != nil {
+		return nil, errors.New("timestamp must be unix seconds")
+	}
+	if skew := a.now().Unix() - timestamp; skew > a.config.MaxClockSkew || -skew > a.config.MaxClockSkew {
+		return nil, errors.Errorf("timestamp is outside of the allowed %d seconds skew", a.config.MaxClockSkew)
+	}
+	if r.ContentLength > a.config.SignedBodyLimit {
+		return nil, errors.New(entity.RequestBodyIsTooBig)
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, a.config.SignedBodyLimit+1))
+	if err != nil {
+		return nil, errors.New(entity.UnableToReadTheBody)
+	}
+	if int64(len(body)) > a.config.SignedBodyLimit {
+		return nil, errors.New(entity.RequestBodyIsTooBig)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	verified, err := a.signCtrl.Verify(r.Context(), &entity.VerifyRequest{
+		Algorithm: signer.HMACSHA256,
+		Text:      StringToSign(r.Method, r.URL.RequestURI(), timestamp, body),
+		KeyID:     client.SigningKeyID,
+		Signature: params["signature"],
+		Encoding:  signer.EncodingHex,
+		Internal:  true,
+	})
+	if err != nil {
+		return nil, errors.Errorf("invalid signature: %s", err)
+	}
+	if !verified.Valid {
+		return nil, errors.New("signature doesn't match")
+	}
+	return &Principal{ID: id, Method: "hmac", Scopes: client.Scopes}, nil
+}
+
+// StringToSign returns the string the HMAC signed requests are signed over: the method, the request URI,
+// the timestamp and the hex sha256 of the body separated by new lines
+func StringToSign(method, requestURI string, timestamp int64, body []byte) string {
+	hash := sha256.Sum256(body)
+	return fmt.Sprintf("%s\n%s\n%d\n%s", method, requestURI, timestamp, hex.EncodeToString(hash[:]))
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/controller/idempotency"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+)
+
+const _idempotencyConfigKey = "idempotency"
+
+// Headers of the idempotent requests, replayed responses are marked by the Idempotent-Replayed header
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// _maxIdempotencyKeyLength limits the length of the idempotency keys stored in Redis
+const _maxIdempotencyKeyLength = 255
+
+type Idempotency interface {
+	Idempotent(bodyLimit int64) func(next http.Handler) http.Handler
+}
+
+// compile time check that idempotencyGuard implements Idempotency interface
+var _ Idempotency = (*idempotencyGuard)(nil)
+
+// IdempotencyParams is an fx container for all Idempotency dependencies
+type IdempotencyParams struct {
+	fx.In
+
+	ConfigProvider  config.Provider
+	IdempotencyCtrl idempotency.Controller
+	Logger          *zap.Logger
+}
+
+// NewIdempotency is a constructor provided to the fx for creating an Idempotency
+func NewIdempotency(p IdempotencyParams) (Idempotency, error) {
+	var cfg internalconfig.IdempotencyConfig
+	err := p.ConfigProvider.Get(_idempotencyConfigKey).Populate(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	return &idempotencyGuard{
+		logger:          p.Logger,
+		idempotencyCtrl: p.IdempotencyCtrl,
+		config:          cfg,
+	}, nil
+}
+
+type idempotencyGuard struct {
+	logger          *zap.Logger
+	idempotencyCtrl idempotency.Controller
+	config          internalconfig.IdempotencyConfig
+}
+
+// Idempotent is a middleware that processes the requests with the Idempotency-Key header at most once. The first
+// response for the key is stored and replayed on the retries, the retries made while the first request is in flight
+// are rejected with 409 and the reuse of the key for another method, URI or body with 422. Bodies of the requests
+// with the key are buffered up to the bodyLimit of the endpoint to be hashed, bigger bodies are rejected with 400.
+// Server errors and the responses bigger than the configured limit are not stored, so that the request can be retried.
+// Keys are scoped by the clients, so the middleware must be wrapped by the Authenticator. Safe methods and
+// the requests without the key pass through.
+func (i *idempotencyGuard) Idempotent(bodyLimit int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !i.config.Enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || isSafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			logger := i.logger.With(
+				zap.String("scope", "validation"),
+				zap.String("function", "Idempotent"),
+				zap.String("request_id", RequestIDFromContext(r.Context())),
+			).Sugar()
+			if len(key) > _maxIdempotencyKeyLength {
+				err := fmt.Errorf("%s must not be longer than %d characters", IdempotencyKeyHeader, _maxIdempotencyKeyLength)
+				problem.Error(w, err.Error(), http.StatusBadRequest)
+				logger.Errorf(entity.BadRequest, err)
+				return
+			}
+			body, err := bufferBody(r, bodyLimit)
+			if err != nil {
+				problem.Error(w, err.Error(), http.StatusBadRequest)
+				logger.Errorf(entity.BadRequest, err)
+				return
+			}
+			sum := sha256.Sum256(body)
+			req := &entity.IdempotencyRequest{
+				Client:      clientID(r),
+				Key:         key,
+				Fingerprint: r.Method + " " + r.URL.RequestURI() + " " + hex.EncodeToString(sum[:]),
+			}
+			res, err := i.idempotencyCtrl.Begin(r.Context(), req)
+			if err != nil {
+				message, status := idempotencyError(err)
+				problem.Error(w, entity.PublicMessage(err), status)
+				logger.Error(message)
+				return
+			}
+			if res.Replay != nil {
+				replay(w, res.Replay)
+				return
+			}
+			completed := false
+			defer func() {
+				// the key is released even if the handler panics, the request context may be already canceled
+				if !completed {
+					if err := i.idempotencyCtrl.Release(context.Background(), req); err != nil {
+						logger.Errorf(entity.FailedToProcessTheRequest, err)
+					}
+				}
+			}()
+			recorder := newResponseRecorder(w, i.config.MaxResponseSize)
+			next.ServeHTTP(recorder, r)
+			if recorder.status >= http.StatusInternalServerError || recorder.truncated {
+				return
+			}
+			err = i.idempotencyCtrl.Complete(context.Background(), req, recorder.response())
+			if err != nil {
+				logger.Errorf(entity.FailedToProcessTheResponse, err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// bufferBody reads the request body up to the limit and replaces it with the buffered copy, so that the handler
+// reads the same body
+func bufferBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.ContentLength > limit {
+		return nil, errors.New(entity.RequestBodyIsTooBig)
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, errors.New(entity.UnableToReadTheBody)
+	}
+	if int64(len(body)) > limit {
+		return nil, errors.New(entity.RequestBodyIsTooBig)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// idempotencyError maps the errors of the idempotency controller into the log message and the http status
+func idempotencyError(err error) (string, int) {
+	if errors.Is(err, entity.ErrConflict) {
+		return fmt.Sprintf(entity.Conflict, err), http.StatusConflict
+	}
+	if errors.Is(err, entity.ErrInvalidArgument) {
+		return fmt.Sprintf(entity.UnprocessableRequest, err), http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, entity.ErrUnavailable) {
+		return fmt.Sprintf(entity.ServiceUnavailable, err), http.StatusServiceUnavailable
+	}
+	return fmt.Sprintf(entity.FailedToProcessTheRequest, err), http.StatusInternalServerError
+}
+
+// replay writes the stored response
+func replay(w http.ResponseWriter, response *entity.StoredResponse) {
+	for name, values := range response.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(response.Status)
+	_, _ = w.Write(response.Body)
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// responseRecorder writes the response through and keeps its copy up to the limit, headers set before the handler
+// is called, e.g. by the rate limiter, are not recorded
+type responseRecorder struct {
+	http.ResponseWriter
+	preset    map[string]bool
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func newResponseRecorder(w http.ResponseWriter, limit int64) *responseRecorder {
+	preset := make(map[string]bool, len(w.Header()))
+	for name := range w.Header() {
+		preset[name] = true
+	}
+	return &responseRecorder{ResponseWriter: w, preset: preset, limit: limit}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.header = make(http.Header)
+		for name, values := range r.ResponseWriter.Header() {
+			if !r.preset[name] {
+				r.header[name] = append([]string(nil), values...)
+			}
+		}
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	if int64(r.body.Len()+len(data)) > r.limit {
+		r.truncated = true
+	} else if !r.truncated {
+		r.body.Write(data)
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// response returns the recorded response, the response without the body and the status is 200 OK
+func (r *responseRecorder) response() *entity.StoredResponse {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	return &entity.StoredResponse{Status: r.status, Header: r.header, Body: r.body.Bytes()}
+}
@@ -0,0 +1,290 @@
+package validation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	mock_idempotency "redis-postgres-service/mocks/controller/idempotency"
+	"strings"
+	"testing"
+)
+
+func TestNewIdempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	provider, _ := config.NewYAML(config.Source(strings.NewReader(`{"idempotency":{"enabled":true}}`)))
+	i, err := NewIdempotency(IdempotencyParams{
+		ConfigProvider:  provider,
+		IdempotencyCtrl: mock_idempotency.NewMockController(ctrl),
+		Logger:          zap.NewNop(),
+	})
+	assert.NotNil(t, i)
+	assert.NoError(t, err)
+}
+
+func Test_idempotencyGuard_Idempotent(t *testing.T) {
+	type mockController struct {
+		begin       *entity.IdempotencyResponse
+		beginErr    error
+		complete    *entity.StoredResponse
+		completeErr error
+		release     bool
+	}
+	tests := []struct {
+		name           string
+		disabled       bool
+		method         string
+		key            string
+		body           string
+		chunked        bool
+		handlerStatus  int
+		handlerBody    string
+		mockController *mockController
+		wantStatus     int
+		wantBody       string
+		wantCalls      int
+		wantReplayed   string
+	}{
+		{
+			name:          "Happy path, response is stored",
+			method:        http.MethodPost,
+			key:           "some-key",
+			handlerStatus: http.StatusCreated,
+			handlerBody:   `{"value":1}`,
+			mockController: &mockController{
+				begin: &entity.IdempotencyResponse{},
+				complete: &entity.StoredResponse{
+					Status: http.StatusCreated,
+					Header: map[string][]string{"Content-Type": {"application/json"}},
+					Body:   []byte(`{"value":1}`),
+				},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"value":1}`,
+			wantCalls:  1,
+		},
+		{
+			name:   "Stored response is replayed",
+			method: http.MethodPost,
+			key:    "some-key",
+			mockController: &mockController{
+				begin: &entity.IdempotencyResponse{Replay: &entity.StoredResponse{
+					Status: http.StatusCreated,
+					Header: map[string][]string{"Content-Type": {"application/json"}},
+					Body:   []byte(`{"value":1}`),
+				}},
+			},
+			wantStatus:   http.StatusCreated,
+			wantBody:     `{"value":1}`,
+			wantReplayed: "true",
+		},
+		{
+			name:   "Request is in flight",
+			method: http.MethodPost,
+			key:    "some-key",
+			mockController: &mockController{
+				beginErr: &entity.PublicError{
+					Message: "request with the idempotency key is in flight, retry later",
+					Err:     fmt.Errorf("request with idempotency key some-key is in flight: %w", entity.ErrConflict),
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody: problemBody(
+				http.StatusConflict,
+				problem.CodeConflict,
+				"request with the idempotency key is in flight, retry later",
+			),
+		},
+		{
+			name:   "Key is used for another request",
+			method: http.MethodPost,
+			key:    "some-key",
+			mockController: &mockController{
+				beginErr: &entity.PublicError{
+					Message: "idempotency key is used for another request",
+					Err:     fmt.Errorf("idempotency key some-key is used for another request: %w", entity.ErrInvalidArgument),
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: problemBody(
+				http.StatusUnprocessableEntity,
+				problem.CodeUnprocessable,
+				"idempotency key is used for another request",
+			),
+		},
+		{
+			name:           "Controller fails",
+			method:         http.MethodPost,
+			key:            "some-key",
+			mockController: &mockController{beginErr: errors.New("some error")},
+			wantStatus:     http.StatusInternalServerError,
+		},
+		{
+			name:           "Redis is unavailable",
+			method:         http.MethodPost,
+			key:            "some-key",
+			mockController: &mockController{beginErr: entity.ErrUnavailable},
+			wantStatus:     http.StatusServiceUnavailable,
+		},
+		{
+			name:           "Server error is not stored",
+			method:         http.MethodPost,
+			key:            "some-key",
+			handlerStatus:  http.StatusBadGateway,
+			mockController: &mockController{begin: &entity.IdempotencyResponse{}, release: true},
+			wantStatus:     http.StatusBadGateway,
+			wantCalls:      1,
+		},
+		{
+			name:           "Response is too big to be stored",
+			method:         http.MethodPost,
+			key:            "some-key",
+			handlerStatus:  http.StatusOK,
+			handlerBody:    strings.Repeat("a", 65),
+			mockController: &mockController{begin: &entity.IdempotencyResponse{}, release: true},
+			wantStatus:     http.StatusOK,
+			wantBody:       strings.Repeat("a", 65),
+			wantCalls:      1,
+		},
+		{
+			name:          "Response can't be stored",
+			method:        http.MethodPost,
+			key:           "some-key",
+			handlerStatus: http.StatusOK,
+			mockController: &mockController{
+				begin:       &entity.IdempotencyResponse{},
+				complete:    &entity.StoredResponse{Status: http.StatusOK, Header: map[string][]string{}},
+				completeErr: errors.New("some error"),
+				release:     true,
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name:       "Key is too long",
+			method:     http.MethodPost,
+			key:        strings.Repeat("k", 256),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "Body is hashed into the fingerprint and passed to the handler",
+			method:        http.MethodPost,
+			key:           "some-key",
+			body:          `{"key":"visits"}`,
+			handlerStatus: http.StatusOK,
+			mockController: &mockController{
+				begin:    &entity.IdempotencyResponse{},
+				complete: &entity.StoredResponse{Status: http.StatusOK, Header: map[string][]string{}},
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name:       "Body is too big",
+			method:     http.MethodPost,
+			key:        "some-key",
+			body:       strings.Repeat("b", 33),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Chunked body is too big",
+			method:     http.MethodPost,
+			key:        "some-key",
+			body:       strings.Repeat("b", 33),
+			chunked:    true,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "Request without the key passes through",
+			method:        http.MethodPost,
+			handlerStatus: http.StatusOK,
+			wantStatus:    http.StatusOK,
+			wantCalls:     1,
+		},
+		{
+			name:          "Safe method passes through",
+			method:        http.MethodGet,
+			key:           "some-key",
+			handlerStatus: http.StatusOK,
+			wantStatus:    http.StatusOK,
+			wantCalls:     1,
+		},
+		{
+			name:          "Idempotency is disabled",
+			disabled:      true,
+			method:        http.MethodPost,
+			key:           "some-key",
+			handlerStatus: http.StatusOK,
+			wantStatus:    http.StatusOK,
+			wantCalls:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			sum := sha256.Sum256([]byte(tt.body))
+			idempotencyReq := &entity.IdempotencyRequest{
+				Client:      "ip:192.0.2.1",
+				Key:         "some-key",
+				Fingerprint: "POST /redis/incr " + hex.EncodeToString(sum[:]),
+			}
+			idempotencyCtrl := mock_idempotency.NewMockController(ctrl)
+			if tt.mockController != nil {
+				idempotencyCtrl.EXPECT().Begin(gomock.Any(), idempotencyReq).
+					Return(tt.mockController.begin, tt.mockController.beginErr)
+				if tt.mockController.complete != nil {
+					idempotencyCtrl.EXPECT().Complete(gomock.Any(), idempotencyReq, tt.mockController.complete).
+						Return(tt.mockController.completeErr)
+				}
+				if tt.mockController.release {
+					idempotencyCtrl.EXPECT().Release(gomock.Any(), idempotencyReq).Return(nil)
+				}
+			}
+			i := &idempotencyGuard{
+				logger:          zap.NewNop(),
+				idempotencyCtrl: idempotencyCtrl,
+				config:          internalconfig.IdempotencyConfig{Enabled: !tt.disabled, MaxResponseSize: 64},
+			}
+			calls := 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, tt.body, string(body))
+				if tt.handlerBody != "" && strings.HasPrefix(tt.handlerBody, "{") {
+					w.Header().Set("Content-Type", "application/json")
+				}
+				w.WriteHeader(tt.handlerStatus)
+				_, _ = w.Write([]byte(tt.handlerBody))
+			})
+			req := httptest.NewRequest(tt.method, "/redis/incr", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			// headers set by the outer middlewares are not stored
+			w.Header().Set(RateLimitLimitHeader, "10")
+			i.Idempotent(32)(next).ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Equal(t, tt.wantReplayed, w.Header().Get(IdempotentReplayedHeader))
+		})
+	}
+}
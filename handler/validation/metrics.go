@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"net/http"
+	"redis-postgres-service/metrics"
+	"strconv"
+	"time"
+)
+
+// _methods are the http methods reported as is, the rest are reported as OTHER to bound the label values
+var _methods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+type Instrumenter interface {
+	Instrument(route string) func(next http.Handler) http.Handler
+}
+
+// compile time check that instrumenter implements Instrumenter interface
+var _ Instrumenter = (*instrumenter)(nil)
+
+// InstrumenterParams is an fx container for all Instrumenter dependencies
+type InstrumenterParams struct {
+	fx.In
+
+	Metrics metrics.Registry
+}
+
+// NewInstrumenter is a constructor provided to the fx for creating an Instrumenter
+func NewInstrumenter(p InstrumenterParams) (Instrumenter, error) {
+	labels := []string{"route", "method", "status"}
+	i := &instrumenter{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of the handled http requests by route, method and status.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of the handled http requests by route, method and status.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+	}
+	for _, c := range []prometheus.Collector{i.requests, i.duration} {
+		if err := p.Metrics.Register(c); err != nil {
+			return nil, errors.Errorf("failed to register metrics: %s", err)
+		}
+	}
+	return i, nil
+}
+
+type instrumenter struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// Instrument is a middleware that counts the requests to the route and observes their latency by the method
+// and the response status. Route is the pattern of the endpoint rather than the request path, so that the number
+// of the series doesn't depend on the path params.
+func (i *instrumenter) Instrument(route string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r)
+			method := r.Method
+			if !_methods[method] {
+				method = "OTHER"
+			}
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			status := strconv.Itoa(recorder.status)
+			i.requests.WithLabelValues(route, method, status).Inc()
+			i.duration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// statusRecorder writes the response through and keeps its status
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,79 @@
+package validation
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewInstrumenter(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	i, err := NewInstrumenter(InstrumenterParams{Metrics: registry})
+	assert.NotNil(t, i)
+	assert.NoError(t, err)
+	// the same metrics can't be registered twice
+	_, err = NewInstrumenter(InstrumenterParams{Metrics: registry})
+	assert.Error(t, err)
+}
+
+func Test_instrumenter_Instrument(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		handler     http.HandlerFunc
+		wantMetrics string
+	}{
+		{
+			name:   "Happy path. Status is 200 if the handler doesn't set it",
+			method: http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			},
+			wantMetrics: `
+				# HELP http_requests_total Number of the handled http requests by route, method and status.
+				# TYPE http_requests_total counter
+				http_requests_total{method="GET",route="/postgres/users/",status="200"} 1
+			`,
+		},
+		{
+			name:   "Status set by the handler",
+			method: http.MethodDelete,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantMetrics: `
+				# HELP http_requests_total Number of the handled http requests by route, method and status.
+				# TYPE http_requests_total counter
+				http_requests_total{method="DELETE",route="/postgres/users/",status="404"} 1
+			`,
+		},
+		{
+			name:    "Nonstandard method",
+			method:  "PROPFIND",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			wantMetrics: `
+				# HELP http_requests_total Number of the handled http requests by route, method and status.
+				# TYPE http_requests_total counter
+				http_requests_total{method="OTHER",route="/postgres/users/",status="200"} 1
+			`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			i, err := NewInstrumenter(InstrumenterParams{Metrics: registry})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			i.Instrument("/postgres/users/")(tt.handler).ServeHTTP(w, httptest.NewRequest(tt.method, "/postgres/users/1", nil))
+			assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(tt.wantMetrics), "http_requests_total"))
+			assert.Equal(t, 1, testutil.CollectAndCount(registry, "http_request_duration_seconds"))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package validation
+
+import (
+	"github.com/pkg/errors"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"redis-postgres-service/controller/ratelimit"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
+	"strconv"
+	"time"
+)
+
+// Headers of the rate limited responses, Reset is the number of seconds until the next request is allowed
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+type RateLimiter interface {
+	Limit(route string) func(next http.Handler) http.Handler
+}
+
+// compile time check that rateLimiter implements RateLimiter interface
+var _ RateLimiter = (*rateLimiter)(nil)
+
+// RateLimiterParams is an fx container for all RateLimiter dependencies
+type RateLimiterParams struct {
+	fx.In
+
+	RateLimitCtrl ratelimit.Controller
+	Logger        *zap.Logger
+}
+
+// NewRateLimiter is a constructor provided to the fx for creating a RateLimiter
+func NewRateLimiter(p RateLimiterParams) (RateLimiter, error) {
+	return &rateLimiter{
+		logger:        p.Logger,
+		rateLimitCtrl: p.RateLimitCtrl,
+	}, nil
+}
+
+type rateLimiter struct {
+	logger        *zap.Logger
+	rateLimitCtrl ratelimit.Controller
+}
+
+// Limit is a middleware that limits the rate of the requests of every client to the route. Clients are identified
+// by the principal authenticated by the Authenticator, so it must wrap the limiter, or by the IP address otherwise.
+// Requests over the limit are rejected with 429, the limiter fails open if the counters can't be reached,
+// so that Redis outage doesn't take the whole service down.
+func (l *rateLimiter) Limit(route string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := l.logger.With(
+				zap.String("scope", "validation"),
+				zap.String("function", "Limit"),
+				zap.String("request_id", RequestIDFromContext(r.Context())),
+			).Sugar()
+			client := clientID(r)
+			res, err := l.rateLimitCtrl.Allow(r.Context(), &entity.RateLimitRequest{Route: route, Client: client})
+			if err != nil {
+				logger.Errorf(entity.FailedToProcessTheRequest, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if res.Limit == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			reset := rateLimitHeaders(w, res)
+			if !res.Allowed {
+				err = errors.Errorf("%s exceeded %d requests to %s", client, res.Limit, route)
+				w.Header().Set(RetryAfterHeader, reset)
+				problem.Error(w, err.Error(), http.StatusTooManyRequests)
+				logger.Errorf(entity.TooManyRequests, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitHeaders sets the rate limit headers of the limited route and returns the seconds until the reset
+func rateLimitHeaders(w http.ResponseWriter, res *entity.RateLimitResponse) string {
+	reset := strconv.FormatInt(seconds(res.Reset), 10)
+	w.Header().Set(RateLimitLimitHeader, strconv.FormatInt(res.Limit, 10))
+	w.Header().Set(RateLimitRemainingHeader, strconv.FormatInt(res.Remaining, 10))
+	w.Header().Set(RateLimitResetHeader, reset)
+	return reset
+}
+
+// clientID returns the authenticated principal of the request or its remote IP address
+func clientID(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		return "principal:" + principal.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds rounds the duration up to the whole seconds
+func seconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"redis-postgres-service/entity"
+	mock_ratelimit "redis-postgres-service/mocks/controller/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	l, err := NewRateLimiter(RateLimiterParams{
+		RateLimitCtrl: mock_ratelimit.NewMockController(ctrl),
+		Logger:        zap.NewNop(),
+	})
+	assert.NotNil(t, l)
+	assert.NoError(t, err)
+}
+
+func Test_rateLimiter_Limit(t *testing.T) {
+	type mockController struct {
+		req *entity.RateLimitRequest
+		res *entity.RateLimitResponse
+		err error
+	}
+	tests := []struct {
+		name           string
+		principal      *Principal
+		mockController mockController
+		wantStatus     int
+		wantHeaders    map[string]string
+	}{
+		{
+			name: "Happy path, client is identified by the IP address",
+			mockController: mockController{
+				req: &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:192.0.2.1"},
+				res: &entity.RateLimitResponse{Allowed: true, Limit: 10, Remaining: 9, Reset: 59500 * time.Millisecond},
+			},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				RateLimitLimitHeader:     "10",
+				RateLimitRemainingHeader: "9",
+				RateLimitResetHeader:     "60",
+				RetryAfterHeader:         "",
+			},
+		},
+		{
+			name:      "Happy path, client is identified by the principal",
+			principal: &Principal{ID: "billing"},
+			mockController: mockController{
+				req: &entity.RateLimitRequest{Route: "/redis/incr", Client: "principal:billing"},
+				res: &entity.RateLimitResponse{Allowed: true, Limit: 10, Remaining: 0, Reset: time.Second},
+			},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				RateLimitLimitHeader:     "10",
+				RateLimitRemainingHeader: "0",
+				RateLimitResetHeader:     "1",
+			},
+		},
+		{
+			name: "Limit is exceeded",
+			mockController: mockController{
+				req: &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:192.0.2.1"},
+				res: &entity.RateLimitResponse{Allowed: false, Limit: 10, Remaining: 0, Reset: 1500 * time.Millisecond},
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				RateLimitLimitHeader:     "10",
+				RateLimitRemainingHeader: "0",
+				RateLimitResetHeader:     "2",
+				RetryAfterHeader:         "2",
+			},
+		},
+		{
+			name: "Route is not limited",
+			mockController: mockController{
+				req: &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:192.0.2.1"},
+				res: &entity.RateLimitResponse{Allowed: true},
+			},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				RateLimitLimitHeader: "",
+			},
+		},
+		{
+			name: "Controller fails, request passes through",
+			mockController: mockController{
+				req: &entity.RateLimitRequest{Route: "/redis/incr", Client: "ip:192.0.2.1"},
+				err: errors.New("some error"),
+			},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				RateLimitLimitHeader: "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			rateLimitCtrl := mock_ratelimit.NewMockController(ctrl)
+			rateLimitCtrl.EXPECT().
+				Allow(gomock.Any(), tt.mockController.req).
+				Return(tt.mockController.res, tt.mockController.err)
+			l := &rateLimiter{
+				logger:        zap.NewNop(),
+				rateLimitCtrl: rateLimitCtrl,
+			}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodPost, "/redis/incr", nil)
+			if tt.principal != nil {
+				req = req.WithContext(context.WithValue(req.Context(), principalKey{}, tt.principal))
+			}
+			w := httptest.NewRecorder()
+			l.Limit("/redis/incr")(next).ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			for header, want := range tt.wantHeaders {
+				assert.Equal(t, want, w.Header().Get(header), header)
+			}
+		})
+	}
+}
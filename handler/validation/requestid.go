@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"redis-postgres-service/handler/problem"
+	"regexp"
+)
+
+// _requestIDRegexp matches the request ids accepted from the callers
+var _requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the id assigned to the request by the RequestID middleware
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID is a middleware that assigns the id to the request, so that the problems returned to the clients can be
+// correlated with the logs. The id provided by the caller in the X-Request-Id header is kept, if it is valid,
+// to trace the request across the services. The id is set as the response header and added to the request context.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, id := WithRequestID(r.Context(), r.Header.Get(problem.RequestIDHeader))
+		w.Header().Set(problem.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WithRequestID adds the request id provided by the caller to the context, the new id is generated
+// if the provided one is not valid. It is used by the transports that can't use the RequestID middleware.
+func WithRequestID(ctx context.Context, id string) (context.Context, string) {
+	if !_requestIDRegexp.MatchString(id) {
+		id = newRequestID()
+	}
+	return context.WithValue(ctx, requestIDKey{}, id), id
+}
+
+// newRequestID returns the random request id
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id) // never fails on the supported platforms
+	return hex.EncodeToString(id)
+}
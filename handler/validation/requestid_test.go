@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"redis-postgres-service/handler/problem"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		provided string
+		wantKept bool
+	}{
+		{
+			name:     "Happy path, provided id is kept",
+			provided: "3f2a9c1e-7b4d-4e8a-9f60-2d1c5b7a8e93",
+			wantKept: true,
+		},
+		{
+			name: "Id is generated if it is missing",
+		},
+		{
+			name:     "Invalid id is replaced",
+			provided: "some id\nwith new line",
+		},
+		{
+			name:     "Too long id is replaced",
+			provided: strings.Repeat("a", 129),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = RequestIDFromContext(r.Context())
+			})
+			req := httptest.NewRequest(http.MethodGet, "/redis/incr/Alex", nil)
+			if tt.provided != "" {
+				req.Header.Set(problem.RequestIDHeader, tt.provided)
+			}
+			w := httptest.NewRecorder()
+			RequestID(next).ServeHTTP(w, req)
+			assert.Equal(t, got, w.Header().Get(problem.RequestIDHeader))
+			if tt.wantKept {
+				assert.Equal(t, tt.provided, got)
+			} else {
+				assert.Len(t, got, 32)
+			}
+		})
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	ctx, id := WithRequestID(context.Background(), "3f2a9c1e")
+	assert.Equal(t, "3f2a9c1e", id)
+	assert.Equal(t, id, RequestIDFromContext(ctx))
+	ctx, id = WithRequestID(context.Background(), "")
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, RequestIDFromContext(ctx))
+}
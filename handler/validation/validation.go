@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"redis-postgres-service/entity"
+	"redis-postgres-service/handler/problem"
 )
 
 const (
@@ -16,7 +17,7 @@ func httpMethodCheckBuilder(method string) func(next http.Handler) http.Handler
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != method {
-				http.Error(w, fmt.Sprintf(entity.MethodNotAllowed, r.Method), http.StatusMethodNotAllowed)
+				problem.Error(w, fmt.Sprintf(entity.MethodNotAllowed, r.Method), http.StatusMethodNotAllowed)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -26,11 +27,26 @@ func httpMethodCheckBuilder(method string) func(next http.Handler) http.Handler
 
 var HttpPostCheck = httpMethodCheckBuilder(http.MethodPost)
 
+var HttpGetCheck = httpMethodCheckBuilder(http.MethodGet)
+
 // NotNilRequest is a middleware that blocks nil requests from going through
 func NotNilRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r == nil {
-			http.Error(w, fmt.Sprintf(entity.BadRequest, nilRequest), http.StatusBadRequest)
+			problem.Error(w, nilRequest, http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MethodRouter is a helper to build a handler that dispatches the incoming request to the handler
+// registered for its http method. Requests with other methods are rejected.
+func MethodRouter(handlers map[string]http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next, ok := handlers[r.Method]
+		if !ok {
+			problem.Error(w, fmt.Sprintf(entity.MethodNotAllowed, r.Method), http.StatusMethodNotAllowed)
 			return
 		}
 		next.ServeHTTP(w, r)
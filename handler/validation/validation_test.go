@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -108,3 +109,58 @@ func TestNotNilRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestMethodRouter(t *testing.T) {
+	tests := []struct {
+		name               string
+		calledHttpMethod   string
+		expectedGetCalls   int
+		expectedPostCalls  int
+		expectedHttpStatus int
+	}{
+		{
+			name:               "GET is routed",
+			calledHttpMethod:   http.MethodGet,
+			expectedGetCalls:   1,
+			expectedHttpStatus: http.StatusOK,
+		},
+		{
+			name:               "POST is routed",
+			calledHttpMethod:   http.MethodPost,
+			expectedPostCalls:  1,
+			expectedHttpStatus: http.StatusOK,
+		},
+		{
+			name:               "DELETE is not allowed",
+			calledHttpMethod:   http.MethodDelete,
+			expectedHttpStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var getCalls, postCalls int
+			router := MethodRouter(map[string]http.Handler{
+				http.MethodGet: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					getCalls++
+				}),
+				http.MethodPost: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					postCalls++
+				}),
+			})
+			req := httptest.NewRequest(tt.calledHttpMethod, "http://testing", nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, tt.expectedHttpStatus, recorder.Code)
+			assert.Equal(t, tt.expectedGetCalls, getCalls)
+			assert.Equal(t, tt.expectedPostCalls, postCalls)
+		})
+	}
+}
+
+// problemBody returns the problem details written by the middleware for the error
+func problemBody(status int, code string, message string) string {
+	return fmt.Sprintf(
+		`{"type":"about:blank","title":%q,"status":%d,"code":%q,"message":%q}`,
+		http.StatusText(status), status, code, message,
+	)
+}
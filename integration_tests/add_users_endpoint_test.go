@@ -3,7 +3,6 @@ package integration_tests
 import (
 	"context"
 	"github.com/golang/mock/gomock"
-	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	uberconfig "go.uber.org/config"
 	"go.uber.org/fx"
@@ -11,14 +10,19 @@ import (
 	"net"
 	"net/http"
 	"redis-postgres-service/controller"
-	"redis-postgres-service/gateway/sha512"
+	"redis-postgres-service/gateway/signer"
 	"redis-postgres-service/handler"
 	"redis-postgres-service/handler/validation"
 	"redis-postgres-service/logging"
-	mocksha512 "redis-postgres-service/mocks/gateway/sha512"
+	"redis-postgres-service/metrics"
+	mocksigner "redis-postgres-service/mocks/gateway/signer"
+	mockkeystore "redis-postgres-service/mocks/repository/keystore"
+	mockmigrations "redis-postgres-service/mocks/repository/postgres/migrations"
 	mockpgfx "redis-postgres-service/mocks/repository/postgres/pgfx"
 	mockredis "redis-postgres-service/mocks/repository/redis"
+	"redis-postgres-service/repository/keystore"
 	"redis-postgres-service/repository/postgres"
+	"redis-postgres-service/repository/postgres/migrations"
 	"redis-postgres-service/repository/postgres/pgfx"
 	"redis-postgres-service/repository/redis"
 	"strings"
@@ -32,18 +36,13 @@ func TestAddUsers(t *testing.T) {
 	defer ctrl.Finish()
 	expectedResponse1 := []byte(`{"id":1}`)
 	expectedResponse2 := []byte(`{"id":2}`)
+	age := 26
 	t.Run("test for 2 concurrent successful calls to Postgres", func(t *testing.T) {
 		NewPostrgesRepo := func() pgfx.Postgres {
 			pgfx_mock := mockpgfx.NewMockPostgres(ctrl)
 			mockTx := mockpgfx.NewMockTx(ctrl)
 			mockRow1 := mockpgfx.NewMockRow(ctrl)
 			mockRow2 := mockpgfx.NewMockRow(ctrl)
-			pgfx_mock.
-				EXPECT().
-				Exec(gomock.Any(), gomock.Any(), gomock.Any()).
-				MaxTimes(1).
-				MinTimes(1).
-				Return(pgconn.NewCommandTag("CREATE TABLE"), nil)
 			pgfx_mock.
 				EXPECT().
 				BeginTx(gomock.Any(), gomock.Any()).
@@ -56,7 +55,7 @@ func TestAddUsers(t *testing.T) {
 					gomock.Any(),
 					gomock.Any(),
 					"user1",
-					26,
+					&age,
 				).
 				MaxTimes(1).
 				MinTimes(1).
@@ -69,7 +68,7 @@ func TestAddUsers(t *testing.T) {
 					gomock.Any(),
 					gomock.Any(),
 					"user2",
-					26,
+					&age,
 				).
 				MaxTimes(1).
 				MinTimes(1).
@@ -106,8 +105,21 @@ func TestAddUsers(t *testing.T) {
 					})
 			return pgfx_mock
 		}
-		NewSignGateway := func() sha512.Gateway {
-			return mocksha512.NewMockGateway(ctrl)
+		NewMigrator := func() migrations.Migrator {
+			migrator := mockmigrations.NewMockMigrator(ctrl)
+			migrator.
+				EXPECT().
+				Up(gomock.Any()).
+				MaxTimes(1).
+				MinTimes(1).
+				Return([]migrations.Migration{{Version: 1, Name: "create_users_table"}}, nil)
+			return migrator
+		}
+		NewSignGateway := func() signer.Gateway {
+			return mocksigner.NewMockGateway(ctrl)
+		}
+		NewKeystore := func() keystore.Keystore {
+			return mockkeystore.NewMockKeystore(ctrl)
 		}
 		NewRedisRepo := func() redis.Repository {
 			return mockredis.NewMockRepository(ctrl)
@@ -134,7 +146,7 @@ func TestAddUsers(t *testing.T) {
 			return mux
 		}
 		NewConfig := func() uberconfig.Provider {
-			configOption := uberconfig.Source(strings.NewReader(`{"postgres_repo_config":{"schema":"public"},"handler":{"request_body_limit":1048576}}`))
+			configOption := uberconfig.Source(strings.NewReader(`{"postgres_repo_config":{"schema":"public","auto_migrate":true},"handler":{"request_body_limit":1048576}}`))
 			provider, _ := uberconfig.NewYAML(configOption)
 			return provider
 		}
@@ -150,13 +162,16 @@ func TestAddUsers(t *testing.T) {
 		}
 		app := fx.New(
 			fx.Provide(NewPostrgesRepo),
+			fx.Provide(NewMigrator),
 			fx.Provide(NewSignGateway),
+			fx.Provide(NewKeystore),
 			fx.Provide(NewRedisRepo),
 			fx.Provide(NewMux),
 			fx.Provide(NewConfig),
 			handler.Module,
 			controller.Module,
 			logging.Module,
+			metrics.Module,
 			fx.Provide(postgres.New),
 			fx.Invoke(Register),
 		)
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"go.uber.org/fx"
+	"os"
 	"redis-postgres-service/app"
 )
 
@@ -12,5 +14,12 @@ func opts() fx.Option {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == app.MigrateCommand {
+		if err := app.Migrate(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	fx.New(opts()).Run()
 }
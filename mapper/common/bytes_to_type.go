@@ -1,16 +1,174 @@
 package common
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"strings"
 )
 
+// Option changes the way BytesToType and TypeToBytes convert the bytes
+type Option func(*options)
+
+type options struct {
+	strict bool
+	codec  Codec
+}
+
+func newOptions(opts []Option) options {
+	o := options{codec: JSON}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Strict makes BytesToType reject the unknown fields, the field names that don't match the json tags exactly
+// and anything following the decoded JSON document, so that typos don't turn into zero values.
+// It applies to the codecs implementing Transcoder only.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithCodec makes BytesToType and TypeToBytes use the codec instead of JSON
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
 // BytesToType converts array of bytes to the variable of type T and returns pointer to it
-func BytesToType[T any](b []byte) (*T, error) {
+func BytesToType[T any](b []byte, opts ...Option) (*T, error) {
+	o := newOptions(opts)
 	var t T
-	err := json.Unmarshal(b, &t)
+	transcoder, ok := o.codec.(Transcoder)
+	if !ok {
+		err := o.codec.Unmarshal(b, &t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal: %s", err)
+		}
+		return &t, nil
+	}
+	b, err := transcoder.ToJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %s", err)
+	}
+	if !o.strict {
+		err := json.Unmarshal(b, &t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal: %s", err)
+		}
+		return &t, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&t)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = errors.New("unexpected end of JSON input")
+	}
+	if err == nil {
+		// the decoder stops after the first document, so anything but EOF is the trailing data
+		if _, err = decoder.Token(); err == io.EOF {
+			err = exactFieldNames(b, reflect.TypeOf(t), "")
+		} else {
+			err = errors.New("unexpected data after the JSON document")
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal: %s", err)
 	}
 	return &t, nil
 }
+
+var _unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// exactFieldNames checks that the names of the object fields in data match the json names of the struct fields
+// of the type case-sensitively, cause encoding/json matches them case-insensitively. Path is the path of data
+// in the document used in the errors, e.g. `items[2].`
+func exactFieldNames(data []byte, t reflect.Type, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) ||
+		t.Implements(_unmarshalerType) || reflect.PointerTo(t).Implements(_unmarshalerType) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil // unreachable, cause data was decoded into the struct
+		}
+		fields := jsonFields(t)
+		for name, value := range object {
+			field, ok := fields[name]
+			if !ok {
+				return fmt.Errorf("json: unknown field \"%s%s\"", path, name)
+			}
+			if err := exactFieldNames(value, field, path+name+"."); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil // []byte is decoded from the base64 string
+		}
+		for i, item := range items {
+			if err := exactFieldNames(item, t.Elem(), fmt.Sprintf("%s[%d].", strings.TrimSuffix(path, "."), i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil // unreachable, cause data was decoded into the map
+		}
+		for key, value := range object {
+			if err := exactFieldNames(value, t.Elem(), path+key+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields returns the types of the struct fields by their json names including the fields of the embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for n, f := range jsonFields(embedded) {
+					if _, ok := fields[n]; !ok {
+						fields[n] = f
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -24,7 +24,7 @@ func TestBytesToTypeIncrementRequest(t *testing.T) {
 			},
 			want: &entity.IncrementRequest{
 				Key:   "Alex",
-				Value: 23,
+				Value: "23",
 			},
 			assertion: assert.NoError,
 		},
@@ -34,7 +34,7 @@ func TestBytesToTypeIncrementRequest(t *testing.T) {
 				b: nil,
 			},
 			want:      nil,
-			assertion: assert.NoError,
+			assertion: assert.Error,
 		},
 		{
 			name: "Happy path",
@@ -55,6 +55,7 @@ func TestBytesToTypeIncrementRequest(t *testing.T) {
 }
 
 func TestBytesToTypeAddUserRequest(t *testing.T) {
+	age := 23
 	type args struct {
 		b []byte
 	}
@@ -72,7 +73,7 @@ func TestBytesToTypeAddUserRequest(t *testing.T) {
 			},
 			want: &entity.AddUserRequest{
 				Name: "Alex",
-				Age:  23,
+				Age:  &age,
 			},
 			assertion: assert.NoError,
 		},
@@ -93,3 +94,64 @@ func TestBytesToTypeAddUserRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestBytesToTypeStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    *entity.BatchIncrementRequest
+		wantErr string
+	}{
+		{
+			name: "Happy path",
+			b:    []byte(`{"items":[{"key":"Alex","value":23}]}` + "\n"),
+			want: &entity.BatchIncrementRequest{
+				Items: []entity.IncrementItem{{Key: "Alex", Value: 23}},
+			},
+		},
+		{
+			name:    "empty body",
+			b:       nil,
+			wantErr: "failed to unmarshal: unexpected end of JSON input",
+		},
+		{
+			name:    "unknown field",
+			b:       []byte(`{"items":[{"key":"Alex","vaule":23}]}`),
+			wantErr: `failed to unmarshal: json: unknown field "vaule"`,
+		},
+		{
+			name:    "field name differs in case",
+			b:       []byte(`{"items":[{"Key":"Alex","value":23}]}`),
+			wantErr: `failed to unmarshal: json: unknown field "items[0].Key"`,
+		},
+		{
+			name:    "trailing garbage",
+			b:       []byte(`{"items":[]}_`),
+			wantErr: "failed to unmarshal: unexpected data after the JSON document",
+		},
+		{
+			name:    "several documents",
+			b:       []byte(`{"items":[]} {"items":[]}`),
+			wantErr: "failed to unmarshal: unexpected data after the JSON document",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BytesToType[entity.BatchIncrementRequest](tt.b, Strict())
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBytesToTypeStrictIgnoredFields(t *testing.T) {
+	_, err := BytesToType[entity.SignRequest]([]byte(`{"key":"k","Algorithm":"hmacsha512"}`), Strict())
+	assert.EqualError(t, err, `failed to unmarshal: json: unknown field "Algorithm"`)
+	got, err := BytesToType[entity.IssueTokenRequest]([]byte(`{"claims":{"Role":"admin"}}`), Strict())
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.IssueTokenRequest{Claims: map[string]any{"Role": "admin"}}, got)
+}
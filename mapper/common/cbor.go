@@ -0,0 +1,86 @@
+package common
+
+import (
+	"encoding/json"
+	"github.com/fxamacker/cbor/v2"
+	"math/big"
+	"reflect"
+)
+
+// CBOR is the RFC 8949 codec, the values are encoded by their json tags in the core deterministic encoding.
+// Integers are encoded in the smallest head, the rest of the numbers as float64. Byte strings are decoded
+// as the base64 string, the same way encoding/json represents []byte, bignums are decoded as numbers,
+// dates as RFC 3339 strings and the rest of the tags are skipped.
+var CBOR Codec = cborCodec{}
+
+var (
+	_cborEncMode = cborMode(cbor.EncOptions{Sort: cbor.SortCoreDeterministic}.EncMode())
+	_cborDecMode = cborMode(cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]any(nil))}.DecMode())
+)
+
+// cborMode returns the mode of the valid options
+func cborMode[T any](mode T, err error) T {
+	if err != nil {
+		panic(err) // unreachable, cause the options are valid
+	}
+	return mode
+}
+
+type cborCodec struct{}
+
+func (cborCodec) ContentType() string {
+	return ContentTypeCBOR
+}
+
+func (cborCodec) Marshal(v any) ([]byte, error) {
+	generic, err := jsonToGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return _cborEncMode.Marshal(generic)
+}
+
+func (c cborCodec) Unmarshal(data []byte, v any) error {
+	j, err := c.ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, v)
+}
+
+func (cborCodec) ToJSON(data []byte) ([]byte, error) {
+	var v any
+	if err := _cborDecMode.Unmarshal(data, &v); err != nil {
+		return nil, codecError("cbor", err)
+	}
+	v, err := cborValue(v)
+	if err != nil {
+		return nil, codecError("cbor", err)
+	}
+	return json.Marshal(v)
+}
+
+// cborValue replaces the decoded values encoding/json can't encode as they are: bignums become numbers
+// and the tagged values their content
+func cborValue(v any) (any, error) {
+	var err error
+	switch value := v.(type) {
+	case big.Int:
+		return json.Number(value.String()), nil
+	case cbor.Tag:
+		return cborValue(value.Content)
+	case []any:
+		for i := range value {
+			if value[i], err = cborValue(value[i]); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]any:
+		for key := range value {
+			if value[key], err = cborValue(value[key]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, checkFinite(v)
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"redis-postgres-service/entity"
+	"testing"
+)
+
+func TestCBORToJSON(t *testing.T) {
+	// vectors of the RFC 8949 Appendix A
+	tests := []struct {
+		name    string
+		cbor    string
+		want    string
+		wantErr string
+	}{
+		{name: "small uint", cbor: "17", want: `23`},
+		{name: "uint16", cbor: "1903e8", want: `1000`},
+		{name: "max uint64", cbor: "1bffffffffffffffff", want: `18446744073709551615`},
+		{name: "negative int", cbor: "3903e7", want: `-1000`},
+		{name: "min negint", cbor: "3bffffffffffffffff", want: `-18446744073709551616`},
+		{name: "bignum", cbor: "c249010000000000000000", want: `18446744073709551616`},
+		{name: "negative bignum", cbor: "c349010000000000000000", want: `-18446744073709551617`},
+		{name: "double", cbor: "fb3ff199999999999a", want: `1.1`},
+		{name: "half", cbor: "f93e00", want: `1.5`},
+		{name: "simple values", cbor: "83f4f5f6", want: `[false,true,null]`},
+		{name: "nested arrays", cbor: "8301820203820405", want: `[1,[2,3],[4,5]]`},
+		{name: "map", cbor: "a26161016162820203", want: `{"a":1,"b":[2,3]}`},
+		{name: "byte string", cbor: "4401020304", want: `"AQIDBA=="`},
+		{name: "indefinite byte string", cbor: "5f42010243030405ff", want: `"AQIDBAU="`},
+		{name: "indefinite text string", cbor: "7f657374726561646d696e67ff", want: `"streaming"`},
+		{name: "indefinite array", cbor: "9f018202039f0405ffff", want: `[1,[2,3],[4,5]]`},
+		{name: "indefinite map", cbor: "bf6346756ef563416d7421ff", want: `{"Amt":-2,"Fun":true}`},
+		{name: "tagged date", cbor: "c074323031332d30332d32315432303a30343a30305a", want: `"2013-03-21T20:04:00Z"`},
+		{name: "truncated", cbor: "8301", wantErr: "cbor: unexpected end of input"},
+		{name: "huge array", cbor: "9bffffffffffffffff", wantErr: "cbor: array length 18446744073709551615 is too large, it would cause integer overflow"},
+		{name: "trailing data", cbor: "0101", wantErr: "cbor: 1 bytes of extraneous data starting at index 1"},
+		{name: "integer keys", cbor: "a10102", wantErr: "cbor: cannot unmarshal positive integer into Go value of type string"},
+		{name: "infinity", cbor: "f97c00", wantErr: "cbor: NaN and infinite numbers are not supported"},
+		{name: "reserved additional information", cbor: "1c", wantErr: "cbor: invalid additional information 28 for type positive integer"},
+		{name: "invalid UTF-8", cbor: "61ff", wantErr: "cbor: invalid UTF-8 string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.cbor)
+			got, err := cborCodec{}.ToJSON(data)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	min, max := int64(-5), int64(1000000)
+	request := &entity.IncrementRequest{Key: "Alex", Value: "-23", TTLSeconds: 60, Min: &min, Max: &max}
+	data, err := TypeToBytes(request, WithCodec(CBOR))
+	assert.NoError(t, err)
+	// keys are sorted by their encoding, so that it is deterministic
+	assert.Equal(t, "a5636b657964416c6578636d61781a000f4240636d696e24"+
+		"6576616c7565366b74746c5f7365636f6e6473183c", hex.EncodeToString(data))
+	got, err := BytesToType[entity.IncrementRequest](data, WithCodec(CBOR), Strict())
+	assert.NoError(t, err)
+	assert.Equal(t, request, got)
+}
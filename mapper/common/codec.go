@@ -0,0 +1,236 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Media types of the registered codecs
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeMsgPack  = "application/msgpack"
+	ContentTypeCBOR     = "application/cbor"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+var (
+	// ErrUnsupportedMediaType is returned when there is no codec registered for the Content-Type of the request
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrNotAcceptable is returned when there is no codec registered for any of the media types in the Accept header
+	ErrNotAcceptable = errors.New("not acceptable")
+)
+
+// Codec encodes the values to the bodies of its media type and decodes them back. Values are structs
+// with the json tags, codecs of the other media types are expected to follow the same field names.
+type Codec interface {
+	// ContentType is the media type of the encoded bodies
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// Transcoder is implemented by the codecs whose bodies can be converted into JSON. BytesToType decodes
+// such bodies as JSON, so that the strict decoding applies to them the same way.
+type Transcoder interface {
+	ToJSON(data []byte) ([]byte, error)
+}
+
+// JSON is the default codec, it is used for the requests without the Content-Type and the Accept headers
+var JSON Codec = jsonCodec{}
+
+var _registry = struct {
+	sync.RWMutex
+	codecs map[string]Codec
+}{
+	codecs: map[string]Codec{
+		ContentTypeJSON:           JSON,
+		"text/json":               JSON,
+		ContentTypeMsgPack:        MsgPack,
+		"application/x-msgpack":   MsgPack,
+		"application/vnd.msgpack": MsgPack,
+		ContentTypeCBOR:           CBOR,
+		ContentTypeProtobuf:       Protobuf,
+		"application/protobuf":    Protobuf,
+	},
+}
+
+// Register makes the codec available for the media types, the media type of the codec is registered as well
+func Register(codec Codec, mediaTypes ...string) {
+	_registry.Lock()
+	defer _registry.Unlock()
+	for _, mediaType := range append(mediaTypes, codec.ContentType()) {
+		_registry.codecs[strings.ToLower(mediaType)] = codec
+	}
+}
+
+// CodecFor returns the codec of the Content-Type, e.g. `application/msgpack`. Parameters of the media type
+// are ignored, bodies without the Content-Type and with the `+json` structured syntax suffix are JSON.
+func CodecFor(contentType string) (Codec, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return JSON, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedMediaType, contentType)
+	}
+	if codec, ok := lookup(mediaType); ok {
+		return codec, nil
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		return JSON, nil
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnsupportedMediaType, mediaType)
+}
+
+// Negotiate returns the codec of the most preferred media type of the Accept header, e.g.
+// `application/cbor, application/json;q=0.5`. Wildcards and the missing header select JSON.
+func Negotiate(accept string) (Codec, error) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+	type candidate struct {
+		mediaType string
+		q         float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if c.mediaType == "*/*" || c.mediaType == "application/*" {
+			return JSON, nil
+		}
+		if codec, ok := lookup(c.mediaType); ok {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrNotAcceptable, accept)
+}
+
+func lookup(mediaType string) (Codec, bool) {
+	_registry.RLock()
+	defer _registry.RUnlock()
+	codec, ok := _registry.codecs[strings.ToLower(mediaType)]
+	return codec, ok
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) ToJSON(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// jsonToGeneric returns the value as the tree of nil, bool, int64, uint64, float64, string, []any
+// and map[string]any the binary codecs encode, so that they follow the json tags of the value
+func jsonToGeneric(v any) (any, error) {
+	generic, err := jsonTree(v)
+	if err != nil {
+		return nil, err
+	}
+	return nativeNumbers(generic), nil
+}
+
+// jsonTree returns the value encoded by encoding/json and decoded back as the tree of json.Number numbers
+func jsonTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&generic); err != nil {
+		return nil, err // unreachable, cause data is a valid JSON
+	}
+	return generic, nil
+}
+
+// nativeNumbers replaces the json.Number values by the integers they hold or float64 otherwise
+func nativeNumbers(v any) any {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return u
+		}
+		f, _ := value.Float64() // the number is valid, cause it is decoded from JSON
+		return f
+	case []any:
+		for i := range value {
+			value[i] = nativeNumbers(value[i])
+		}
+	case map[string]any:
+		for key := range value {
+			value[key] = nativeNumbers(value[key])
+		}
+	}
+	return v
+}
+
+// checkFinite rejects NaN and infinite numbers decoded by the binary codecs, they can't be represented in JSON
+func checkFinite(v any) error {
+	var f float64
+	switch value := v.(type) {
+	case float32:
+		f = float64(value)
+	case float64:
+		f = value
+	default:
+		return nil
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errors.New("NaN and infinite numbers are not supported")
+	}
+	return nil
+}
+
+// codecError prefixes the decoding error by the codec name unless the library did, truncated bodies
+// are reported the same way by every codec
+func codecError(name string, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%s: unexpected end of input", name)
+	}
+	if strings.HasPrefix(err.Error(), name+":") {
+		return err
+	}
+	return fmt.Errorf("%s: %s", name, err)
+}
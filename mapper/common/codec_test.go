@@ -0,0 +1,91 @@
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCodecFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        Codec
+		wantErr     string
+	}{
+		{name: "missing content type", contentType: "", want: JSON},
+		{name: "json with charset", contentType: "application/json; charset=utf-8", want: JSON},
+		{name: "json suffix", contentType: "application/merge-patch+json", want: JSON},
+		{
+			name:        "form sent by curl by default",
+			contentType: "application/x-www-form-urlencoded",
+			wantErr:     "unsupported media type application/x-www-form-urlencoded",
+		},
+		{name: "msgpack", contentType: "application/msgpack", want: MsgPack},
+		{name: "msgpack alias in another case", contentType: "Application/X-MsgPack", want: MsgPack},
+		{name: "cbor", contentType: "application/cbor", want: CBOR},
+		{name: "protobuf", contentType: "application/x-protobuf", want: Protobuf},
+		{name: "xml", contentType: "application/xml", wantErr: "unsupported media type application/xml"},
+		{name: "malformed", contentType: "application/", wantErr: "unsupported media type application/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CodecFor(tt.contentType)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  string
+		want    Codec
+		wantErr string
+	}{
+		{name: "missing accept", accept: "", want: JSON},
+		{name: "any", accept: "*/*", want: JSON},
+		{name: "single", accept: "application/cbor", want: CBOR},
+		{name: "first of the same quality", accept: "application/msgpack, application/cbor", want: MsgPack},
+		{name: "highest quality", accept: "application/json;q=0.5, application/cbor;q=0.9, */*;q=0.1", want: CBOR},
+		{name: "unknown types are skipped", accept: "application/xml, application/msgpack;q=0.2", want: MsgPack},
+		{name: "zero quality is not acceptable", accept: "application/cbor;q=0, application/json", want: JSON},
+		{name: "nothing is acceptable", accept: "application/xml, text/html", wantErr: "not acceptable application/xml, text/html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Negotiate(tt.accept)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, ErrNotAcceptable)
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type textCodec struct{}
+
+func (textCodec) ContentType() string                { return "text/plain" }
+func (textCodec) Marshal(v any) ([]byte, error)      { return []byte(*v.(*string)), nil }
+func (textCodec) Unmarshal(data []byte, v any) error { *v.(*string) = string(data); return nil }
+
+func TestRegister(t *testing.T) {
+	Register(textCodec{}, "text/x-plain")
+	codec, err := CodecFor("text/x-plain; charset=utf-8")
+	assert.NoError(t, err)
+	assert.Equal(t, textCodec{}, codec)
+	got, err := BytesToType[string]([]byte("hello"), WithCodec(codec), Strict())
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", *got)
+	data, err := TypeToBytes(got, WithCodec(codec))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+}
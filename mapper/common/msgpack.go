@@ -0,0 +1,114 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// MsgPack is the MessagePack codec, the values are encoded by their json tags with the sorted map keys.
+// Integers are encoded in the smallest format, the rest of the numbers as float64. Binary data is decoded
+// as the base64 string, the same way encoding/json represents []byte, timestamps as RFC 3339 strings,
+// the rest of the extension types are not supported.
+var MsgPack Codec = msgpackCodec{}
+
+// _maxDepth limits the nesting of the arrays and the maps of the decoded bodies
+const _maxDepth = 1000
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string {
+	return ContentTypeMsgPack
+}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	generic, err := jsonToGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	if err = encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c msgpackCodec) Unmarshal(data []byte, v any) error {
+	j, err := c.ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, v)
+}
+
+func (msgpackCodec) ToJSON(data []byte) ([]byte, error) {
+	reader := bytes.NewReader(data)
+	v, err := msgpackValue(msgpack.NewDecoder(reader), 0)
+	if err != nil {
+		return nil, codecError("msgpack", err)
+	}
+	if reader.Len() != 0 {
+		return nil, errors.New("msgpack: unexpected data after the value")
+	}
+	return json.Marshal(v)
+}
+
+// msgpackValue decodes the value walking the arrays and the maps itself, so that their nesting is limited
+// and the lengths of the truncated bodies don't allocate the memory
+func msgpackValue(d *msgpack.Decoder, depth int) (any, error) {
+	if depth > _maxDepth {
+		return nil, errors.New("nesting is too deep")
+	}
+	code, err := d.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msgpcode.IsFixedArray(code), code == msgpcode.Array16, code == msgpcode.Array32:
+		n, err := d.DecodeArrayLen()
+		if err != nil {
+			return nil, err
+		}
+		array := make([]any, 0)
+		for i := 0; i < n; i++ {
+			v, err := msgpackValue(d, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+		return array, nil
+	case msgpcode.IsFixedMap(code), code == msgpcode.Map16, code == msgpcode.Map32:
+		n, err := d.DecodeMapLen()
+		if err != nil {
+			return nil, err
+		}
+		object := make(map[string]any)
+		for i := 0; i < n; i++ {
+			if code, err = d.PeekCode(); err != nil {
+				return nil, err
+			}
+			if !msgpcode.IsString(code) {
+				return nil, errors.New("map keys must be strings")
+			}
+			key, err := d.DecodeString()
+			if err != nil {
+				return nil, err
+			}
+			if object[key], err = msgpackValue(d, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}
+	v, err := d.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+	return v, checkFinite(v)
+}
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"redis-postgres-service/entity"
+	"testing"
+)
+
+func TestMsgPackToJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		msgpack string
+		want    string
+		wantErr string
+	}{
+		{name: "positive fixint", msgpack: "7f", want: `127`},
+		{name: "negative fixint", msgpack: "e0", want: `-32`},
+		{name: "uint64", msgpack: "cfffffffffffffffff", want: `18446744073709551615`},
+		{name: "int8", msgpack: "d080", want: `-128`},
+		{name: "int64", msgpack: "d38000000000000000", want: `-9223372036854775808`},
+		{name: "float32", msgpack: "ca3fc00000", want: `1.5`},
+		{name: "float64", msgpack: "cb3ff199999999999a", want: `1.1`},
+		{name: "nil and booleans", msgpack: "93c0c2c3", want: `[null,false,true]`},
+		{name: "str8", msgpack: "d903616263", want: `"abc"`},
+		{name: "bin8", msgpack: "c40401020304", want: `"AQIDBA=="`},
+		{name: "array16", msgpack: "dc00020102", want: `[1,2]`},
+		{name: "timestamp", msgpack: "d6ff51e5de80", want: `"2013-07-17T00:00:00Z"`},
+		{name: "map", msgpack: "82a3616765cd0100a46e616d65a4416c6578", want: `{"age":256,"name":"Alex"}`},
+		{name: "truncated", msgpack: "92a3616263", wantErr: "msgpack: unexpected end of input"},
+		{name: "huge map", msgpack: "dfffffffff", wantErr: "msgpack: unexpected end of input"},
+		{name: "trailing data", msgpack: "0101", wantErr: "msgpack: unexpected data after the value"},
+		{name: "integer keys", msgpack: "810102", wantErr: "msgpack: map keys must be strings"},
+		{name: "extension", msgpack: "d4010a", wantErr: "msgpack: unknown ext id=1"},
+		{name: "NaN", msgpack: "cb7ff8000000000000", wantErr: "msgpack: NaN and infinite numbers are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.msgpack)
+			got, err := msgpackCodec{}.ToJSON(data)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestMsgPackRoundTrip(t *testing.T) {
+	request := &entity.BatchIncrementRequest{
+		Items: []entity.IncrementItem{{Key: "a", Value: -200}, {Key: "b", Value: 70000}},
+	}
+	data, err := TypeToBytes(request, WithCodec(MsgPack))
+	assert.NoError(t, err)
+	// keys are sorted, so that the encoding is deterministic
+	assert.Equal(t, "81a56974656d739282a36b6579a161a576616c7565d1ff38"+
+		"82a36b6579a162a576616c7565ce00011170", hex.EncodeToString(data))
+	got, err := BytesToType[entity.BatchIncrementRequest](data, WithCodec(MsgPack), Strict())
+	assert.NoError(t, err)
+	assert.Equal(t, request, got)
+}
+
+func TestMsgPackStrict(t *testing.T) {
+	// {"kye": "a"}
+	data, _ := hex.DecodeString("81a36b7965a161")
+	_, err := BytesToType[entity.IncrementItem](data, WithCodec(MsgPack), Strict())
+	assert.EqualError(t, err, `failed to unmarshal: json: unknown field "kye"`)
+}
@@ -0,0 +1,291 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+	entityv1 "redis-postgres-service/api/entity/v1"
+	"redis-postgres-service/entity"
+	"reflect"
+	"strconv"
+)
+
+// Protobuf is the codec of the messages of api/entity/v1, every entity of the HTTP bodies is encoded as the message
+// of the same name. The fields are matched by the json names of the entity fields, numbers are converted to the
+// strings of the string fields and back, arrays are encoded as the messages with the single repeated field.
+// Unknown fields of the decoded messages are rejected.
+var Protobuf Codec = protobufCodec{}
+
+// _messages are the messages of the types of the HTTP bodies
+var _messages = map[reflect.Type]proto.Message{
+	reflect.TypeOf(entity.IncrementRequest{}):       &entityv1.IncrementRequest{},
+	reflect.TypeOf(entity.IncrementResponse{}):      &entityv1.IncrementResponse{},
+	reflect.TypeOf(entity.BatchIncrementRequest{}):  &entityv1.BatchIncrementRequest{},
+	reflect.TypeOf(entity.BatchIncrementResponse{}): &entityv1.BatchIncrementResponse{},
+	reflect.TypeOf(entity.AddUserRequest{}):         &entityv1.AddUserRequest{},
+	reflect.TypeOf([]entity.AddUserRequest{}):       &entityv1.BulkAddUsersRequest{},
+	reflect.TypeOf(entity.AddUserResponse{}):        &entityv1.AddUserResponse{},
+	reflect.TypeOf(entity.User{}):                   &entityv1.User{},
+	reflect.TypeOf(entity.ListUsersResponse{}):      &entityv1.ListUsersResponse{},
+	reflect.TypeOf(entity.UpdateUserRequest{}):      &entityv1.UpdateUserRequest{},
+	reflect.TypeOf(entity.BulkAddUsersResponse{}):   &entityv1.BulkAddUsersResponse{},
+	reflect.TypeOf(entity.SignRequest{}):            &entityv1.SignRequest{},
+	reflect.TypeOf(entity.SignResponse{}):           &entityv1.SignResponse{},
+	reflect.TypeOf(entity.VerifyRequest{}):          &entityv1.VerifyRequest{},
+	reflect.TypeOf(entity.VerifyResponse{}):         &entityv1.VerifyResponse{},
+	reflect.TypeOf(entity.JWKS{}):                   &entityv1.JWKS{},
+	reflect.TypeOf(entity.HTTPSignRequest{}):        &entityv1.HTTPSignRequest{},
+	reflect.TypeOf(entity.HTTPSignResponse{}):       &entityv1.HTTPSignResponse{},
+	reflect.TypeOf(entity.HTTPVerifyRequest{}):      &entityv1.HTTPVerifyRequest{},
+	reflect.TypeOf(entity.HTTPVerifyResponse{}):     &entityv1.HTTPVerifyResponse{},
+	reflect.TypeOf(entity.IssueTokenRequest{}):      &entityv1.IssueTokenRequest{},
+	reflect.TypeOf(entity.IssueTokenResponse{}):     &entityv1.IssueTokenResponse{},
+	reflect.TypeOf(entity.VerifyTokenRequest{}):     &entityv1.VerifyTokenRequest{},
+	reflect.TypeOf(entity.VerifyTokenResponse{}):    &entityv1.VerifyTokenResponse{},
+}
+
+var _structType = (&structpb.Struct{}).ProtoReflect().Descriptor().FullName()
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, err := newMessage(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	generic, err := jsonTree(v)
+	if err != nil {
+		return nil, err
+	}
+	if array, ok := generic.([]any); ok {
+		generic, err = wrapArray(m.ProtoReflect().Descriptor(), array)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = setFields(m.ProtoReflect(), generic); err != nil {
+		return nil, fmt.Errorf("protobuf: %s", err)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return fmt.Errorf("protobuf: %T is not a pointer", v)
+	}
+	m, err := newMessage(t.Elem())
+	if err != nil {
+		return err
+	}
+	if err = proto.Unmarshal(data, m); err != nil {
+		return errors.New("protobuf: invalid wire-format data") // the messages of the proto errors are unstable
+	}
+	generic, err := fieldValues(m.ProtoReflect())
+	if err != nil {
+		return fmt.Errorf("protobuf: %s", err)
+	}
+	var value any = generic
+	if t.Elem().Kind() == reflect.Slice {
+		value = unwrapArray(m.ProtoReflect().Descriptor(), generic)
+	}
+	j, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, v)
+}
+
+// newMessage returns the empty message of the type, pointers are dereferenced
+func newMessage(t reflect.Type) (proto.Message, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	m, ok := _messages[t]
+	if !ok {
+		return nil, fmt.Errorf("protobuf: no message for %v", t)
+	}
+	return proto.Clone(m), nil
+}
+
+// wrapArray returns the object of the array as the value of the single repeated field of the message
+func wrapArray(d protoreflect.MessageDescriptor, array []any) (map[string]any, error) {
+	if d.Fields().Len() != 1 || !d.Fields().Get(0).IsList() {
+		return nil, fmt.Errorf("protobuf: %s doesn't wrap an array", d.FullName())
+	}
+	return map[string]any{string(d.Fields().Get(0).Name()): array}, nil
+}
+
+// unwrapArray returns the array of the single repeated field of the message
+func unwrapArray(d protoreflect.MessageDescriptor, object map[string]any) []any {
+	array, _ := object[string(d.Fields().Get(0).Name())].([]any)
+	if array == nil {
+		return []any{}
+	}
+	return array
+}
+
+// setFields sets the fields of the message by the object decoded from JSON with the json.Number numbers
+func setFields(m protoreflect.Message, generic any) error {
+	if m.Descriptor().FullName() == _structType {
+		s, err := structpb.NewStruct(nativeNumbers(generic).(map[string]any))
+		if err != nil {
+			return err
+		}
+		proto.Merge(m.Interface(), s)
+		return nil
+	}
+	object, ok := generic.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s must be an object", m.Descriptor().FullName())
+	}
+	fields := m.Descriptor().Fields()
+	for name, value := range object {
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("unknown field %s of %s", name, m.Descriptor().FullName())
+		}
+		if value == nil {
+			continue
+		}
+		var err error
+		switch {
+		case fd.IsList():
+			items, ok := value.([]any)
+			if !ok {
+				return fmt.Errorf("%s must be an array", fd.Name())
+			}
+			list := m.Mutable(fd).List()
+			for _, item := range items {
+				v, err := fieldValue(fd, list.NewElement, item)
+				if err != nil {
+					return err
+				}
+				list.Append(v)
+			}
+		case fd.IsMap():
+			entries, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s must be an object", fd.Name())
+			}
+			mapValue := m.Mutable(fd).Map()
+			for key, entry := range entries {
+				v, err := fieldValue(fd.MapValue(), mapValue.NewValue, entry)
+				if err != nil {
+					return err
+				}
+				mapValue.Set(protoreflect.ValueOfString(key).MapKey(), v)
+			}
+		case fd.Message() != nil:
+			err = setFields(m.Mutable(fd).Message(), value)
+		default:
+			var v protoreflect.Value
+			if v, err = fieldValue(fd, nil, value); err == nil {
+				m.Set(fd, v)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldValue converts the JSON value to the value of the field, newValue returns the new message
+// of the message fields
+func fieldValue(fd protoreflect.FieldDescriptor, newValue func() protoreflect.Value, value any) (protoreflect.Value, error) {
+	if fd.Message() != nil {
+		v := newValue()
+		return v, setFields(v.Message(), value)
+	}
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		switch s := value.(type) {
+		case string:
+			return protoreflect.ValueOfString(s), nil
+		case json.Number:
+			return protoreflect.ValueOfString(s.String()), nil
+		}
+	case protoreflect.BytesKind:
+		if s, ok := value.(string); ok {
+			if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+				return protoreflect.ValueOfBytes(b), nil
+			}
+		}
+	case protoreflect.BoolKind:
+		if b, ok := value.(bool); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if n, ok := value.(json.Number); ok {
+			if i, err := strconv.ParseInt(n.String(), 10, 32); err == nil {
+				return protoreflect.ValueOfInt32(int32(i)), nil
+			}
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if n, ok := value.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return protoreflect.ValueOfInt64(i), nil
+			}
+		}
+	case protoreflect.DoubleKind:
+		if n, ok := value.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				return protoreflect.ValueOfFloat64(f), nil
+			}
+		}
+	}
+	return protoreflect.Value{}, fmt.Errorf("%s must be %s", fd.Name(), fd.Kind())
+}
+
+// fieldValues returns the populated fields of the message by their names as the values encoding/json encodes
+func fieldValues(m protoreflect.Message) (map[string]any, error) {
+	if len(m.GetUnknown()) != 0 {
+		return nil, fmt.Errorf("unknown fields of %s", m.Descriptor().FullName())
+	}
+	if s, ok := m.Interface().(*structpb.Struct); ok {
+		return s.AsMap(), nil
+	}
+	object := make(map[string]any)
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			list := v.List()
+			items := make([]any, list.Len())
+			for i := range items {
+				if items[i], err = jsonValue(fd, list.Get(i)); err != nil {
+					return false
+				}
+			}
+			object[string(fd.Name())] = items
+		case fd.IsMap():
+			entries := make(map[string]any)
+			v.Map().Range(func(key protoreflect.MapKey, v protoreflect.Value) bool {
+				entries[key.String()], err = jsonValue(fd.MapValue(), v)
+				return err == nil
+			})
+			object[string(fd.Name())] = entries
+		default:
+			object[string(fd.Name())], err = jsonValue(fd, v)
+		}
+		return err == nil
+	})
+	return object, err
+}
+
+// jsonValue returns the value of the field encoding/json encodes
+func jsonValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	if fd.Message() != nil {
+		return fieldValues(v.Message())
+	}
+	return v.Interface(), nil
+}
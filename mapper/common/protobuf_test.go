@@ -0,0 +1,141 @@
+package common
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	entityv1 "redis-postgres-service/api/entity/v1"
+	"redis-postgres-service/entity"
+	"testing"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	min, max := int64(-5), int64(1000000)
+	age := 30
+	name := "Alex"
+	tests := []struct {
+		name  string
+		value any
+		want  proto.Message
+	}{
+		{
+			name:  "numbers of the string fields",
+			value: &entity.IncrementRequest{Key: "Alex", Value: "-0.000000000000000000023", TTLSeconds: 60, Min: &min, Max: &max},
+			want: &entityv1.IncrementRequest{
+				Key: "Alex", Value: "-0.000000000000000000023", TtlSeconds: 60, Min: proto.Int64(-5), Max: proto.Int64(1000000),
+			},
+		},
+		{
+			name:  "repeated messages",
+			value: &entity.BatchIncrementRequest{Items: []entity.IncrementItem{{Key: "a", Value: -200}, {Key: "b"}}},
+			want: &entityv1.BatchIncrementRequest{
+				Items: []*entityv1.IncrementItem{{Key: "a", Value: -200}, {Key: "b"}},
+			},
+		},
+		{
+			name:  "optional fields",
+			value: &entity.UpdateUserRequest{Name: &name},
+			want:  &entityv1.UpdateUserRequest{Name: proto.String("Alex")},
+		},
+		{
+			name:  "array",
+			value: &[]entity.AddUserRequest{{Name: "Alex", Age: &age}, {Name: "Bob"}},
+			want: &entityv1.BulkAddUsersRequest{
+				Users: []*entityv1.AddUserRequest{{Name: "Alex", Age: proto.Int32(30)}, {Name: "Bob"}},
+			},
+		},
+		{
+			name: "embedded struct and map",
+			value: &entity.HTTPSignRequest{
+				HTTPMessage: entity.HTTPMessage{Method: "GET", URL: "https://example.com", Headers: map[string]string{"Host": "example.com"}},
+				Algorithm:   "hmac-sha256",
+				Components:  []string{"@method"},
+			},
+			want: &entityv1.HTTPSignRequest{
+				Method:     "GET",
+				Url:        "https://example.com",
+				Headers:    map[string]string{"Host": "example.com"},
+				Algorithm:  "hmac-sha256",
+				Components: []string{"@method"},
+			},
+		},
+		{
+			name:  "claims",
+			value: &entity.VerifyTokenResponse{Valid: true, Claims: map[string]any{"sub": "alex", "exp": float64(1700000000)}},
+			want: &entityv1.VerifyTokenResponse{
+				Valid: true,
+				Claims: &structpb.Struct{Fields: map[string]*structpb.Value{
+					"sub": structpb.NewStringValue("alex"),
+					"exp": structpb.NewNumberValue(1700000000),
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Protobuf.Marshal(tt.value)
+			assert.NoError(t, err)
+			want, _ := proto.MarshalOptions{Deterministic: true}.Marshal(tt.want)
+			assert.Equal(t, hex.EncodeToString(want), hex.EncodeToString(data))
+			got := newValue(tt.value)
+			assert.NoError(t, Protobuf.Unmarshal(data, got))
+			assert.Equal(t, tt.value, got)
+		})
+	}
+}
+
+// newValue returns the pointer to the zero value of the type v points to
+func newValue(v any) any {
+	switch v.(type) {
+	case *entity.IncrementRequest:
+		return &entity.IncrementRequest{}
+	case *entity.BatchIncrementRequest:
+		return &entity.BatchIncrementRequest{}
+	case *entity.UpdateUserRequest:
+		return &entity.UpdateUserRequest{}
+	case *[]entity.AddUserRequest:
+		return &[]entity.AddUserRequest{}
+	case *entity.HTTPSignRequest:
+		return &entity.HTTPSignRequest{}
+	case *entity.VerifyTokenResponse:
+		return &entity.VerifyTokenResponse{}
+	}
+	return nil
+}
+
+func TestProtobufUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *entity.IncrementResponse
+		wantErr string
+	}{
+		{name: "number of the string field", data: "0a022d311008", want: &entity.IncrementResponse{Value: "-1", TTLSeconds: 8}},
+		{name: "empty message", data: "", want: &entity.IncrementResponse{}},
+		{name: "unknown field", data: "0a02313218ff01", wantErr: "protobuf: unknown fields of redis_postgres_service.entity.v1.IncrementResponse"},
+		{name: "truncated", data: "0a05", wantErr: "protobuf: invalid wire-format data"},
+		{
+			name:    "not a number",
+			data:    "0a03616263",
+			wantErr: `json: cannot unmarshal string "abc" into Go value of type json.Number: invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			got, err := BytesToType[entity.IncrementResponse](data, WithCodec(Protobuf), Strict())
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, "failed to unmarshal: "+tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProtobufNoMessage(t *testing.T) {
+	_, err := TypeToBytes(&entity.Problem{}, WithCodec(Protobuf))
+	assert.EqualError(t, err, "failed to marshal: protobuf: no message for entity.Problem")
+}
@@ -1,12 +1,12 @@
 package common
 
 import (
-	"encoding/json"
 	"fmt"
 )
 
-func TypeToBytes[T any](t *T) ([]byte, error) {
-	b, err := json.Marshal(t)
+// TypeToBytes converts the variable of type T to array of bytes, JSON unless the codec is provided by WithCodec
+func TypeToBytes[T any](t *T, opts ...Option) ([]byte, error) {
+	b, err := newOptions(opts).codec.Marshal(t)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal: %s", err)
 	}
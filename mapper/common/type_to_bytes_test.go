@@ -22,7 +22,7 @@ func TestTypeToBytesIncrementRequest(t *testing.T) {
 			args: args[entity.IncrementRequest]{
 				t: &entity.IncrementRequest{
 					Key:   "Alex",
-					Value: 24,
+					Value: "24",
 				},
 			},
 			want:      []byte(`{"key":"Alex","value":24}`),
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Registry collects the metrics registered by the layers of the service and gathers them for the /metrics endpoint
+type Registry interface {
+	prometheus.Registerer
+	prometheus.Gatherer
+}
+
+// New is a constructor provided to the fx for creating a Registry with the go runtime and the process metrics
+func New() (Registry, error) {
+	registry := prometheus.NewRegistry()
+	for _, c := range []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		if err := registry.Register(c); err != nil {
+			return nil, errors.Errorf("failed to register metrics: %s", err) // unreachable, cause the registry is empty
+		}
+	}
+	return registry, nil
+}
+
+// Handler serves the metrics gathered by the registry in the Prometheus exposition format
+func Handler(g prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(g, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,7 @@
+package metrics
+
+import "go.uber.org/fx"
+
+var Module = fx.Options(
+	fx.Provide(New),
+)
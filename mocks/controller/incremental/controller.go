@@ -35,6 +35,21 @@ func (m *MockController) EXPECT() *MockControllerMockRecorder {
 	return m.recorder
 }
 
+// Get mocks base method.
+func (m *MockController) Get(ctx context.Context, req *entity.CounterRequest) (*entity.IncrementResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, req)
+	ret0, _ := ret[0].(*entity.IncrementResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockControllerMockRecorder) Get(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockController)(nil).Get), ctx, req)
+}
+
 // Inc mocks base method.
 func (m *MockController) Inc(ctx context.Context, req *entity.IncrementRequest) (*entity.IncrementResponse, error) {
 	m.ctrl.T.Helper()
@@ -49,3 +64,33 @@ func (mr *MockControllerMockRecorder) Inc(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inc", reflect.TypeOf((*MockController)(nil).Inc), ctx, req)
 }
+
+// IncBatch mocks base method.
+func (m *MockController) IncBatch(ctx context.Context, req *entity.BatchIncrementRequest) (*entity.BatchIncrementResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncBatch", ctx, req)
+	ret0, _ := ret[0].(*entity.BatchIncrementResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncBatch indicates an expected call of IncBatch.
+func (mr *MockControllerMockRecorder) IncBatch(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncBatch", reflect.TypeOf((*MockController)(nil).IncBatch), ctx, req)
+}
+
+// Reset mocks base method.
+func (m *MockController) Reset(ctx context.Context, req *entity.CounterRequest) (*entity.IncrementResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, req)
+	ret0, _ := ret[0].(*entity.IncrementResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockControllerMockRecorder) Reset(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockController)(nil).Reset), ctx, req)
+}
@@ -35,6 +35,21 @@ func (m *MockController) EXPECT() *MockControllerMockRecorder {
 	return m.recorder
 }
 
+// PublicKeys mocks base method.
+func (m *MockController) PublicKeys(ctx context.Context) (*entity.JWKS, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKeys", ctx)
+	ret0, _ := ret[0].(*entity.JWKS)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublicKeys indicates an expected call of PublicKeys.
+func (mr *MockControllerMockRecorder) PublicKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKeys", reflect.TypeOf((*MockController)(nil).PublicKeys), ctx)
+}
+
 // Sign mocks base method.
 func (m *MockController) Sign(ctx context.Context, req *entity.SignRequest) (*entity.SignResponse, error) {
 	m.ctrl.T.Helper()
@@ -49,3 +64,33 @@ func (mr *MockControllerMockRecorder) Sign(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockController)(nil).Sign), ctx, req)
 }
+
+// SignStream mocks base method.
+func (m *MockController) SignStream(ctx context.Context, req *entity.SignStreamRequest) (*entity.SignResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignStream", ctx, req)
+	ret0, _ := ret[0].(*entity.SignResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignStream indicates an expected call of SignStream.
+func (mr *MockControllerMockRecorder) SignStream(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignStream", reflect.TypeOf((*MockController)(nil).SignStream), ctx, req)
+}
+
+// Verify mocks base method.
+func (m *MockController) Verify(ctx context.Context, req *entity.VerifyRequest) (*entity.VerifyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, req)
+	ret0, _ := ret[0].(*entity.VerifyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockControllerMockRecorder) Verify(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockController)(nil).Verify), ctx, req)
+}
@@ -49,3 +49,78 @@ func (mr *MockControllerMockRecorder) Add(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockController)(nil).Add), ctx, req)
 }
+
+// BulkAdd mocks base method.
+func (m *MockController) BulkAdd(ctx context.Context, req *entity.BulkAddUsersRequest) (*entity.BulkAddUsersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkAdd", ctx, req)
+	ret0, _ := ret[0].(*entity.BulkAddUsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkAdd indicates an expected call of BulkAdd.
+func (mr *MockControllerMockRecorder) BulkAdd(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkAdd", reflect.TypeOf((*MockController)(nil).BulkAdd), ctx, req)
+}
+
+// Delete mocks base method.
+func (m *MockController) Delete(ctx context.Context, req *entity.DeleteUserRequest) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, req)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockControllerMockRecorder) Delete(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockController)(nil).Delete), ctx, req)
+}
+
+// Get mocks base method.
+func (m *MockController) Get(ctx context.Context, req *entity.GetUserRequest) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, req)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockControllerMockRecorder) Get(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockController)(nil).Get), ctx, req)
+}
+
+// List mocks base method.
+func (m *MockController) List(ctx context.Context, req *entity.ListUsersRequest) (*entity.ListUsersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, req)
+	ret0, _ := ret[0].(*entity.ListUsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockControllerMockRecorder) List(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockController)(nil).List), ctx, req)
+}
+
+// Update mocks base method.
+func (m *MockController) Update(ctx context.Context, req *entity.UpdateUserRequest) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, req)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockControllerMockRecorder) Update(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockController)(nil).Update), ctx, req)
+}
@@ -0,0 +1,353 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gateway/signer/gateway.go
+
+// Package mock_signer is a generated GoMock package.
+package mock_signer
+
+import (
+	context "context"
+	entity "redis-postgres-service/entity"
+	signer "redis-postgres-service/gateway/signer"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSigner is a mock of Signer interface.
+type MockSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignerMockRecorder
+}
+
+// MockSignerMockRecorder is the mock recorder for MockSigner.
+type MockSignerMockRecorder struct {
+	mock *MockSigner
+}
+
+// NewMockSigner creates a new mock instance.
+func NewMockSigner(ctrl *gomock.Controller) *MockSigner {
+	mock := &MockSigner{ctrl: ctrl}
+	mock.recorder = &MockSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSigner) EXPECT() *MockSignerMockRecorder {
+	return m.recorder
+}
+
+// Sign mocks base method.
+func (m *MockSigner) Sign(key, message []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", key, message)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockSignerMockRecorder) Sign(key, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockSigner)(nil).Sign), key, message)
+}
+
+// Verify mocks base method.
+func (m *MockSigner) Verify(key, message, signature []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", key, message, signature)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockSignerMockRecorder) Verify(key, message, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockSigner)(nil).Verify), key, message, signature)
+}
+
+// MockPublicKeySigner is a mock of PublicKeySigner interface.
+type MockPublicKeySigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublicKeySignerMockRecorder
+}
+
+// MockPublicKeySignerMockRecorder is the mock recorder for MockPublicKeySigner.
+type MockPublicKeySignerMockRecorder struct {
+	mock *MockPublicKeySigner
+}
+
+// NewMockPublicKeySigner creates a new mock instance.
+func NewMockPublicKeySigner(ctrl *gomock.Controller) *MockPublicKeySigner {
+	mock := &MockPublicKeySigner{ctrl: ctrl}
+	mock.recorder = &MockPublicKeySignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublicKeySigner) EXPECT() *MockPublicKeySignerMockRecorder {
+	return m.recorder
+}
+
+// PublicKey mocks base method.
+func (m *MockPublicKeySigner) PublicKey(key []byte) (*entity.JWK, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKey", key)
+	ret0, _ := ret[0].(*entity.JWK)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublicKey indicates an expected call of PublicKey.
+func (mr *MockPublicKeySignerMockRecorder) PublicKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKey", reflect.TypeOf((*MockPublicKeySigner)(nil).PublicKey), key)
+}
+
+// Sign mocks base method.
+func (m *MockPublicKeySigner) Sign(key, message []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", key, message)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockPublicKeySignerMockRecorder) Sign(key, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockPublicKeySigner)(nil).Sign), key, message)
+}
+
+// Verify mocks base method.
+func (m *MockPublicKeySigner) Verify(key, message, signature []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", key, message, signature)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockPublicKeySignerMockRecorder) Verify(key, message, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockPublicKeySigner)(nil).Verify), key, message, signature)
+}
+
+// MockStream is a mock of Stream interface.
+type MockStream struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamMockRecorder
+}
+
+// MockStreamMockRecorder is the mock recorder for MockStream.
+type MockStreamMockRecorder struct {
+	mock *MockStream
+}
+
+// NewMockStream creates a new mock instance.
+func NewMockStream(ctrl *gomock.Controller) *MockStream {
+	mock := &MockStream{ctrl: ctrl}
+	mock.recorder = &MockStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStream) EXPECT() *MockStreamMockRecorder {
+	return m.recorder
+}
+
+// Signature mocks base method.
+func (m *MockStream) Signature() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Signature")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Signature indicates an expected call of Signature.
+func (mr *MockStreamMockRecorder) Signature() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signature", reflect.TypeOf((*MockStream)(nil).Signature))
+}
+
+// Write mocks base method.
+func (m *MockStream) Write(p []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockStreamMockRecorder) Write(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockStream)(nil).Write), p)
+}
+
+// MockStreamSigner is a mock of StreamSigner interface.
+type MockStreamSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamSignerMockRecorder
+}
+
+// MockStreamSignerMockRecorder is the mock recorder for MockStreamSigner.
+type MockStreamSignerMockRecorder struct {
+	mock *MockStreamSigner
+}
+
+// NewMockStreamSigner creates a new mock instance.
+func NewMockStreamSigner(ctrl *gomock.Controller) *MockStreamSigner {
+	mock := &MockStreamSigner{ctrl: ctrl}
+	mock.recorder = &MockStreamSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamSigner) EXPECT() *MockStreamSignerMockRecorder {
+	return m.recorder
+}
+
+// NewStream mocks base method.
+func (m *MockStreamSigner) NewStream(key []byte) (signer.Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewStream", key)
+	ret0, _ := ret[0].(signer.Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewStream indicates an expected call of NewStream.
+func (mr *MockStreamSignerMockRecorder) NewStream(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewStream", reflect.TypeOf((*MockStreamSigner)(nil).NewStream), key)
+}
+
+// Sign mocks base method.
+func (m *MockStreamSigner) Sign(key, message []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", key, message)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockStreamSignerMockRecorder) Sign(key, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockStreamSigner)(nil).Sign), key, message)
+}
+
+// Verify mocks base method.
+func (m *MockStreamSigner) Verify(key, message, signature []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", key, message, signature)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockStreamSignerMockRecorder) Verify(key, message, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockStreamSigner)(nil).Verify), key, message, signature)
+}
+
+// MockGateway is a mock of Gateway interface.
+type MockGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockGatewayMockRecorder
+}
+
+// MockGatewayMockRecorder is the mock recorder for MockGateway.
+type MockGatewayMockRecorder struct {
+	mock *MockGateway
+}
+
+// NewMockGateway creates a new mock instance.
+func NewMockGateway(ctrl *gomock.Controller) *MockGateway {
+	mock := &MockGateway{ctrl: ctrl}
+	mock.recorder = &MockGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGateway) EXPECT() *MockGatewayMockRecorder {
+	return m.recorder
+}
+
+// Algorithms mocks base method.
+func (m *MockGateway) Algorithms() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Algorithms")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Algorithms indicates an expected call of Algorithms.
+func (mr *MockGatewayMockRecorder) Algorithms() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Algorithms", reflect.TypeOf((*MockGateway)(nil).Algorithms))
+}
+
+// NewStream mocks base method.
+func (m *MockGateway) NewStream(ctx context.Context, algorithm string, key []byte) (signer.Stream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewStream", ctx, algorithm, key)
+	ret0, _ := ret[0].(signer.Stream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewStream indicates an expected call of NewStream.
+func (mr *MockGatewayMockRecorder) NewStream(ctx, algorithm, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewStream", reflect.TypeOf((*MockGateway)(nil).NewStream), ctx, algorithm, key)
+}
+
+// PublicKey mocks base method.
+func (m *MockGateway) PublicKey(ctx context.Context, algorithm string, key []byte) (*entity.JWK, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKey", ctx, algorithm, key)
+	ret0, _ := ret[0].(*entity.JWK)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublicKey indicates an expected call of PublicKey.
+func (mr *MockGatewayMockRecorder) PublicKey(ctx, algorithm, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKey", reflect.TypeOf((*MockGateway)(nil).PublicKey), ctx, algorithm, key)
+}
+
+// Sign mocks base method.
+func (m *MockGateway) Sign(ctx context.Context, algorithm string, key, message []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", ctx, algorithm, key, message)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockGatewayMockRecorder) Sign(ctx, algorithm, key, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockGateway)(nil).Sign), ctx, algorithm, key, message)
+}
+
+// Verify mocks base method.
+func (m *MockGateway) Verify(ctx context.Context, algorithm string, key, message, signature []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, algorithm, key, message, signature)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockGatewayMockRecorder) Verify(ctx, algorithm, key, message, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockGateway)(nil).Verify), ctx, algorithm, key, message, signature)
+}
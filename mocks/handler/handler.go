@@ -0,0 +1,251 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler/handler.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHandler is a mock of Handler interface.
+type MockHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockHandlerMockRecorder
+}
+
+// MockHandlerMockRecorder is the mock recorder for MockHandler.
+type MockHandlerMockRecorder struct {
+	mock *MockHandler
+}
+
+// NewMockHandler creates a new mock instance.
+func NewMockHandler(ctrl *gomock.Controller) *MockHandler {
+	mock := &MockHandler{ctrl: ctrl}
+	mock.recorder = &MockHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHandler) EXPECT() *MockHandlerMockRecorder {
+	return m.recorder
+}
+
+// AddUser mocks base method.
+func (m *MockHandler) AddUser(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddUser", w, req)
+}
+
+// AddUser indicates an expected call of AddUser.
+func (mr *MockHandlerMockRecorder) AddUser(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockHandler)(nil).AddUser), w, req)
+}
+
+// BulkAddUsers mocks base method.
+func (m *MockHandler) BulkAddUsers(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BulkAddUsers", w, req)
+}
+
+// BulkAddUsers indicates an expected call of BulkAddUsers.
+func (mr *MockHandlerMockRecorder) BulkAddUsers(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkAddUsers", reflect.TypeOf((*MockHandler)(nil).BulkAddUsers), w, req)
+}
+
+// DeleteUser mocks base method.
+func (m *MockHandler) DeleteUser(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteUser", w, req)
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockHandlerMockRecorder) DeleteUser(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockHandler)(nil).DeleteUser), w, req)
+}
+
+// GetCounter mocks base method.
+func (m *MockHandler) GetCounter(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetCounter", w, req)
+}
+
+// GetCounter indicates an expected call of GetCounter.
+func (mr *MockHandlerMockRecorder) GetCounter(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCounter", reflect.TypeOf((*MockHandler)(nil).GetCounter), w, req)
+}
+
+// GetUser mocks base method.
+func (m *MockHandler) GetUser(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetUser", w, req)
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockHandlerMockRecorder) GetUser(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockHandler)(nil).GetUser), w, req)
+}
+
+// HTTPSign mocks base method.
+func (m *MockHandler) HTTPSign(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HTTPSign", w, req)
+}
+
+// HTTPSign indicates an expected call of HTTPSign.
+func (mr *MockHandlerMockRecorder) HTTPSign(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HTTPSign", reflect.TypeOf((*MockHandler)(nil).HTTPSign), w, req)
+}
+
+// HTTPVerify mocks base method.
+func (m *MockHandler) HTTPVerify(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HTTPVerify", w, req)
+}
+
+// HTTPVerify indicates an expected call of HTTPVerify.
+func (mr *MockHandlerMockRecorder) HTTPVerify(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HTTPVerify", reflect.TypeOf((*MockHandler)(nil).HTTPVerify), w, req)
+}
+
+// Incremental mocks base method.
+func (m *MockHandler) Incremental(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Incremental", w, req)
+}
+
+// Incremental indicates an expected call of Incremental.
+func (mr *MockHandlerMockRecorder) Incremental(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incremental", reflect.TypeOf((*MockHandler)(nil).Incremental), w, req)
+}
+
+// IncrementalBatch mocks base method.
+func (m *MockHandler) IncrementalBatch(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrementalBatch", w, req)
+}
+
+// IncrementalBatch indicates an expected call of IncrementalBatch.
+func (mr *MockHandlerMockRecorder) IncrementalBatch(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementalBatch", reflect.TypeOf((*MockHandler)(nil).IncrementalBatch), w, req)
+}
+
+// IssueToken mocks base method.
+func (m *MockHandler) IssueToken(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IssueToken", w, req)
+}
+
+// IssueToken indicates an expected call of IssueToken.
+func (mr *MockHandlerMockRecorder) IssueToken(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueToken", reflect.TypeOf((*MockHandler)(nil).IssueToken), w, req)
+}
+
+// ListUsers mocks base method.
+func (m *MockHandler) ListUsers(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListUsers", w, req)
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockHandlerMockRecorder) ListUsers(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockHandler)(nil).ListUsers), w, req)
+}
+
+// PublicKeys mocks base method.
+func (m *MockHandler) PublicKeys(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PublicKeys", w, req)
+}
+
+// PublicKeys indicates an expected call of PublicKeys.
+func (mr *MockHandlerMockRecorder) PublicKeys(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKeys", reflect.TypeOf((*MockHandler)(nil).PublicKeys), w, req)
+}
+
+// ResetCounter mocks base method.
+func (m *MockHandler) ResetCounter(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetCounter", w, req)
+}
+
+// ResetCounter indicates an expected call of ResetCounter.
+func (mr *MockHandlerMockRecorder) ResetCounter(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetCounter", reflect.TypeOf((*MockHandler)(nil).ResetCounter), w, req)
+}
+
+// Signature mocks base method.
+func (m *MockHandler) Signature(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Signature", w, req)
+}
+
+// Signature indicates an expected call of Signature.
+func (mr *MockHandlerMockRecorder) Signature(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signature", reflect.TypeOf((*MockHandler)(nil).Signature), w, req)
+}
+
+// SignatureStream mocks base method.
+func (m *MockHandler) SignatureStream(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignatureStream", w, req)
+}
+
+// SignatureStream indicates an expected call of SignatureStream.
+func (mr *MockHandlerMockRecorder) SignatureStream(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignatureStream", reflect.TypeOf((*MockHandler)(nil).SignatureStream), w, req)
+}
+
+// UpdateUser mocks base method.
+func (m *MockHandler) UpdateUser(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateUser", w, req)
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockHandlerMockRecorder) UpdateUser(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockHandler)(nil).UpdateUser), w, req)
+}
+
+// Verify mocks base method.
+func (m *MockHandler) Verify(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Verify", w, req)
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockHandlerMockRecorder) Verify(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockHandler)(nil).Verify), w, req)
+}
+
+// VerifyToken mocks base method.
+func (m *MockHandler) VerifyToken(w http.ResponseWriter, req *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VerifyToken", w, req)
+}
+
+// VerifyToken indicates an expected call of VerifyToken.
+func (mr *MockHandlerMockRecorder) VerifyToken(w, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyToken", reflect.TypeOf((*MockHandler)(nil).VerifyToken), w, req)
+}
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler/validation/metrics.go
+
+// Package mock_validation is a generated GoMock package.
+package mock_validation
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockInstrumenter is a mock of Instrumenter interface.
+type MockInstrumenter struct {
+	ctrl     *gomock.Controller
+	recorder *MockInstrumenterMockRecorder
+}
+
+// MockInstrumenterMockRecorder is the mock recorder for MockInstrumenter.
+type MockInstrumenterMockRecorder struct {
+	mock *MockInstrumenter
+}
+
+// NewMockInstrumenter creates a new mock instance.
+func NewMockInstrumenter(ctrl *gomock.Controller) *MockInstrumenter {
+	mock := &MockInstrumenter{ctrl: ctrl}
+	mock.recorder = &MockInstrumenterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInstrumenter) EXPECT() *MockInstrumenterMockRecorder {
+	return m.recorder
+}
+
+// Instrument mocks base method.
+func (m *MockInstrumenter) Instrument(route string) func(http.Handler) http.Handler {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Instrument", route)
+	ret0, _ := ret[0].(func(http.Handler) http.Handler)
+	return ret0
+}
+
+// Instrument indicates an expected call of Instrument.
+func (mr *MockInstrumenterMockRecorder) Instrument(route interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Instrument", reflect.TypeOf((*MockInstrumenter)(nil).Instrument), route)
+}
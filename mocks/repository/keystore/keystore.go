@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/keystore/keystore.go
+
+// Package mock_keystore is a generated GoMock package.
+package mock_keystore
+
+import (
+	context "context"
+	keystore "redis-postgres-service/repository/keystore"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockKeystore is a mock of Keystore interface.
+type MockKeystore struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeystoreMockRecorder
+}
+
+// MockKeystoreMockRecorder is the mock recorder for MockKeystore.
+type MockKeystoreMockRecorder struct {
+	mock *MockKeystore
+}
+
+// NewMockKeystore creates a new mock instance.
+func NewMockKeystore(ctrl *gomock.Controller) *MockKeystore {
+	mock := &MockKeystore{ctrl: ctrl}
+	mock.recorder = &MockKeystoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeystore) EXPECT() *MockKeystoreMockRecorder {
+	return m.recorder
+}
+
+// Key mocks base method.
+func (m *MockKeystore) Key(ctx context.Context, id string, version int) (*keystore.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Key", ctx, id, version)
+	ret0, _ := ret[0].(*keystore.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Key indicates an expected call of Key.
+func (mr *MockKeystoreMockRecorder) Key(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Key", reflect.TypeOf((*MockKeystore)(nil).Key), ctx, id, version)
+}
+
+// Keys mocks base method.
+func (m *MockKeystore) Keys(ctx context.Context) ([]keystore.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Keys", ctx)
+	ret0, _ := ret[0].([]keystore.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Keys indicates an expected call of Keys.
+func (mr *MockKeystoreMockRecorder) Keys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keys", reflect.TypeOf((*MockKeystore)(nil).Keys), ctx)
+}
+
+// Versions mocks base method.
+func (m *MockKeystore) Versions(ctx context.Context, id string) ([]keystore.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Versions", ctx, id)
+	ret0, _ := ret[0].([]keystore.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Versions indicates an expected call of Versions.
+func (mr *MockKeystoreMockRecorder) Versions(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Versions", reflect.TypeOf((*MockKeystore)(nil).Versions), ctx, id)
+}
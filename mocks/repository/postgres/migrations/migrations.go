@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/postgres/migrations/migrations.go
+
+// Package mock_migrations is a generated GoMock package.
+package mock_migrations
+
+import (
+	context "context"
+	migrations "redis-postgres-service/repository/postgres/migrations"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMigrator is a mock of Migrator interface.
+type MockMigrator struct {
+	ctrl     *gomock.Controller
+	recorder *MockMigratorMockRecorder
+}
+
+// MockMigratorMockRecorder is the mock recorder for MockMigrator.
+type MockMigratorMockRecorder struct {
+	mock *MockMigrator
+}
+
+// NewMockMigrator creates a new mock instance.
+func NewMockMigrator(ctrl *gomock.Controller) *MockMigrator {
+	mock := &MockMigrator{ctrl: ctrl}
+	mock.recorder = &MockMigratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMigrator) EXPECT() *MockMigratorMockRecorder {
+	return m.recorder
+}
+
+// Down mocks base method.
+func (m *MockMigrator) Down(ctx context.Context, steps int) ([]migrations.Migration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Down", ctx, steps)
+	ret0, _ := ret[0].([]migrations.Migration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Down indicates an expected call of Down.
+func (mr *MockMigratorMockRecorder) Down(ctx, steps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Down", reflect.TypeOf((*MockMigrator)(nil).Down), ctx, steps)
+}
+
+// Status mocks base method.
+func (m *MockMigrator) Status(ctx context.Context) ([]migrations.Status, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx)
+	ret0, _ := ret[0].([]migrations.Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockMigratorMockRecorder) Status(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockMigrator)(nil).Status), ctx)
+}
+
+// Up mocks base method.
+func (m *MockMigrator) Up(ctx context.Context) ([]migrations.Migration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Up", ctx)
+	ret0, _ := ret[0].([]migrations.Migration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Up indicates an expected call of Up.
+func (mr *MockMigratorMockRecorder) Up(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Up", reflect.TypeOf((*MockMigrator)(nil).Up), ctx)
+}
@@ -6,7 +6,10 @@ package mock_redis
 
 import (
 	context "context"
+	entity "redis-postgres-service/entity"
+	redis "redis-postgres-service/repository/redis"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,6 +37,54 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AcquireKey mocks base method.
+func (m *MockRepository) AcquireKey(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireKey", ctx, key, value, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AcquireKey indicates an expected call of AcquireKey.
+func (mr *MockRepositoryMockRecorder) AcquireKey(ctx, key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireKey", reflect.TypeOf((*MockRepository)(nil).AcquireKey), ctx, key, value, ttl)
+}
+
+// AddDecimalValueForKey mocks base method.
+func (m *MockRepository) AddDecimalValueForKey(ctx context.Context, key, value string, ttl time.Duration) (string, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDecimalValueForKey", ctx, key, value, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddDecimalValueForKey indicates an expected call of AddDecimalValueForKey.
+func (mr *MockRepositoryMockRecorder) AddDecimalValueForKey(ctx, key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDecimalValueForKey", reflect.TypeOf((*MockRepository)(nil).AddDecimalValueForKey), ctx, key, value, ttl)
+}
+
+// AddFloatValueForKey mocks base method.
+func (m *MockRepository) AddFloatValueForKey(ctx context.Context, key string, value float64, ttl time.Duration) (string, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFloatValueForKey", ctx, key, value, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddFloatValueForKey indicates an expected call of AddFloatValueForKey.
+func (mr *MockRepositoryMockRecorder) AddFloatValueForKey(ctx, key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFloatValueForKey", reflect.TypeOf((*MockRepository)(nil).AddFloatValueForKey), ctx, key, value, ttl)
+}
+
 // AddIntValueForKey mocks base method.
 func (m *MockRepository) AddIntValueForKey(ctx context.Context, key string, value int64) (int64, error) {
 	m.ctrl.T.Helper()
@@ -48,3 +99,108 @@ func (mr *MockRepositoryMockRecorder) AddIntValueForKey(ctx, key, value interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIntValueForKey", reflect.TypeOf((*MockRepository)(nil).AddIntValueForKey), ctx, key, value)
 }
+
+// AddIntValueForKeyWithOptions mocks base method.
+func (m *MockRepository) AddIntValueForKeyWithOptions(ctx context.Context, key string, value int64, opts redis.IncrementOptions) (int64, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddIntValueForKeyWithOptions", ctx, key, value, opts)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddIntValueForKeyWithOptions indicates an expected call of AddIntValueForKeyWithOptions.
+func (mr *MockRepositoryMockRecorder) AddIntValueForKeyWithOptions(ctx, key, value, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIntValueForKeyWithOptions", reflect.TypeOf((*MockRepository)(nil).AddIntValueForKeyWithOptions), ctx, key, value, opts)
+}
+
+// AddIntValuesForKeys mocks base method.
+func (m *MockRepository) AddIntValuesForKeys(ctx context.Context, items []entity.IncrementItem, atomic bool) ([]redis.IncrementResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddIntValuesForKeys", ctx, items, atomic)
+	ret0, _ := ret[0].([]redis.IncrementResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddIntValuesForKeys indicates an expected call of AddIntValuesForKeys.
+func (mr *MockRepositoryMockRecorder) AddIntValuesForKeys(ctx, items, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIntValuesForKeys", reflect.TypeOf((*MockRepository)(nil).AddIntValuesForKeys), ctx, items, atomic)
+}
+
+// DeleteKey mocks base method.
+func (m *MockRepository) DeleteKey(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteKey indicates an expected call of DeleteKey.
+func (mr *MockRepositoryMockRecorder) DeleteKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKey", reflect.TypeOf((*MockRepository)(nil).DeleteKey), ctx, key)
+}
+
+// GetValueForKey mocks base method.
+func (m *MockRepository) GetValueForKey(ctx context.Context, key string) (string, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValueForKey", ctx, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetValueForKey indicates an expected call of GetValueForKey.
+func (mr *MockRepositoryMockRecorder) GetValueForKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValueForKey", reflect.TypeOf((*MockRepository)(nil).GetValueForKey), ctx, key)
+}
+
+// HitSlidingWindow mocks base method.
+func (m *MockRepository) HitSlidingWindow(ctx context.Context, key string, limit int64, window time.Duration, now time.Time) (redis.SlidingWindowResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HitSlidingWindow", ctx, key, limit, window, now)
+	ret0, _ := ret[0].(redis.SlidingWindowResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HitSlidingWindow indicates an expected call of HitSlidingWindow.
+func (mr *MockRepositoryMockRecorder) HitSlidingWindow(ctx, key, limit, window, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HitSlidingWindow", reflect.TypeOf((*MockRepository)(nil).HitSlidingWindow), ctx, key, limit, window, now)
+}
+
+// PeekSlidingWindow mocks base method.
+func (m *MockRepository) PeekSlidingWindow(ctx context.Context, key string, limit int64, window time.Duration, now time.Time) (redis.SlidingWindowResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeekSlidingWindow", ctx, key, limit, window, now)
+	ret0, _ := ret[0].(redis.SlidingWindowResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeekSlidingWindow indicates an expected call of PeekSlidingWindow.
+func (mr *MockRepositoryMockRecorder) PeekSlidingWindow(ctx, key, limit, window, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeekSlidingWindow", reflect.TypeOf((*MockRepository)(nil).PeekSlidingWindow), ctx, key, limit, window, now)
+}
+
+// SetKey mocks base method.
+func (m *MockRepository) SetKey(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetKey", ctx, key, value, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetKey indicates an expected call of SetKey.
+func (mr *MockRepositoryMockRecorder) SetKey(ctx, key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKey", reflect.TypeOf((*MockRepository)(nil).SetKey), ctx, key, value, ttl)
+}
@@ -0,0 +1,127 @@
+package keystore
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"sort"
+)
+
+const _secretsKey = "signing_keys"
+
+// Key is a single version of the named signing key. Algorithm is empty if the key can be used with any
+// of the symmetric algorithms. Internal keys are only used by the service itself.
+type Key struct {
+	ID        string
+	Version   int
+	Algorithm string
+	Secret    []byte
+	Internal  bool
+}
+
+type Keystore interface {
+	Key(ctx context.Context, id string, version int) (*Key, error)
+	Versions(ctx context.Context, id string) ([]Key, error)
+	Keys(ctx context.Context) ([]Key, error)
+}
+
+// compile time check that keystore implements Keystore interface
+var _ Keystore = (*keystore)(nil)
+
+// Params is an fx container for all Keystore dependencies
+type Params struct {
+	fx.In
+
+	ConfigProvider config.Provider
+}
+
+// New is a constructor provided to the fx for creating a Keystore. Keys are loaded from the `signing_keys`
+// section of the secrets, the service starts with an empty keystore if the section is missing.
+func New(p Params) (Keystore, error) {
+	var secrets map[string]internalconfig.SigningKeySecrets
+	err := p.ConfigProvider.Get(_secretsKey).Populate(&secrets)
+	if err != nil {
+		return nil, errors.Errorf("failed to populate secrets: %s", err)
+	}
+	keys := make(map[string]internalconfig.SigningKeySecrets, len(secrets))
+	for id, key := range secrets {
+		if _, ok := key.Versions[key.CurrentVersion]; !ok {
+			return nil, errors.Errorf("current version %d of the signing key %s is missing", key.CurrentVersion, id)
+		}
+		keys[id] = key
+	}
+	return &keystore{
+		keys: keys,
+	}, nil
+}
+
+type keystore struct {
+	keys map[string]internalconfig.SigningKeySecrets
+}
+
+// Key returns the version of the key with the id, version 0 stands for the current version.
+// entity.ErrNotFound is returned if there is no such key or version.
+func (k *keystore) Key(ctx context.Context, id string, version int) (*Key, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("signing key %s: %w", id, entity.ErrNotFound)
+	}
+	if version == 0 {
+		version = key.CurrentVersion
+	}
+	secret, ok := key.Versions[version]
+	if !ok {
+		return nil, fmt.Errorf("signing key %s version %d: %w", id, version, entity.ErrNotFound)
+	}
+	return &Key{
+		ID:        id,
+		Version:   version,
+		Algorithm: key.Algorithm,
+		Secret:    []byte(secret),
+		Internal:  key.Internal,
+	}, nil
+}
+
+// Versions returns all the versions of the key with the id starting from the newest one.
+// entity.ErrNotFound is returned if there is no such key.
+func (k *keystore) Versions(ctx context.Context, id string) ([]Key, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("signing key %s: %w", id, entity.ErrNotFound)
+	}
+	return versions(id, key), nil
+}
+
+// Keys returns all the versions of all the keys ordered by id, starting from the newest version of each key
+func (k *keystore) Keys(ctx context.Context) ([]Key, error) {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var keys []Key
+	for _, id := range ids {
+		keys = append(keys, versions(id, k.keys[id])...)
+	}
+	return keys, nil
+}
+
+// versions returns all the versions of the key starting from the newest one
+func versions(id string, key internalconfig.SigningKeySecrets) []Key {
+	versions := make([]Key, 0, len(key.Versions))
+	for version, secret := range key.Versions {
+		versions = append(versions, Key{
+			ID:        id,
+			Version:   version,
+			Algorithm: key.Algorithm,
+			Secret:    []byte(secret),
+			Internal:  key.Internal,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions
+}
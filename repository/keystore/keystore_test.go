@@ -0,0 +1,158 @@
+package keystore
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+	"redis-postgres-service/entity"
+	"strings"
+	"testing"
+)
+
+const _testSecrets = `
+signing_keys:
+  partner-a:
+    current_version: 2
+    versions:
+      1: secret-v1
+      2: secret-v2
+      3: secret-v3
+  webhooks:
+    algorithm: ed25519
+    current_version: 1
+    versions:
+      1: private-key-v1
+  tokens:
+    internal: true
+    current_version: 1
+    versions:
+      1: tokens-v1
+`
+
+func newTestKeystore(t *testing.T) Keystore {
+	provider, err := config.NewYAML(config.Source(strings.NewReader(_testSecrets)))
+	assert.NoError(t, err)
+	k, err := New(Params{ConfigProvider: provider})
+	assert.NoError(t, err)
+	return k
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		secrets   string
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			secrets:   _testSecrets,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, no signing keys",
+			secrets:   `{"redis_secrets":{"password":"qwerty"}}`,
+			assertion: assert.NoError,
+		},
+		{
+			name: "Current version is missing",
+			secrets: `
+signing_keys:
+  partner-a:
+    current_version: 2
+    versions:
+      1: secret-v1
+`,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := config.NewYAML(config.Source(strings.NewReader(tt.secrets)))
+			assert.NoError(t, err)
+			_, err = New(Params{ConfigProvider: provider})
+			tt.assertion(t, err)
+		})
+	}
+}
+
+func Test_keystore_Key(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		version   int
+		want      *Key
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, current version",
+			id:        "partner-a",
+			want:      &Key{ID: "partner-a", Version: 2, Secret: []byte("secret-v2")},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, pinned version",
+			id:        "partner-a",
+			version:   1,
+			want:      &Key{ID: "partner-a", Version: 1, Secret: []byte("secret-v1")},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, internal key",
+			id:        "tokens",
+			want:      &Key{ID: "tokens", Version: 1, Secret: []byte("tokens-v1"), Internal: true},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Unknown key",
+			id:        "partner-b",
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Unknown version",
+			id:        "partner-a",
+			version:   4,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := newTestKeystore(t)
+			got, err := k.Key(context.Background(), tt.id, tt.version)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_keystore_Versions(t *testing.T) {
+	k := newTestKeystore(t)
+	got, err := k.Versions(context.Background(), "partner-a")
+	assert.NoError(t, err)
+	assert.Equal(t, []Key{
+		{ID: "partner-a", Version: 3, Secret: []byte("secret-v3")},
+		{ID: "partner-a", Version: 2, Secret: []byte("secret-v2")},
+		{ID: "partner-a", Version: 1, Secret: []byte("secret-v1")},
+	}, got)
+
+	_, err = k.Versions(context.Background(), "partner-b")
+	assert.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func Test_keystore_Keys(t *testing.T) {
+	k := newTestKeystore(t)
+	got, err := k.Keys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Key{
+		{ID: "partner-a", Version: 3, Secret: []byte("secret-v3")},
+		{ID: "partner-a", Version: 2, Secret: []byte("secret-v2")},
+		{ID: "partner-a", Version: 1, Secret: []byte("secret-v1")},
+		{ID: "tokens", Version: 1, Secret: []byte("tokens-v1"), Internal: true},
+		{ID: "webhooks", Version: 1, Algorithm: "ed25519", Secret: []byte("private-key-v1")},
+	}, got)
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"go.uber.org/fx"
+	"redis-postgres-service/repository/keystore"
 	"redis-postgres-service/repository/postgres"
+	"redis-postgres-service/repository/postgres/migrations"
 	"redis-postgres-service/repository/postgres/pgfx"
 
 	"redis-postgres-service/repository/redis"
@@ -10,6 +12,8 @@ import (
 
 var Module = fx.Options(
 	pgfx.Module,
+	fx.Provide(migrations.New),
 	fx.Provide(postgres.New),
 	fx.Provide(redis.New),
+	fx.Provide(keystore.New),
 )
@@ -0,0 +1,300 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"go.uber.org/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"io/fs"
+	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/repository/postgres/pgfx"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const _configKey = "postgres_repo_config"
+
+const (
+	// _advisoryLockKey is an arbitrary key of the transaction level advisory lock that prevents
+	// concurrent replicas from running the migrations at the same time
+	_advisoryLockKey = 7250019457231
+
+	_setSearchPathQuery         = `SET LOCAL search_path TO %s`
+	_advisoryLockQuery          = `SELECT pg_advisory_xact_lock($1)`
+	_createMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations
+					(
+					    version BIGINT PRIMARY KEY,
+					    name TEXT NOT NULL,
+					    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+					);`
+	_selectAppliedMigrationsQuery = `SELECT version, applied_at FROM schema_migrations ORDER BY version`
+	_insertMigrationQuery         = `INSERT INTO schema_migrations(version, name) VALUES ($1, $2)`
+	_deleteMigrationQuery         = `DELETE FROM schema_migrations WHERE version = $1`
+)
+
+//go:embed sql/*.sql
+var _sqlFiles embed.FS
+
+var _fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a single versioned schema change with the statements to apply and to revert it
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes if the migration is applied to the database. AppliedAt is nil for pending migrations.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator interface {
+	Up(ctx context.Context) ([]Migration, error)
+	Down(ctx context.Context, steps int) ([]Migration, error)
+	Status(ctx context.Context) ([]Status, error)
+}
+
+// compile time check that migrator implements Migrator interface
+var _ Migrator = (*migrator)(nil)
+
+// Params is an fx container for all Migrator dependencies
+type Params struct {
+	fx.In
+
+	Postgres       pgfx.Postgres
+	Logger         *zap.Logger
+	ConfigProvider config.Provider
+}
+
+// New is a constructor provided to the fx for creating a Migrator
+func New(p Params) (Migrator, error) {
+	var cfg internalconfig.PostgresRepoConfig
+	err := p.ConfigProvider.Get(_configKey).Populate(&cfg)
+	if err != nil {
+		return nil, errors.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
+	}
+	migrations, err := load(_sqlFiles)
+	if err != nil {
+		return nil, errors.Errorf("failed to load migrations: %s", err) // unreachable in tests, cause embedded files are valid.
+	}
+	return &migrator{
+		logger:         p.Logger,
+		postgresClient: p.Postgres,
+		schema:         cfg.Schema,
+		migrations:     migrations,
+	}, nil
+}
+
+type migrator struct {
+	logger         *zap.Logger
+	postgresClient pgfx.Postgres
+	schema         string
+	migrations     []Migration
+}
+
+// Up applies all the pending migrations in the version order and returns the applied ones
+func (m *migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.inLockedTx(ctx, func(tx pgx.Tx) error {
+		appliedAt, err := appliedVersions(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := appliedAt[migration.Version]; ok {
+				continue
+			}
+			if _, err = tx.Exec(ctx, migration.Up); err != nil {
+				return errors.Errorf("failed to apply migration %d_%s: %s", migration.Version, migration.Name, err)
+			}
+			if _, err = tx.Exec(ctx, _insertMigrationQuery, migration.Version, migration.Name); err != nil {
+				return err
+			}
+			m.logger.With(
+				zap.Int64("version", migration.Version),
+				zap.String("name", migration.Name),
+			).Info("migration applied")
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// Down reverts up to steps last applied migrations in the reverse version order and returns the reverted ones
+func (m *migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.inLockedTx(ctx, func(tx pgx.Tx) error {
+		appliedAt, err := appliedVersions(ctx, tx)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(appliedAt))
+		for version := range appliedAt {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		for i := 0; i < steps && i < len(versions); i++ {
+			migration, ok := m.find(versions[i])
+			if !ok {
+				return errors.Errorf("applied migration %d is unknown to this build", versions[i])
+			}
+			if _, err = tx.Exec(ctx, migration.Down); err != nil {
+				return errors.Errorf("failed to revert migration %d_%s: %s", migration.Version, migration.Name, err)
+			}
+			if _, err = tx.Exec(ctx, _deleteMigrationQuery, migration.Version); err != nil {
+				return err
+			}
+			m.logger.With(
+				zap.Int64("version", migration.Version),
+				zap.String("name", migration.Name),
+			).Info("migration reverted")
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reverted, nil
+}
+
+// Status returns all the known migrations in the version order with the time they were applied at
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.inLockedTx(ctx, func(tx pgx.Tx) error {
+		appliedAt, err := appliedVersions(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if at, ok := appliedAt[migration.Version]; ok {
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// inLockedTx runs fn in a transaction that holds the migrations advisory lock and has the search path
+// set to the configured schema. Transaction is committed if fn succeeds and rolled back otherwise.
+func (m *migrator) inLockedTx(ctx context.Context, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := m.postgresClient.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			m.logger.With(zap.Error(err)).Error("migration transaction rollback")
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+	if _, err = tx.Exec(ctx, fmt.Sprintf(_setSearchPathQuery, pgx.Identifier{m.schema}.Sanitize())); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, _advisoryLockQuery, _advisoryLockKey); err != nil {
+		return errors.Errorf("failed to acquire migrations lock: %s", err)
+	}
+	if _, err = tx.Exec(ctx, _createMigrationsTableQuery); err != nil {
+		return errors.Errorf("failed to create migrations table: %s", err)
+	}
+	return fn(tx)
+}
+
+// find returns the known migration with the version
+func (m *migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// appliedVersions returns the time of application for every migration version recorded in the database
+func appliedVersions(ctx context.Context, tx pgx.Tx) (map[int64]time.Time, error) {
+	rows, err := tx.Query(ctx, _selectAppliedMigrationsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err = rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// load reads the migrations from the `sql` directory of fsys. Every migration is expected to be represented
+// by a pair of <version>_<name>.up.sql and <version>_<name>.down.sql files.
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := _fileNameRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, errors.Errorf("unexpected migration file name %s", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("unexpected migration version in %s: %s", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, "sql/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, errors.Errorf("migration %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, errors.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -0,0 +1,289 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+	"go.uber.org/zap"
+	mock_pgfx "redis-postgres-service/mocks/repository/postgres/pgfx"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var _testMigrations = []Migration{
+	{Version: 1, Name: "create_users_table", Up: "CREATE TABLE users", Down: "DROP TABLE users"},
+	{Version: 2, Name: "add_users_email", Up: "ALTER TABLE users ADD email", Down: "ALTER TABLE users DROP email"},
+}
+
+func TestNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	provider, _ := config.NewYAML(config.Source(strings.NewReader(`{"postgres_repo_config":{"schema":"public"}}`)))
+	m, err := New(Params{
+		Postgres:       mock_pgfx.NewMockPostgres(ctrl),
+		Logger:         zap.NewNop(),
+		ConfigProvider: provider,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, m)
+	assert.NotEmpty(t, m.(*migrator).migrations)
+}
+
+func Test_load(t *testing.T) {
+	tests := []struct {
+		name      string
+		fsys      fstest.MapFS
+		want      []Migration
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path",
+			fsys: fstest.MapFS{
+				"sql/0002_add_users_email.up.sql":      {Data: []byte("ALTER TABLE users ADD email")},
+				"sql/0002_add_users_email.down.sql":    {Data: []byte("ALTER TABLE users DROP email")},
+				"sql/0001_create_users_table.up.sql":   {Data: []byte("CREATE TABLE users")},
+				"sql/0001_create_users_table.down.sql": {Data: []byte("DROP TABLE users")},
+			},
+			want:      _testMigrations,
+			assertion: assert.NoError,
+		},
+		{
+			name: "Down file is missing",
+			fsys: fstest.MapFS{
+				"sql/0001_create_users_table.up.sql": {Data: []byte("CREATE TABLE users")},
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name: "Unexpected file name",
+			fsys: fstest.MapFS{
+				"sql/create_users_table.sql": {Data: []byte("CREATE TABLE users")},
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name: "Same version has different names",
+			fsys: fstest.MapFS{
+				"sql/0001_create_users_table.up.sql": {Data: []byte("CREATE TABLE users")},
+				"sql/0001_create_users.down.sql":     {Data: []byte("DROP TABLE users")},
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := load(tt.fsys)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_load_embedded(t *testing.T) {
+	got, err := load(_sqlFiles)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got[0].Version)
+	assert.Equal(t, "create_users_table", got[0].Name)
+}
+
+// expectLockedTx sets up the expectations for the statements executed by inLockedTx before fn is called and
+// returns the mocked transaction. appliedVersions are returned by the schema_migrations query.
+func expectLockedTx(
+	ctrl *gomock.Controller,
+	ctx context.Context,
+	postgres *mock_pgfx.MockPostgres,
+	lockErr error,
+	applied map[int64]time.Time,
+) *mock_pgfx.MockTx {
+	tx := mock_pgfx.NewMockTx(ctrl)
+	postgres.EXPECT().BeginTx(ctx, gomock.Any()).Return(tx, nil)
+	tx.EXPECT().Exec(ctx, `SET LOCAL search_path TO "public"`).Return(pgconn.NewCommandTag("SET"), nil)
+	tx.EXPECT().Exec(ctx, _advisoryLockQuery, gomock.Any()).Return(pgconn.NewCommandTag("SELECT 1"), lockErr)
+	if lockErr != nil {
+		tx.EXPECT().Rollback(ctx).Return(nil)
+		return tx
+	}
+	tx.EXPECT().Exec(ctx, _createMigrationsTableQuery).Return(pgconn.NewCommandTag("CREATE TABLE"), nil)
+	rows := mock_pgfx.NewMockRows(ctrl)
+	tx.EXPECT().Query(ctx, _selectAppliedMigrationsQuery).Return(rows, nil)
+	rows.EXPECT().Close()
+	for version, at := range applied {
+		version, at := version, at
+		rows.EXPECT().Next().Return(true)
+		rows.EXPECT().Scan(gomock.Any(), gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+			*dest[0].(*int64) = version
+			*dest[1].(*time.Time) = at
+			return nil
+		})
+	}
+	rows.EXPECT().Next().Return(false)
+	rows.EXPECT().Err().Return(nil)
+	return tx
+}
+
+func Test_migrator_Up(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	appliedAt := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		applied    map[int64]time.Time
+		lockErr    error
+		migrateErr error
+		want       []Migration
+		assertion  assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, all pending",
+			applied:   map[int64]time.Time{},
+			want:      _testMigrations,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, first is already applied",
+			applied:   map[int64]time.Time{1: appliedAt},
+			want:      _testMigrations[1:],
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Lock fails",
+			lockErr:   errors.New("some error"),
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:       "Migration fails",
+			applied:    map[int64]time.Time{1: appliedAt},
+			migrateErr: errors.New("some error"),
+			want:       nil,
+			assertion:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			postgres := mock_pgfx.NewMockPostgres(ctrl)
+			tx := expectLockedTx(ctrl, ctx, postgres, tt.lockErr, tt.applied)
+			if tt.lockErr == nil {
+				for _, migration := range _testMigrations {
+					if _, ok := tt.applied[migration.Version]; ok {
+						continue
+					}
+					tx.EXPECT().Exec(ctx, migration.Up).Return(pgconn.NewCommandTag("OK"), tt.migrateErr)
+					if tt.migrateErr != nil {
+						break
+					}
+					tx.EXPECT().
+						Exec(ctx, _insertMigrationQuery, migration.Version, migration.Name).
+						Return(pgconn.NewCommandTag("INSERT 1"), nil)
+				}
+				if tt.migrateErr != nil {
+					tx.EXPECT().Rollback(ctx).Return(nil)
+				} else {
+					tx.EXPECT().Commit(ctx).Return(nil)
+				}
+			}
+			m := &migrator{
+				logger:         zap.NewNop(),
+				postgresClient: postgres,
+				schema:         "public",
+				migrations:     _testMigrations,
+			}
+			got, err := m.Up(ctx)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_migrator_Down(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	appliedAt := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		steps     int
+		applied   map[int64]time.Time
+		want      []Migration
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, last one is reverted",
+			steps:     1,
+			applied:   map[int64]time.Time{1: appliedAt, 2: appliedAt},
+			want:      []Migration{_testMigrations[1]},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, steps exceed applied migrations",
+			steps:     5,
+			applied:   map[int64]time.Time{1: appliedAt},
+			want:      []Migration{_testMigrations[0]},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Applied migration is unknown",
+			steps:     1,
+			applied:   map[int64]time.Time{3: appliedAt},
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			postgres := mock_pgfx.NewMockPostgres(ctrl)
+			tx := expectLockedTx(ctrl, ctx, postgres, nil, tt.applied)
+			if tt.want != nil {
+				for _, migration := range tt.want {
+					tx.EXPECT().Exec(ctx, migration.Down).Return(pgconn.NewCommandTag("OK"), nil)
+					tx.EXPECT().
+						Exec(ctx, _deleteMigrationQuery, migration.Version).
+						Return(pgconn.NewCommandTag("DELETE 1"), nil)
+				}
+				tx.EXPECT().Commit(ctx).Return(nil)
+			} else {
+				tx.EXPECT().Rollback(ctx).Return(nil)
+			}
+			m := &migrator{
+				logger:         zap.NewNop(),
+				postgresClient: postgres,
+				schema:         "public",
+				migrations:     _testMigrations,
+			}
+			got, err := m.Down(ctx, tt.steps)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_migrator_Status(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	appliedAt := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	postgres := mock_pgfx.NewMockPostgres(ctrl)
+	tx := expectLockedTx(ctrl, ctx, postgres, nil, map[int64]time.Time{1: appliedAt})
+	tx.EXPECT().Commit(ctx).Return(nil)
+	m := &migrator{
+		logger:         zap.NewNop(),
+		postgresClient: postgres,
+		schema:         "public",
+		migrations:     _testMigrations,
+	}
+	got, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{
+		{Migration: _testMigrations[0], AppliedAt: &appliedAt},
+		{Migration: _testMigrations[1]},
+	}, got)
+}
@@ -0,0 +1,63 @@
+package pgfx
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// compile time check that poolCollector implements prometheus.Collector interface
+var _ prometheus.Collector = (*poolCollector)(nil)
+
+// poolCollector reports the stats of the connection pool on every scrape
+type poolCollector struct {
+	stat func() *pgxpool.Stat
+
+	acquired        *prometheus.Desc
+	idle            *prometheus.Desc
+	total           *prometheus.Desc
+	max             *prometheus.Desc
+	acquires        *prometheus.Desc
+	emptyWaits      *prometheus.Desc
+	canceled        *prometheus.Desc
+	acquireDuration *prometheus.Desc
+}
+
+func newPoolCollector(stat func() *pgxpool.Stat) *poolCollector {
+	return &poolCollector{
+		stat:     stat,
+		acquired: prometheus.NewDesc("pgxpool_acquired_connections", "Number of the connections currently acquired from the pool.", nil, nil),
+		idle:     prometheus.NewDesc("pgxpool_idle_connections", "Number of the idle connections in the pool.", nil, nil),
+		total:    prometheus.NewDesc("pgxpool_total_connections", "Number of the connections in the pool, including the ones being established.", nil, nil),
+		max:      prometheus.NewDesc("pgxpool_max_connections", "Maximum size of the pool.", nil, nil),
+		acquires: prometheus.NewDesc("pgxpool_acquires_total", "Number of the successful acquires of the connections.", nil, nil),
+		emptyWaits: prometheus.NewDesc(
+			"pgxpool_empty_acquires_total",
+			"Number of the successful acquires that waited for a connection, cause the pool was empty.",
+			nil, nil,
+		),
+		canceled: prometheus.NewDesc("pgxpool_canceled_acquires_total", "Number of the acquires canceled by the context.", nil, nil),
+		// the pool doesn't measure the wait for a free connection apart from the acquire, the waits are counted
+		// by pgxpool_empty_acquires_total
+		acquireDuration: prometheus.NewDesc(
+			"pgxpool_acquire_duration_seconds_total",
+			"Total duration of the successful acquires, it is not the wait for a free connection alone.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyWaits, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceled, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/metrics"
 )
 
 const (
@@ -29,11 +30,15 @@ type Params struct {
 	LC             fx.Lifecycle
 	ConfigProvider config.Provider
 	Logger         *zap.Logger
+	Metrics        metrics.Registry
 }
 
 type Postgres interface {
 	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
 }
 
 func New(p Params) (Postgres, error) {
@@ -62,6 +67,10 @@ func New(p Params) (Postgres, error) {
 	if err != nil {
 		return nil, errors.Errorf("failed to create a db: %s", err)
 	}
+	if err = p.Metrics.Register(newPoolCollector(dbpool.Stat)); err != nil {
+		dbpool.Close()
+		return nil, errors.Errorf("failed to register metrics: %s", err)
+	}
 	p.LC.Append(
 		fx.Hook{
 			OnStop: func(ctx context.Context) error {
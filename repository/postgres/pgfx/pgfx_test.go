@@ -1,6 +1,7 @@
 package pgfx
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/config"
 	"go.uber.org/fx/fxtest"
@@ -67,6 +68,7 @@ func TestNew(t *testing.T) {
 				LC:             testlc,
 				ConfigProvider: tt.args.p,
 				Logger:         logger,
+				Metrics:        prometheus.NewRegistry(),
 			})
 			tt.assertion(t, err)
 			if err == nil {
@@ -2,31 +2,64 @@ package postgres
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pkg/errors"
 	"go.uber.org/config"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+	"net"
 	internalconfig "redis-postgres-service/config"
 	"redis-postgres-service/entity"
+	"redis-postgres-service/repository/postgres/migrations"
 	"redis-postgres-service/repository/postgres/pgfx"
+	"strings"
 )
 
 const _configKey = "postgres_repo_config"
 
+// _defaultBulkBatchSize is used if the bulk batch size is not configured
+const _defaultBulkBatchSize = 1000
+
 const (
-	_createUsersTableQuery = `CREATE TABLE IF NOT EXISTS %s.users 
-					(
-					    id SERIAL PRIMARY KEY,
-    			   		name TEXT,  
-    			   		age INT
-					);`
 	_insertUserQuery = `INSERT INTO %s.users(name, age) VALUES ($1, $2) RETURNING id`
+	_selectUserQuery = `SELECT id, COALESCE(name, ''), COALESCE(age, 0) FROM %s.users WHERE id = $1`
+	_listUsersQuery  = `SELECT id, COALESCE(name, ''), COALESCE(age, 0) FROM %s.users ORDER BY id LIMIT $1 OFFSET $2`
+	_updateUserQuery = `UPDATE %s.users SET name = COALESCE($2, name), age = COALESCE($3, age) WHERE id = $1
+					RETURNING id, COALESCE(name, ''), COALESCE(age, 0)`
+	_deleteUserQuery = `DELETE FROM %s.users WHERE id = $1 RETURNING id, COALESCE(name, ''), COALESCE(age, 0)`
+	// _reserveUserIdsQuery takes the ids for the rows copied in bulk, since COPY can't return the generated ones
+	_reserveUserIdsQuery = `SELECT nextval(pg_get_serial_sequence('%s.users', 'id')) FROM generate_series(1, $1)`
 )
 
+// _unavailableCodes are the SQLSTATE codes of the server that can't serve the requests at the moment,
+// besides the connection exceptions of the class 08
+var _unavailableCodes = map[string]bool{
+	"53300": true, // too_many_connections
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// _usersColumns are the columns populated by the bulk copy
+var _usersColumns = []string{"id", "name", "age"}
+
+// bulkExecutor is implemented by both pgfx.Postgres and pgx.Tx, so that the batches can be copied
+// either independently or within a single transaction
+type bulkExecutor interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
 type Repository interface {
 	AddUser(ctx context.Context, request *entity.AddUserRequest) (*entity.AddUserResponse, error)
+	AddUsers(ctx context.Context, request *entity.BulkAddUsersRequest) (*entity.BulkAddUsersResponse, error)
+	GetUser(ctx context.Context, request *entity.GetUserRequest) (*entity.User, error)
+	ListUsers(ctx context.Context, request *entity.ListUsersRequest) ([]entity.User, error)
+	UpdateUser(ctx context.Context, request *entity.UpdateUserRequest) (*entity.User, error)
+	DeleteUser(ctx context.Context, request *entity.DeleteUserRequest) (*entity.User, error)
 }
 
 // compile time check that repository implements Repository interface
@@ -37,6 +70,7 @@ type Params struct {
 	fx.In
 
 	Postgres       pgfx.Postgres
+	Migrator       migrations.Migrator
 	Logger         *zap.Logger
 	ConfigProvider config.Provider
 }
@@ -49,12 +83,17 @@ func New(p Params) (Repository, error) {
 		return nil, errors.Errorf("failed to populate config: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
 	}
 
-	query := fmt.Sprintf(_createUsersTableQuery, cfg.Schema)
-	tag, err := p.Postgres.Exec(context.Background(), query)
-	if err != nil {
-		return nil, errors.Errorf("failed to create a user table: %s", err)
+	if cfg.AutoMigrate {
+		applied, err := p.Migrator.Up(context.Background())
+		if err != nil {
+			return nil, errors.Errorf("failed to migrate the schema: %s", err)
+		}
+		p.Logger.With(zap.Int("applied", len(applied))).Info("schema migrations completed")
+	}
+
+	if cfg.BulkBatchSize <= 0 {
+		cfg.BulkBatchSize = _defaultBulkBatchSize
 	}
-	p.Logger.With(zap.String("result", tag.String())).Info("dbpool result")
 
 	return &repository{
 		logger:         p.Logger,
@@ -75,7 +114,7 @@ func (r *repository) AddUser(ctx context.Context, request *entity.AddUserRequest
 	tx, err := r.postgresClient.BeginTx(ctx, pgx.TxOptions{})
 
 	if err != nil {
-		return nil, err
+		return nil, postgresError(err)
 	}
 	defer func() {
 		if err != nil {
@@ -96,10 +135,206 @@ func (r *repository) AddUser(ctx context.Context, request *entity.AddUserRequest
 		request.Age,
 	).
 		Scan(&id); err != nil {
-		return nil, err
+		return nil, postgresError(err)
 	}
 
 	return &entity.AddUserResponse{
 		Id: id,
-	}, err
+	}, nil
+}
+
+// AddUsers appends the rows to the 'users' table with COPY in batches of the configured size and returns
+// the ids of the added rows. In atomic mode all the batches are copied in a single transaction and the error
+// is returned if any of them fails. Otherwise the failed batches are reported in the response.
+func (r *repository) AddUsers(
+	ctx context.Context,
+	request *entity.BulkAddUsersRequest,
+) (*entity.BulkAddUsersResponse, error) {
+	logger := r.logger.With(zap.String("scope", "repository.addusers"))
+	response := &entity.BulkAddUsersResponse{
+		Ids: make([]int64, len(request.Users)),
+	}
+	if !request.Atomic {
+		for offset := 0; offset < len(request.Users); offset += r.config.BulkBatchSize {
+			end := r.batchEnd(offset, len(request.Users))
+			err := r.copyUsers(ctx, r.postgresClient, request.Users[offset:end], response.Ids[offset:end])
+			if err != nil {
+				logger.
+					With(zap.Int("offset", offset), zap.Error(err)).
+					Error("batch copy failed")
+				response.Errors = append(response.Errors, entity.BulkAddUsersError{
+					Offset: offset,
+					Count:  end - offset,
+					Error:  err.Error(),
+				})
+			}
+		}
+		return response, nil
+	}
+
+	tx, err := r.postgresClient.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, postgresError(err)
+	}
+	for offset := 0; offset < len(request.Users); offset += r.config.BulkBatchSize {
+		end := r.batchEnd(offset, len(request.Users))
+		if err = r.copyUsers(ctx, tx, request.Users[offset:end], response.Ids[offset:end]); err != nil {
+			logger.
+				With(zap.Int("offset", offset), zap.Error(err)).
+				Error("transaction rollback")
+			tx.Rollback(ctx)
+			return nil, postgresError(err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, postgresError(err)
+	}
+	return response, nil
+}
+
+// GetUser reads a single row from the 'users' table. entity.ErrNotFound is returned if there is no such row.
+func (r *repository) GetUser(ctx context.Context, request *entity.GetUserRequest) (*entity.User, error) {
+	query := fmt.Sprintf(_selectUserQuery, r.config.Schema)
+	return r.scanUser(
+		r.postgresClient.QueryRow(ctx, query, request.Id),
+		request.Id,
+	)
+}
+
+// ListUsers reads a page of rows from the 'users' table ordered by id
+func (r *repository) ListUsers(ctx context.Context, request *entity.ListUsersRequest) ([]entity.User, error) {
+	query := fmt.Sprintf(_listUsersQuery, r.config.Schema)
+	rows, err := r.postgresClient.Query(ctx, query, request.Limit, request.Offset)
+	if err != nil {
+		return nil, postgresError(err)
+	}
+	defer rows.Close()
+	users := make([]entity.User, 0, request.Limit)
+	for rows.Next() {
+		var user entity.User
+		if err = rows.Scan(&user.Id, &user.Name, &user.Age); err != nil {
+			return nil, postgresError(err)
+		}
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, postgresError(err)
+	}
+	return users, nil
+}
+
+// UpdateUser updates the non-nil fields of the row in the 'users' table and returns the resulting row.
+// entity.ErrNotFound is returned if there is no such row.
+func (r *repository) UpdateUser(ctx context.Context, request *entity.UpdateUserRequest) (*entity.User, error) {
+	query := fmt.Sprintf(_updateUserQuery, r.config.Schema)
+	return r.scanUser(
+		r.postgresClient.QueryRow(ctx, query, request.Id, request.Name, request.Age),
+		request.Id,
+	)
+}
+
+// DeleteUser deletes the row from the 'users' table and returns the deleted row.
+// entity.ErrNotFound is returned if there is no such row.
+func (r *repository) DeleteUser(ctx context.Context, request *entity.DeleteUserRequest) (*entity.User, error) {
+	query := fmt.Sprintf(_deleteUserQuery, r.config.Schema)
+	return r.scanUser(
+		r.postgresClient.QueryRow(ctx, query, request.Id),
+		request.Id,
+	)
+}
+
+// batchEnd returns the end of the batch starting at offset
+func (r *repository) batchEnd(offset int, total int) int {
+	if end := offset + r.config.BulkBatchSize; end < total {
+		return end
+	}
+	return total
+}
+
+// copyUsers reserves the ids for the users, copies them into the 'users' table and writes the ids into ids.
+// ids are left zeroed if the copy fails.
+func (r *repository) copyUsers(
+	ctx context.Context,
+	executor bulkExecutor,
+	users []entity.AddUserRequest,
+	ids []int64,
+) error {
+	rows, err := executor.Query(ctx, fmt.Sprintf(_reserveUserIdsQuery, r.config.Schema), len(users))
+	if err != nil {
+		return err
+	}
+	reserved := make([]int64, 0, len(users))
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		reserved = append(reserved, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if len(reserved) != len(users) {
+		return errors.Errorf("reserved %d ids for %d users", len(reserved), len(users)) // unreachable in tests
+	}
+	copyRows := make([][]any, len(users))
+	for i, user := range users {
+		copyRows[i] = []any{reserved[i], user.Name, user.Age}
+	}
+	if _, err = executor.CopyFrom(
+		ctx,
+		pgx.Identifier{r.config.Schema, "users"},
+		_usersColumns,
+		pgx.CopyFromRows(copyRows),
+	); err != nil {
+		return err
+	}
+	copy(ids, reserved)
+	return nil
+}
+
+// scanUser scans the row returned by the single-user queries translating the missing row into entity.ErrNotFound
+func (r *repository) scanUser(row pgx.Row, id int64) (*entity.User, error) {
+	var user entity.User
+	err := row.Scan(&user.Id, &user.Name, &user.Age)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("user %d: %w", id, entity.ErrNotFound)
+	}
+	if err != nil {
+		return nil, postgresError(err)
+	}
+	return &user, nil
+}
+
+// postgresError maps the postgres errors into the entity errors: the unreachable database is reported
+// as entity.ErrUnavailable, unique violations as entity.ErrConflict and the rest of the integrity
+// and data exceptions as entity.ErrInvalidArgument. Messages of the postgres errors are kept out of the client
+// messages of the entity.PublicError. The rest of the errors are returned as is.
+func postgresError(err error) error {
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505": // unique_violation
+			return &entity.PublicError{
+				Message: "user conflicts with the stored users",
+				Err:     fmt.Errorf("%s: %w", err, entity.ErrConflict),
+			}
+		case strings.HasPrefix(pgErr.Code, "22"), strings.HasPrefix(pgErr.Code, "23"):
+			return &entity.PublicError{
+				Message: "user violates the constraints of the stored users",
+				Err:     fmt.Errorf("%s: %w", err, entity.ErrInvalidArgument),
+			}
+		case strings.HasPrefix(pgErr.Code, "08"), _unavailableCodes[pgErr.Code]:
+			return fmt.Errorf("%s: %w", err, entity.ErrUnavailable)
+		}
+		return err
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) || pgconn.Timeout(err) || stderrors.Is(err, context.DeadlineExceeded) ||
+		strings.Contains(err.Error(), "closed pool") {
+		return fmt.Errorf("%s: %w", err, entity.ErrUnavailable)
+	}
+	return err
 }
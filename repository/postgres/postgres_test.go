@@ -4,39 +4,61 @@ import (
 	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
 	"go.uber.org/zap"
+	"net"
+	internalconfig "redis-postgres-service/config"
 	"redis-postgres-service/entity"
+	mock_migrations "redis-postgres-service/mocks/repository/postgres/migrations"
 	mock_pgfx "redis-postgres-service/mocks/repository/postgres/pgfx"
+	"redis-postgres-service/repository/postgres/migrations"
+	"strings"
+	"syscall"
 	"testing"
 )
 
 func TestNew(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
-	type mockPostgres struct {
-		res pgconn.CommandTag
+	providerAutoMigrate, _ := config.NewYAML(config.Source(strings.NewReader(
+		`{"postgres_repo_config":{"schema":"public","auto_migrate":true}}`,
+	)))
+	providerNoMigrate, _ := config.NewYAML(config.Source(strings.NewReader(
+		`{"postgres_repo_config":{"schema":"public"}}`,
+	)))
+	type mockMigrator struct {
+		res []migrations.Migration
 		err error
 	}
 
 	tests := []struct {
 		name         string
-		mockPostgres *mockPostgres
+		provider     config.Provider
+		mockMigrator *mockMigrator
 		assertion    assert.ErrorAssertionFunc
 	}{
 		{
-			name: "Happy path",
-			mockPostgres: &mockPostgres{
-				res: pgconn.NewCommandTag("some tag"),
+			name:     "Happy path",
+			provider: providerAutoMigrate,
+			mockMigrator: &mockMigrator{
+				res: []migrations.Migration{{Version: 1, Name: "create_users_table"}},
 				err: nil,
 			},
 			assertion: assert.NoError,
 		},
 		{
-			name: "Table creation fails",
-			mockPostgres: &mockPostgres{
-				res: pgconn.NewCommandTag("some tag"),
+			name:      "Happy path, auto migration is disabled",
+			provider:  providerNoMigrate,
+			assertion: assert.NoError,
+		},
+		{
+			name:     "Migration fails",
+			provider: providerAutoMigrate,
+			mockMigrator: &mockMigrator{
+				res: nil,
 				err: errors.New("some error"),
 			},
 			assertion: assert.Error,
@@ -45,21 +67,20 @@ func TestNew(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			postgres := mock_pgfx.NewMockPostgres(ctrl)
-
-			postgres.EXPECT().
-				Exec(
-					ctx,
-					gomock.Any(),
-					gomock.Any(),
-				).
-				Return(
-					tt.mockPostgres.res,
-					tt.mockPostgres.err,
-				)
+			migrator := mock_migrations.NewMockMigrator(ctrl)
+			if tt.mockMigrator != nil {
+				migrator.EXPECT().
+					Up(ctx).
+					Return(
+						tt.mockMigrator.res,
+						tt.mockMigrator.err,
+					)
+			}
 			got, err := New(Params{
-				Logger:   zap.NewNop(),
-				Postgres: postgres,
+				Logger:         zap.NewNop(),
+				Postgres:       mock_pgfx.NewMockPostgres(ctrl),
+				Migrator:       migrator,
+				ConfigProvider: tt.provider,
 			})
 			if err == nil {
 				assert.NotNil(t, got)
@@ -72,6 +93,7 @@ func TestNew(t *testing.T) {
 func Test_repository_AddUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
+	age := 23
 	type mockPostgresBeginTx struct {
 		err error
 	}
@@ -103,7 +125,7 @@ func Test_repository_AddUser(t *testing.T) {
 			args: args{
 				request: &entity.AddUserRequest{
 					Name: "Name",
-					Age:  23,
+					Age:  &age,
 				},
 			},
 			mockPostgresBeginTx: &mockPostgresBeginTx{
@@ -126,7 +148,7 @@ func Test_repository_AddUser(t *testing.T) {
 			args: args{
 				request: &entity.AddUserRequest{
 					Name: "Name",
-					Age:  23,
+					Age:  &age,
 				},
 			},
 			mockPostgresBeginTx: &mockPostgresBeginTx{
@@ -140,7 +162,7 @@ func Test_repository_AddUser(t *testing.T) {
 			args: args{
 				request: &entity.AddUserRequest{
 					Name: "Name",
-					Age:  23,
+					Age:  &age,
 				},
 			},
 			mockPostgresBeginTx: &mockPostgresBeginTx{
@@ -213,6 +235,7 @@ func Test_repository_AddUser(t *testing.T) {
 			r := &repository{
 				logger:         zap.NewNop(),
 				postgresClient: mockPostgres,
+				config:         &internalconfig.PostgresRepoConfig{Schema: "public"},
 			}
 			got, err := r.AddUser(ctx, tt.args.request)
 			tt.assertion(t, err)
@@ -220,3 +243,519 @@ func Test_repository_AddUser(t *testing.T) {
 		})
 	}
 }
+
+// bulkRecorder is implemented by the recorders of both postgres and transaction mocks
+type bulkRecorder interface {
+	Query(ctx, sql interface{}, args ...interface{}) *gomock.Call
+	CopyFrom(ctx, tableName, columnNames, rowSrc interface{}) *gomock.Call
+}
+
+func Test_repository_AddUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	age1, age3 := 23, 25
+	users := []entity.AddUserRequest{{Name: "Name1", Age: &age1}, {Name: "Name2"}, {Name: "Name3", Age: &age3}}
+	type mockBatch struct {
+		ids      []int64
+		queryErr error
+		copyErr  error
+	}
+	tests := []struct {
+		name        string
+		atomic      bool
+		beginErr    error
+		mockBatches []mockBatch
+		want        *entity.BulkAddUsersResponse
+		assertion   assert.ErrorAssertionFunc
+	}{
+		{
+			name:        "Happy path, best effort",
+			mockBatches: []mockBatch{{ids: []int64{1, 2}}, {ids: []int64{3}}},
+			want:        &entity.BulkAddUsersResponse{Ids: []int64{1, 2, 3}},
+			assertion:   assert.NoError,
+		},
+		{
+			name: "Best effort, failed batches are reported",
+			mockBatches: []mockBatch{
+				{queryErr: errors.New("some error")},
+				{ids: []int64{3}},
+			},
+			want: &entity.BulkAddUsersResponse{
+				Ids:    []int64{0, 0, 3},
+				Errors: []entity.BulkAddUsersError{{Offset: 0, Count: 2, Error: "some error"}},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "Best effort, ids of the failed copy are not returned",
+			mockBatches: []mockBatch{
+				{ids: []int64{1, 2}},
+				{ids: []int64{3}, copyErr: errors.New("some error")},
+			},
+			want: &entity.BulkAddUsersResponse{
+				Ids:    []int64{1, 2, 0},
+				Errors: []entity.BulkAddUsersError{{Offset: 2, Count: 1, Error: "some error"}},
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name:        "Happy path, atomic",
+			atomic:      true,
+			mockBatches: []mockBatch{{ids: []int64{1, 2}}, {ids: []int64{3}}},
+			want:        &entity.BulkAddUsersResponse{Ids: []int64{1, 2, 3}},
+			assertion:   assert.NoError,
+		},
+		{
+			name:   "Atomic, copy fails",
+			atomic: true,
+			mockBatches: []mockBatch{
+				{ids: []int64{1, 2}},
+				{ids: []int64{3}, copyErr: errors.New("some error")},
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Atomic, begin tx fails",
+			atomic:    true,
+			beginErr:  errors.New("some error"),
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockPostgres := mock_pgfx.NewMockPostgres(ctrl)
+			mockTx := mock_pgfx.NewMockTx(ctrl)
+			var recorder bulkRecorder = mockPostgres.EXPECT()
+			if tt.atomic {
+				recorder = mockTx.EXPECT()
+				mockPostgres.EXPECT().BeginTx(ctx, gomock.Any()).Return(mockTx, tt.beginErr)
+			}
+			failed := false
+			for i, batch := range tt.mockBatches {
+				batchUsers := users[i*2 : i*2+len(batch.ids)]
+				if batch.queryErr != nil {
+					recorder.Query(ctx, `SELECT nextval(pg_get_serial_sequence('public.users', 'id')) FROM generate_series(1, $1)`, 2).
+						Return(nil, batch.queryErr)
+					continue
+				}
+				mockRows := mock_pgfx.NewMockRows(ctrl)
+				recorder.Query(ctx, gomock.Any(), len(batchUsers)).Return(mockRows, nil)
+				for _, id := range batch.ids {
+					id := id
+					mockRows.EXPECT().Next().Return(true)
+					mockRows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+						*dest[0].(*int64) = id
+						return nil
+					})
+				}
+				mockRows.EXPECT().Next().Return(false)
+				mockRows.EXPECT().Close()
+				mockRows.EXPECT().Err().Return(nil)
+				copyRows := make([][]any, len(batchUsers))
+				for j, user := range batchUsers {
+					copyRows[j] = []any{batch.ids[j], user.Name, user.Age}
+				}
+				recorder.CopyFrom(ctx, pgx.Identifier{"public", "users"}, _usersColumns, pgx.CopyFromRows(copyRows)).
+					Return(int64(len(batchUsers)), batch.copyErr)
+				if tt.atomic && batch.copyErr != nil {
+					failed = true
+					break
+				}
+			}
+			if tt.atomic && tt.beginErr == nil {
+				if failed {
+					mockTx.EXPECT().Rollback(ctx).Return(nil)
+				} else {
+					mockTx.EXPECT().Commit(ctx).Return(nil)
+				}
+			}
+
+			r := &repository{
+				logger:         zap.NewNop(),
+				postgresClient: mockPostgres,
+				config:         &internalconfig.PostgresRepoConfig{Schema: "public", BulkBatchSize: 2},
+			}
+			got, err := r.AddUsers(ctx, &entity.BulkAddUsersRequest{Users: users, Atomic: tt.atomic})
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_repository_GetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockRowScan struct {
+		res entity.User
+		err error
+	}
+	tests := []struct {
+		name        string
+		request     *entity.GetUserRequest
+		mockRowScan *mockRowScan
+		want        *entity.User
+		wantErr     error
+		assertion   assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "Happy path",
+			request: &entity.GetUserRequest{Id: 1},
+			mockRowScan: &mockRowScan{
+				res: entity.User{Id: 1, Name: "Name", Age: 23},
+			},
+			want:      &entity.User{Id: 1, Name: "Name", Age: 23},
+			assertion: assert.NoError,
+		},
+		{
+			name:    "No such row",
+			request: &entity.GetUserRequest{Id: 1},
+			mockRowScan: &mockRowScan{
+				err: pgx.ErrNoRows,
+			},
+			want:      nil,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name:    "Row scan fails",
+			request: &entity.GetUserRequest{Id: 1},
+			mockRowScan: &mockRowScan{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:    "Postgres is unreachable",
+			request: &entity.GetUserRequest{Id: 1},
+			mockRowScan: &mockRowScan{
+				err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			},
+			want:      nil,
+			wantErr:   entity.ErrUnavailable,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockPostgres := mock_pgfx.NewMockPostgres(ctrl)
+			mockRow := mock_pgfx.NewMockRow(ctrl)
+			mockPostgres.EXPECT().
+				QueryRow(ctx, gomock.Any(), tt.request.Id).
+				Return(mockRow)
+			mockRow.EXPECT().
+				Scan(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(userScan(tt.mockRowScan.res, tt.mockRowScan.err))
+
+			r := &repository{
+				logger:         zap.NewNop(),
+				postgresClient: mockPostgres,
+				config:         &internalconfig.PostgresRepoConfig{Schema: "public"},
+			}
+			got, err := r.GetUser(ctx, tt.request)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_repository_ListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	type mockQuery struct {
+		rows []entity.User
+		err  error
+	}
+	tests := []struct {
+		name      string
+		request   *entity.ListUsersRequest
+		mockQuery *mockQuery
+		scanErr   error
+		rowsErr   error
+		want      []entity.User
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "Happy path",
+			request: &entity.ListUsersRequest{Limit: 2, Offset: 0},
+			mockQuery: &mockQuery{
+				rows: []entity.User{{Id: 1, Name: "Name1", Age: 23}, {Id: 2, Name: "Name2", Age: 24}},
+			},
+			want:      []entity.User{{Id: 1, Name: "Name1", Age: 23}, {Id: 2, Name: "Name2", Age: 24}},
+			assertion: assert.NoError,
+		},
+		{
+			name:    "Empty page",
+			request: &entity.ListUsersRequest{Limit: 2, Offset: 10},
+			mockQuery: &mockQuery{
+				rows: nil,
+			},
+			want:      []entity.User{},
+			assertion: assert.NoError,
+		},
+		{
+			name:    "Query fails",
+			request: &entity.ListUsersRequest{Limit: 2, Offset: 0},
+			mockQuery: &mockQuery{
+				err: errors.New("some error"),
+			},
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:    "Row scan fails",
+			request: &entity.ListUsersRequest{Limit: 2, Offset: 0},
+			mockQuery: &mockQuery{
+				rows: []entity.User{{Id: 1, Name: "Name1", Age: 23}},
+			},
+			scanErr:   errors.New("some error"),
+			want:      nil,
+			assertion: assert.Error,
+		},
+		{
+			name:    "Rows iteration fails",
+			request: &entity.ListUsersRequest{Limit: 2, Offset: 0},
+			mockQuery: &mockQuery{
+				rows: nil,
+			},
+			rowsErr:   errors.New("some error"),
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockPostgres := mock_pgfx.NewMockPostgres(ctrl)
+			mockRows := mock_pgfx.NewMockRows(ctrl)
+			if tt.mockQuery.err != nil {
+				mockPostgres.EXPECT().
+					Query(ctx, gomock.Any(), tt.request.Limit, tt.request.Offset).
+					Return(nil, tt.mockQuery.err)
+			} else {
+				mockPostgres.EXPECT().
+					Query(ctx, gomock.Any(), tt.request.Limit, tt.request.Offset).
+					Return(mockRows, nil)
+				mockRows.EXPECT().Close()
+				for _, row := range tt.mockQuery.rows {
+					mockRows.EXPECT().Next().Return(true)
+					mockRows.EXPECT().
+						Scan(gomock.Any(), gomock.Any(), gomock.Any()).
+						DoAndReturn(userScan(row, tt.scanErr))
+					if tt.scanErr != nil {
+						break
+					}
+				}
+				if tt.scanErr == nil {
+					mockRows.EXPECT().Next().Return(false)
+					mockRows.EXPECT().Err().Return(tt.rowsErr)
+				}
+			}
+
+			r := &repository{
+				logger:         zap.NewNop(),
+				postgresClient: mockPostgres,
+				config:         &internalconfig.PostgresRepoConfig{Schema: "public"},
+			}
+			got, err := r.ListUsers(ctx, tt.request)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_repository_UpdateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	name := "NewName"
+	tests := []struct {
+		name      string
+		request   *entity.UpdateUserRequest
+		scanErr   error
+		want      *entity.User
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			request:   &entity.UpdateUserRequest{Id: 1, Name: &name},
+			want:      &entity.User{Id: 1, Name: "NewName", Age: 23},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "No such row",
+			request:   &entity.UpdateUserRequest{Id: 1, Name: &name},
+			scanErr:   pgx.ErrNoRows,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockPostgres := mock_pgfx.NewMockPostgres(ctrl)
+			mockRow := mock_pgfx.NewMockRow(ctrl)
+			mockPostgres.EXPECT().
+				QueryRow(ctx, gomock.Any(), tt.request.Id, tt.request.Name, tt.request.Age).
+				Return(mockRow)
+			mockRow.EXPECT().
+				Scan(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(userScan(entity.User{Id: 1, Name: "NewName", Age: 23}, tt.scanErr))
+
+			r := &repository{
+				logger:         zap.NewNop(),
+				postgresClient: mockPostgres,
+				config:         &internalconfig.PostgresRepoConfig{Schema: "public"},
+			}
+			got, err := r.UpdateUser(ctx, tt.request)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_repository_DeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name      string
+		request   *entity.DeleteUserRequest
+		scanErr   error
+		want      *entity.User
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			request:   &entity.DeleteUserRequest{Id: 1},
+			want:      &entity.User{Id: 1, Name: "Name", Age: 23},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "No such row",
+			request:   &entity.DeleteUserRequest{Id: 1},
+			scanErr:   pgx.ErrNoRows,
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockPostgres := mock_pgfx.NewMockPostgres(ctrl)
+			mockRow := mock_pgfx.NewMockRow(ctrl)
+			mockPostgres.EXPECT().
+				QueryRow(ctx, gomock.Any(), tt.request.Id).
+				Return(mockRow)
+			mockRow.EXPECT().
+				Scan(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(userScan(entity.User{Id: 1, Name: "Name", Age: 23}, tt.scanErr))
+
+			r := &repository{
+				logger:         zap.NewNop(),
+				postgresClient: mockPostgres,
+				config:         &internalconfig.PostgresRepoConfig{Schema: "public"},
+			}
+			got, err := r.DeleteUser(ctx, tt.request)
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// userScan is a helper that mimics scanning of the id, name, age columns into the destinations
+func userScan(user entity.User, err error) func(dest ...interface{}) error {
+	return func(dest ...interface{}) error {
+		if err != nil {
+			return err
+		}
+		*dest[0].(*int64) = user.Id
+		*dest[1].(*string) = user.Name
+		*dest[2].(*int) = user.Age
+		return nil
+	}
+}
+
+func Test_postgresError(t *testing.T) {
+	someErr := errors.New("some error")
+	tests := []struct {
+		name        string
+		err         error
+		wantErr     error
+		wantMessage string
+	}{
+		{
+			name:        "Unique violation",
+			err:         &pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint "users_pkey"`},
+			wantErr:     entity.ErrConflict,
+			wantMessage: "user conflicts with the stored users",
+		},
+		{
+			name:        "Check violation",
+			err:         &pgconn.PgError{Code: "23514", Message: `new row violates check constraint "users_age_check"`},
+			wantErr:     entity.ErrInvalidArgument,
+			wantMessage: "user violates the constraints of the stored users",
+		},
+		{
+			name:    "Numeric value out of range",
+			err:     &pgconn.PgError{Code: "22003"},
+			wantErr: entity.ErrInvalidArgument,
+		},
+		{
+			name:    "Connection exception",
+			err:     &pgconn.PgError{Code: "08006"},
+			wantErr: entity.ErrUnavailable,
+		},
+		{
+			name:    "Too many connections",
+			err:     &pgconn.PgError{Code: "53300"},
+			wantErr: entity.ErrUnavailable,
+		},
+		{
+			name:    "Network error",
+			err:     &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			wantErr: entity.ErrUnavailable,
+		},
+		{
+			name:    "Deadline exceeded",
+			err:     context.DeadlineExceeded,
+			wantErr: entity.ErrUnavailable,
+		},
+		{
+			name:    "Syntax error is returned as is",
+			err:     &pgconn.PgError{Code: "42601"},
+			wantErr: nil,
+		},
+		{
+			name:    "Unknown error is returned as is",
+			err:     someErr,
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := postgresError(tt.err)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, entity.PublicMessage(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	stderrors "errors"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"strings"
+	"time"
+)
+
+// _pipelineCommand is the command label of the pipelines, their commands are not timed separately
+const _pipelineCommand = "pipeline"
+
+// compile time check that metricsHook implements redis.Hook interface
+var _ redis.Hook = (*metricsHook)(nil)
+
+// metricsHook observes the latency and counts the errors of the redis commands by their names
+type metricsHook struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// newMetricsHook creates the hook and registers its metrics
+func newMetricsHook(registerer prometheus.Registerer) (*metricsHook, error) {
+	h := &metricsHook{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Latency of the redis commands by command, pipelines are observed as a single command.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_command_errors_total",
+			Help: "Number of the failed redis commands by command, missing keys are not counted.",
+		}, []string{"command"}),
+	}
+	for _, c := range []prometheus.Collector{h.duration, h.errors} {
+		if err := registerer.Register(c); err != nil {
+			return nil, errors.Errorf("failed to register metrics: %s", err)
+		}
+	}
+	return h, nil
+}
+
+func (h *metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.duration.WithLabelValues(cmd.Name()).Observe(time.Since(start).Seconds())
+		if failed(err) {
+			h.errors.WithLabelValues(cmd.Name()).Inc()
+		}
+		return err
+	}
+}
+
+func (h *metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.duration.WithLabelValues(_pipelineCommand).Observe(time.Since(start).Seconds())
+		for _, cmd := range cmds {
+			if failed(cmd.Err()) {
+				h.errors.WithLabelValues(cmd.Name()).Inc()
+			}
+		}
+		return err
+	}
+}
+
+// failed reports whether the command failed. Missing keys and the scripts missing in the cache, that are
+// loaded by redis.Script.Run on the first call, are the expected replies rather than failures.
+func failed(err error) bool {
+	return err != nil && !stderrors.Is(err, redis.Nil) && !strings.HasPrefix(err.Error(), "NOSCRIPT")
+}
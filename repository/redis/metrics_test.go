@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_newMetricsHook(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	h, err := newMetricsHook(registry)
+	assert.NotNil(t, h)
+	assert.NoError(t, err)
+	// the same metrics can't be registered twice
+	_, err = newMetricsHook(registry)
+	assert.Error(t, err)
+}
+
+func Test_metricsHook(t *testing.T) {
+	s := miniredis.RunT(t)
+	registry := prometheus.NewRegistry()
+	h, err := newMetricsHook(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	client.AddHook(h)
+	ctx := context.Background()
+
+	assert.NoError(t, client.Set(ctx, "counter", "not a number", 0).Err())
+	assert.ErrorIs(t, client.Get(ctx, "missing").Err(), redis.Nil)
+	assert.Error(t, client.IncrBy(ctx, "counter", 1).Err())
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "missing")
+		pipe.IncrBy(ctx, "counter", 1)
+		return nil
+	})
+	assert.Error(t, err)
+	// the script is loaded after the NOSCRIPT reply of EVALSHA
+	assert.NoError(t, redis.NewScript("return 1").Run(ctx, client, nil).Err())
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP redis_command_errors_total Number of the failed redis commands by command, missing keys are not counted.
+		# TYPE redis_command_errors_total counter
+		redis_command_errors_total{command="incrby"} 2
+	`), "redis_command_errors_total"))
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	observed := make(map[string]uint64)
+	for _, family := range families {
+		if family.GetName() != "redis_command_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			observed[metric.GetLabel()[0].GetValue()] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, map[string]uint64{
+		"set":      1,
+		"get":      1,
+		"incrby":   1,
+		"pipeline": 1,
+		"evalsha":  1,
+		"eval":     1,
+	}, observed)
+}
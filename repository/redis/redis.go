@@ -2,12 +2,22 @@ package redis
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/config"
 	"go.uber.org/fx"
+	"io"
+	"math/rand"
+	"net"
 	internalconfig "redis-postgres-service/config"
+	"redis-postgres-service/entity"
+	"redis-postgres-service/metrics"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -15,8 +25,205 @@ const (
 	_secretsKey = "redis_secrets"
 )
 
+// _incrementWithOptionsScript increments the counter, clamps the result to the optional [min, max] range
+// and sets the expiration of the counter if it doesn't have one yet, so that subsequent increments
+// don't prolong the window. Bounds are compared as lua numbers, i.e. precisely only within ±2^53.
+// KEYS[1] - counter key, ARGV[1] - increment, ARGV[2] - min or empty, ARGV[3] - max or empty,
+// ARGV[4] - ttl in milliseconds or 0. Returns the resulting value as string and the remaining ttl in milliseconds.
+var _incrementWithOptionsScript = redis.NewScript(`
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+if ARGV[2] ~= '' and value < tonumber(ARGV[2]) then
+	redis.call('SET', KEYS[1], ARGV[2], 'KEEPTTL')
+elseif ARGV[3] ~= '' and value > tonumber(ARGV[3]) then
+	redis.call('SET', KEYS[1], ARGV[3], 'KEEPTTL')
+end
+local ttl = tonumber(ARGV[4])
+if ttl > 0 and redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return {redis.call('GET', KEYS[1]), redis.call('PTTL', KEYS[1])}
+`)
+
+// _incrementFloatScript increments the counter by a floating point value and sets the expiration of the counter
+// if it doesn't have one yet. KEYS[1] - counter key, ARGV[1] - increment, ARGV[2] - ttl in milliseconds or 0.
+// Returns the resulting value as string and the remaining ttl in milliseconds.
+var _incrementFloatScript = redis.NewScript(`
+local value = redis.call('INCRBYFLOAT', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl > 0 and redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return {value, redis.call('PTTL', KEYS[1])}
+`)
+
+// _incrementDecimalScript adds two decimal numbers digit by digit, so that the precision is limited only by
+// the length of the strings. Scale of the result is the biggest scale of the operands, e.g. 1.10 + 2 = 3.10.
+// Missing counter is treated as 0. The expiration is handled the same way as in _incrementFloatScript.
+// KEYS[1] - counter key, ARGV[1] - increment, ARGV[2] - ttl in milliseconds or 0.
+// Returns the resulting value as string and the remaining ttl in milliseconds.
+var _incrementDecimalScript = redis.NewScript(`
+local function parse(s)
+	local sign, int, frac = string.match(s, '^([+-]?)(%d+)%.?(%d*)$')
+	return int ~= nil, sign == '-', int, frac
+end
+
+local function digit(s, i)
+	return string.byte(s, i) - 48
+end
+
+local function add(a, b)
+	local result, carry = {}, 0
+	for i = #a, 1, -1 do
+		local d = digit(a, i) + digit(b, i) + carry
+		carry = math.floor(d / 10)
+		result[i] = string.char(48 + d % 10)
+	end
+	return (carry > 0 and '1' or '') .. table.concat(result)
+end
+
+local function sub(a, b)
+	local result, borrow = {}, 0
+	for i = #a, 1, -1 do
+		local d = digit(a, i) - digit(b, i) - borrow
+		borrow = 0
+		if d < 0 then
+			d, borrow = d + 10, 1
+		end
+		result[i] = string.char(48 + d)
+	end
+	return table.concat(result)
+end
+
+local current = redis.call('GET', KEYS[1]) or '0'
+local ok, xneg, xint, xfrac = parse(current)
+if not ok then
+	return redis.error_reply('ERR value is not a valid decimal')
+end
+local _, yneg, yint, yfrac = parse(ARGV[1])
+local scale = math.max(#xfrac, #yfrac)
+local width = math.max(#xint, #yint)
+local x = string.rep('0', width - #xint) .. xint .. xfrac .. string.rep('0', scale - #xfrac)
+local y = string.rep('0', width - #yint) .. yint .. yfrac .. string.rep('0', scale - #yfrac)
+local digits, neg
+if xneg == yneg then
+	digits, neg = add(x, y), xneg
+elseif x >= y then
+	digits, neg = sub(x, y), xneg
+else
+	digits, neg = sub(y, x), yneg
+end
+local int = string.gsub(string.sub(digits, 1, #digits - scale), '^0+', '')
+if int == '' then
+	int = '0'
+end
+local value = int
+if scale > 0 then
+	value = value .. '.' .. string.sub(digits, #digits - scale + 1)
+end
+if neg and string.find(value, '[1-9]') then
+	value = '-' .. value
+end
+redis.call('SET', KEYS[1], value, 'KEEPTTL')
+local ttl = tonumber(ARGV[2])
+if ttl > 0 and redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return {value, redis.call('PTTL', KEYS[1])}
+`)
+
+// _slidingWindowScript records the hit in the sliding window log of the key if the log has less than limit hits
+// within the window. The log is a sorted set of the hits scored by their time, hits that left the window are removed
+// and the whole log expires when no hits are made within the window.
+// KEYS[1] - log key, ARGV[1] - current time in milliseconds, ARGV[2] - window in milliseconds, ARGV[3] - limit,
+// ARGV[4] - unique hit member, the hit is only checked without being recorded if it is empty. Returns 1 if the hit
+// is allowed or 0 otherwise, the number of hits in the window and the milliseconds left until the oldest hit leaves
+// the window.
+var _slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < tonumber(ARGV[3]) then
+	allowed = 1
+	if ARGV[4] ~= '' then
+		redis.call('ZADD', KEYS[1], now, ARGV[4])
+		redis.call('PEXPIRE', KEYS[1], window)
+		count = count + 1
+	end
+end
+local reset = 0
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// _acquireScript sets the key unless it exists and returns the current value otherwise.
+// KEYS[1] - key, ARGV[1] - value, ARGV[2] - ttl in milliseconds. Returns {1} if the key is set or {0, value}.
+var _acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return {1}
+end
+return {0, redis.call('GET', KEYS[1])}
+`)
+
+// _numberRegexp matches the values that can be returned as JSON numbers, i.e. stored by any of the increment modes
+var _numberRegexp = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?([eE][+-]?\d+)?$`)
+
+// IncrementOptions are the optional parameters of the counter increment
+type IncrementOptions struct {
+	// TTL is set as the counter expiration if the counter doesn't have one yet, zero value means no expiration
+	TTL time.Duration
+	// Min and Max clamp the counter value after the increment if set
+	Min *int64
+	Max *int64
+}
+
+// SlidingWindowResult is the state of the sliding window log after the hit. Count includes the allowed hit
+// unless the hit is only checked, Reset is the time left until the oldest hit leaves the window.
+type SlidingWindowResult struct {
+	Allowed bool
+	Count   int64
+	Reset   time.Duration
+}
+
+// IncrementResult is the outcome of a single increment of the batch, Err is set if the increment failed
+type IncrementResult struct {
+	Value int64
+	Err   error
+}
+
 type Repository interface {
 	AddIntValueForKey(ctx context.Context, key string, value int64) (int64, error)
+	AddIntValueForKeyWithOptions(
+		ctx context.Context,
+		key string,
+		value int64,
+		opts IncrementOptions,
+	) (int64, time.Duration, error)
+	AddIntValuesForKeys(ctx context.Context, items []entity.IncrementItem, atomic bool) ([]IncrementResult, error)
+	AddFloatValueForKey(ctx context.Context, key string, value float64, ttl time.Duration) (string, time.Duration, error)
+	AddDecimalValueForKey(ctx context.Context, key string, value string, ttl time.Duration) (string, time.Duration, error)
+	GetValueForKey(ctx context.Context, key string) (string, time.Duration, error)
+	DeleteKey(ctx context.Context, key string) error
+	AcquireKey(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, []byte, error)
+	SetKey(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	HitSlidingWindow(
+		ctx context.Context,
+		key string,
+		limit int64,
+		window time.Duration,
+		now time.Time,
+	) (SlidingWindowResult, error)
+	PeekSlidingWindow(
+		ctx context.Context,
+		key string,
+		limit int64,
+		window time.Duration,
+		now time.Time,
+	) (SlidingWindowResult, error)
 }
 
 // compile time check that repository implements Repository interface
@@ -28,6 +235,7 @@ type Params struct {
 
 	LC             fx.Lifecycle
 	ConfigProvider config.Provider
+	Metrics        metrics.Registry
 }
 
 // New is a constructor provided to the fx for creating a Repository
@@ -42,12 +250,17 @@ func New(p Params) (Repository, error) {
 	if err != nil {
 		return nil, errors.Errorf("failed to populate secrets: %s", err) // unreachable in tests, cause provider is populating from valid yaml.
 	}
+	hook, err := newMetricsHook(p.Metrics)
+	if err != nil {
+		return nil, err
+	}
 	fmt.Println(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port))
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
 		Password: secrets.Password,
 		DB:       cfg.Database,
 	})
+	client.AddHook(hook)
 	if _, err = client.Ping(context.Background()).Result(); err != nil {
 		return nil, errors.Errorf("failed to create a db, is redis running? Err: %s", err)
 	}
@@ -71,7 +284,287 @@ type repository struct {
 func (r *repository) AddIntValueForKey(ctx context.Context, key string, value int64) (int64, error) {
 	res, err := r.client.IncrBy(ctx, key, value).Result()
 	if err != nil {
-		return 0, errors.Errorf("redis increment failed: %s", err)
+		return 0, incrementError(err)
 	}
 	return res, nil
 }
+
+// AddIntValueForKeyWithOptions atomically adds integer value for the key provided applying the options.
+// Returns the resulting value and the remaining time to live of the key, which is negative if the key never expires.
+func (r *repository) AddIntValueForKeyWithOptions(
+	ctx context.Context,
+	key string,
+	value int64,
+	opts IncrementOptions,
+) (int64, time.Duration, error) {
+	res, err := _incrementWithOptionsScript.Run(
+		ctx,
+		r.client,
+		[]string{key},
+		value,
+		optionalInt(opts.Min),
+		optionalInt(opts.Max),
+		opts.TTL.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return 0, 0, incrementError(err)
+	}
+	if len(res) != 2 {
+		return 0, 0, errors.Errorf("redis increment failed: unexpected script result %v", res) // unreachable in tests
+	}
+	resValue, _ := res[0].(string)
+	result, err := strconv.ParseInt(resValue, 10, 64)
+	if err != nil {
+		return 0, 0, commandError("increment", err) // unreachable in tests, INCRBY guarantees integer
+	}
+	pttl, _ := res[1].(int64)
+	return result, ttlFromPTTL(pttl), nil
+}
+
+// AddIntValuesForKeys adds integer values for the keys provided in a single round trip, using a pipeline
+// or a MULTI/EXEC transaction if atomic is set. Results are returned in the order of the items. Errors replied
+// by redis for the individual increments are reported in the results, while the error is returned only
+// if the batch as a whole failed, e.g. redis is unreachable.
+func (r *repository) AddIntValuesForKeys(
+	ctx context.Context,
+	items []entity.IncrementItem,
+	atomic bool,
+) ([]IncrementResult, error) {
+	cmds := make([]*redis.IntCmd, len(items))
+	fn := func(pipe redis.Pipeliner) error {
+		for i, item := range items {
+			cmds[i] = pipe.IncrBy(ctx, item.Key, item.Value)
+		}
+		return nil
+	}
+	var err error
+	if atomic {
+		_, err = r.client.TxPipelined(ctx, fn)
+	} else {
+		_, err = r.client.Pipelined(ctx, fn)
+	}
+	if _, replied := err.(redis.Error); err != nil && !replied {
+		return nil, commandError("batch increment", err)
+	}
+	results := make([]IncrementResult, len(items))
+	for i, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil {
+			results[i].Err = incrementError(cmdErr)
+			continue
+		}
+		results[i].Value = cmd.Val()
+	}
+	return results, nil
+}
+
+// AddFloatValueForKey adds floating point value for the key provided, the expiration is set if ttl is positive
+// and the key doesn't have one yet. Returns the resulting value formatted by redis and the remaining time to live
+// of the key, which is negative if the key never expires.
+func (r *repository) AddFloatValueForKey(
+	ctx context.Context,
+	key string,
+	value float64,
+	ttl time.Duration,
+) (string, time.Duration, error) {
+	res, err := _incrementFloatScript.Run(
+		ctx,
+		r.client,
+		[]string{key},
+		strconv.FormatFloat(value, 'f', -1, 64),
+		ttl.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return "", 0, incrementError(err)
+	}
+	return scriptResult(res)
+}
+
+// AddDecimalValueForKey adds arbitrary-precision decimal value, e.g. "-12.50", for the key provided,
+// the expiration is handled the same way as in AddFloatValueForKey.
+// Returns the resulting value and the remaining time to live of the key, which is negative if the key never expires.
+func (r *repository) AddDecimalValueForKey(
+	ctx context.Context,
+	key string,
+	value string,
+	ttl time.Duration,
+) (string, time.Duration, error) {
+	res, err := _incrementDecimalScript.Run(
+		ctx,
+		r.client,
+		[]string{key},
+		value,
+		ttl.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return "", 0, incrementError(err)
+	}
+	return scriptResult(res)
+}
+
+// GetValueForKey returns the numeric value stored under the key and its remaining time to live,
+// which is negative if the key never expires. entity.ErrNotFound is returned if there is no such key.
+func (r *repository) GetValueForKey(ctx context.Context, key string) (string, time.Duration, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err == redis.Nil {
+		return "", 0, fmt.Errorf("key %s: %w", key, entity.ErrNotFound)
+	}
+	if err != nil {
+		return "", 0, commandError("get", err)
+	}
+	if !_numberRegexp.MatchString(get.Val()) {
+		return "", 0, errors.Errorf("redis get failed: value %q is not a number", get.Val())
+	}
+	return get.Val(), pttl.Val(), nil
+}
+
+// DeleteKey removes the key. entity.ErrNotFound is returned if there is no such key.
+func (r *repository) DeleteKey(ctx context.Context, key string) error {
+	deleted, err := r.client.Del(ctx, key).Result()
+	if err != nil {
+		return commandError("delete", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("key %s: %w", key, entity.ErrNotFound)
+	}
+	return nil
+}
+
+// AcquireKey atomically stores the value under the key with the ttl unless the key exists. Returns true if the value
+// is stored, or false and the value stored under the key otherwise.
+func (r *repository) AcquireKey(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, []byte, error) {
+	res, err := _acquireScript.Run(ctx, r.client, []string{key}, value, ttl.Milliseconds()).Slice()
+	if err != nil {
+		return false, nil, commandError("acquire", err)
+	}
+	if acquired, _ := res[0].(int64); acquired == 1 {
+		return true, nil, nil
+	}
+	if len(res) != 2 {
+		return false, nil, errors.Errorf("redis acquire failed: unexpected script result %v", res) // unreachable in tests
+	}
+	current, _ := res[1].(string)
+	return false, []byte(current), nil
+}
+
+// SetKey stores the value under the key with the ttl, overwriting the existing value
+func (r *repository) SetKey(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := r.client.Set(ctx, key, value, ttl).Err()
+	if err != nil {
+		return commandError("set", err)
+	}
+	return nil
+}
+
+// HitSlidingWindow atomically records the hit made at now in the sliding window log stored under the key,
+// unless the log already has limit hits within the window.
+func (r *repository) HitSlidingWindow(
+	ctx context.Context,
+	key string,
+	limit int64,
+	window time.Duration,
+	now time.Time,
+) (SlidingWindowResult, error) {
+	return r.slidingWindow(ctx, key, limit, window, now, fmt.Sprintf("%d-%d", now.UnixNano(), rand.Int63()))
+}
+
+// PeekSlidingWindow checks whether the hit made at now would be allowed by the sliding window log stored under the key
+// without recording it, so that only some of the checked hits are recorded by HitSlidingWindow afterwards.
+func (r *repository) PeekSlidingWindow(
+	ctx context.Context,
+	key string,
+	limit int64,
+	window time.Duration,
+	now time.Time,
+) (SlidingWindowResult, error) {
+	return r.slidingWindow(ctx, key, limit, window, now, "")
+}
+
+// slidingWindow runs _slidingWindowScript, the hit is recorded as the member unless it is empty
+func (r *repository) slidingWindow(
+	ctx context.Context,
+	key string,
+	limit int64,
+	window time.Duration,
+	now time.Time,
+	member string,
+) (SlidingWindowResult, error) {
+	res, err := _slidingWindowScript.Run(ctx, r.client, []string{key}, now.UnixMilli(), window.Milliseconds(), limit, member).
+		Int64Slice()
+	if err != nil {
+		return SlidingWindowResult{}, commandError("rate limit", err)
+	}
+	if len(res) != 3 {
+		return SlidingWindowResult{}, errors.Errorf("redis rate limit failed: unexpected script result %v", res) // unreachable in tests
+	}
+	return SlidingWindowResult{
+		Allowed: res[0] == 1,
+		Count:   res[1],
+		Reset:   time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// incrementError maps the errors of the increment commands into the entity errors where the request is to blame
+func incrementError(err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "would overflow"), strings.Contains(msg, "NaN or Infinity"):
+		return &entity.PublicError{
+			Message: "increment overflows the stored value",
+			Err:     fmt.Errorf("redis increment failed: %s: %w", msg, entity.ErrOverflow),
+		}
+	case strings.Contains(msg, "not an integer"), strings.Contains(msg, "not a valid float"),
+		strings.Contains(msg, "not a valid decimal"):
+		return &entity.PublicError{
+			Message: "stored value doesn't match the increment mode",
+			Err:     fmt.Errorf("redis increment failed: %s: %w", msg, entity.ErrInvalidArgument),
+		}
+	}
+	return commandError("increment", err)
+}
+
+// commandError wraps the error of the redis command, errors of the unreachable redis are reported as entity.ErrUnavailable
+func commandError(command string, err error) error {
+	if unavailable(err) {
+		return fmt.Errorf("redis %s failed: %s: %w", command, err, entity.ErrUnavailable)
+	}
+	return errors.Errorf("redis %s failed: %s", command, err)
+}
+
+// unavailable reports whether the error is caused by the connection to redis rather than by the command itself
+func unavailable(err error) bool {
+	var netErr net.Error
+	return stderrors.As(err, &netErr) || stderrors.Is(err, io.EOF) || stderrors.Is(err, redis.ErrClosed) ||
+		stderrors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "connection pool timeout")
+}
+
+// scriptResult converts the {value, pttl} reply of the increment scripts
+func scriptResult(res []interface{}) (string, time.Duration, error) {
+	if len(res) != 2 {
+		return "", 0, errors.Errorf("redis increment failed: unexpected script result %v", res) // unreachable in tests
+	}
+	value, _ := res[0].(string)
+	pttl, _ := res[1].(int64)
+	return value, ttlFromPTTL(pttl), nil
+}
+
+// optionalInt converts the optional integer into the script argument, empty string stands for nil
+func optionalInt(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+// ttlFromPTTL converts the PTTL reply into the duration, keeping negative replies for the keys without expiration
+func ttlFromPTTL(pttl int64) time.Duration {
+	if pttl < 0 {
+		return time.Duration(pttl)
+	}
+	return time.Duration(pttl) * time.Millisecond
+}
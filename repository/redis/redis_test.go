@@ -6,11 +6,15 @@ import (
 	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redismock/v9"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/config"
 	"go.uber.org/fx/fxtest"
+	"redis-postgres-service/entity"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -67,6 +71,7 @@ func TestNew(t *testing.T) {
 			got, err := New(Params{
 				ConfigProvider: tt.args.provider,
 				LC:             testlc,
+				Metrics:        prometheus.NewRegistry(),
 			})
 			tt.assertion(t, err)
 			if err == nil {
@@ -119,6 +124,21 @@ func Test_repository_AddIntValueForKey(t *testing.T) {
 			want:      0,
 			assertion: assert.Error,
 		},
+		{
+			name: "Increment overflows",
+			args: args{
+				value: 64,
+				key:   "some_key",
+			},
+			mockRedis: &mockRedis{
+				res: 0,
+				err: errors.New("ERR increment or decrement would overflow"),
+			},
+			want: 0,
+			assertion: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrOverflow, i...)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -138,3 +158,597 @@ func Test_repository_AddIntValueForKey(t *testing.T) {
 		})
 	}
 }
+
+func newMiniredisRepository(t *testing.T) (*miniredis.Miniredis, *repository) {
+	s := miniredis.RunT(t)
+	return s, &repository{
+		client: redis.NewClient(&redis.Options{Addr: s.Addr()}),
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func Test_repository_AddIntValueForKeyWithOptions(t *testing.T) {
+	type args struct {
+		key   string
+		value int64
+		opts  IncrementOptions
+	}
+	tests := []struct {
+		name      string
+		initial   string
+		initTTL   time.Duration
+		args      args
+		want      int64
+		wantTTL   time.Duration
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Happy path, no bounds reached",
+			args: args{
+				key:   "some_key",
+				value: 5,
+				opts:  IncrementOptions{Min: int64Ptr(0), Max: int64Ptr(10)},
+			},
+			want:      5,
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:    "Decrement is floored at min",
+			initial: "3",
+			args: args{
+				key:   "some_key",
+				value: -5,
+				opts:  IncrementOptions{Min: int64Ptr(0)},
+			},
+			want:      0,
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:    "Increment is capped at max",
+			initial: "8",
+			args: args{
+				key:   "some_key",
+				value: 5,
+				opts:  IncrementOptions{Max: int64Ptr(10)},
+			},
+			want:      10,
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name: "TTL is set for the new counter",
+			args: args{
+				key:   "some_key",
+				value: 1,
+				opts:  IncrementOptions{TTL: time.Minute},
+			},
+			want:      1,
+			wantTTL:   time.Minute,
+			assertion: assert.NoError,
+		},
+		{
+			name:    "TTL is not prolonged for the existing counter",
+			initial: "1",
+			initTTL: 10 * time.Second,
+			args: args{
+				key:   "some_key",
+				value: 1,
+				opts:  IncrementOptions{TTL: time.Minute, Max: int64Ptr(1)},
+			},
+			want:      1,
+			wantTTL:   10 * time.Second,
+			assertion: assert.NoError,
+		},
+		{
+			name:    "Value is not an integer",
+			initial: "1.5",
+			args: args{
+				key:   "some_key",
+				value: 1,
+				opts:  IncrementOptions{TTL: time.Minute},
+			},
+			want:    0,
+			wantTTL: 0,
+			assertion: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrInvalidArgument, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			if tt.initial != "" {
+				s.Set(tt.args.key, tt.initial)
+			}
+			if tt.initTTL != 0 {
+				s.SetTTL(tt.args.key, tt.initTTL)
+			}
+			got, gotTTL, err := r.AddIntValueForKeyWithOptions(context.Background(), tt.args.key, tt.args.value, tt.args.opts)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantTTL, gotTTL)
+		})
+	}
+}
+
+func Test_repository_AddIntValuesForKeys(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("atomic %t", atomic), func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			s.Set("b", "10")
+			s.Set("c", "1.5")
+			got, err := r.AddIntValuesForKeys(context.Background(), []entity.IncrementItem{
+				{Key: "a", Value: 1},
+				{Key: "b", Value: -2},
+				{Key: "c", Value: 1},
+				{Key: "a", Value: 5},
+			}, atomic)
+			assert.NoError(t, err)
+			assert.Len(t, got, 4)
+			assert.Equal(t, IncrementResult{Value: 1}, got[0])
+			assert.Equal(t, IncrementResult{Value: 8}, got[1])
+			assert.ErrorIs(t, got[2].Err, entity.ErrInvalidArgument)
+			assert.Equal(t, IncrementResult{Value: 6}, got[3])
+		})
+	}
+}
+
+func Test_repository_AddIntValuesForKeys_RedisFails(t *testing.T) {
+	s, r := newMiniredisRepository(t)
+	s.Close()
+	got, err := r.AddIntValuesForKeys(context.Background(), []entity.IncrementItem{{Key: "a", Value: 1}}, false)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func Test_repository_AddFloatValueForKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   string
+		initTTL   time.Duration
+		value     float64
+		ttl       time.Duration
+		want      string
+		wantTTL   time.Duration
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, new counter",
+			value:     10.5,
+			want:      "10.5",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, existing integer counter",
+			initial:   "3",
+			value:     -0.25,
+			want:      "2.75",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "TTL is set for the new counter",
+			value:     1.5,
+			ttl:       time.Minute,
+			want:      "1.5",
+			wantTTL:   time.Minute,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "TTL is not prolonged for the existing counter",
+			initial:   "1.5",
+			initTTL:   10 * time.Second,
+			value:     1.5,
+			ttl:       time.Minute,
+			want:      "3",
+			wantTTL:   10 * time.Second,
+			assertion: assert.NoError,
+		},
+		{
+			name:    "Value is not a float",
+			initial: "abc",
+			value:   1.5,
+			want:    "",
+			assertion: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrInvalidArgument, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			if tt.initial != "" {
+				s.Set("some_key", tt.initial)
+			}
+			if tt.initTTL != 0 {
+				s.SetTTL("some_key", tt.initTTL)
+			}
+			got, gotTTL, err := r.AddFloatValueForKey(context.Background(), "some_key", tt.value, tt.ttl)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+			if err == nil {
+				assert.Equal(t, tt.wantTTL, gotTTL)
+			}
+		})
+	}
+}
+
+func Test_repository_AddDecimalValueForKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   string
+		initTTL   time.Duration
+		value     string
+		ttl       time.Duration
+		want      string
+		wantTTL   time.Duration
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, new counter",
+			value:     "12.50",
+			want:      "12.50",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Carry beyond int64",
+			initial:   "9223372036854775807.99",
+			value:     "0.01",
+			want:      "9223372036854775808.00",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Precision beyond float64",
+			initial:   "0.1",
+			value:     "0.2",
+			want:      "0.3",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Scale is the biggest of the operands",
+			initial:   "1.10",
+			value:     "2",
+			want:      "3.10",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Subtraction changes the sign",
+			initial:   "1.5",
+			value:     "-10.25",
+			want:      "-8.75",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Negative counter is decreased",
+			initial:   "-1.5",
+			value:     "-0.5",
+			want:      "-2.0",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Result is zero",
+			initial:   "-100.01",
+			value:     "+100.01",
+			want:      "0.00",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "TTL is not prolonged for the existing counter",
+			initial:   "1",
+			initTTL:   10 * time.Second,
+			value:     "1",
+			ttl:       time.Minute,
+			want:      "2",
+			wantTTL:   10 * time.Second,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "TTL is set for the new counter",
+			value:     "1",
+			ttl:       time.Minute,
+			want:      "1",
+			wantTTL:   time.Minute,
+			assertion: assert.NoError,
+		},
+		{
+			name:    "Value is not a decimal",
+			initial: "1e5",
+			value:   "1",
+			want:    "",
+			assertion: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrInvalidArgument, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			if tt.initial != "" {
+				s.Set("some_key", tt.initial)
+			}
+			if tt.initTTL != 0 {
+				s.SetTTL("some_key", tt.initTTL)
+			}
+			got, gotTTL, err := r.AddDecimalValueForKey(context.Background(), "some_key", tt.value, tt.ttl)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+			if err == nil {
+				assert.Equal(t, tt.wantTTL, gotTTL)
+				stored, _ := s.Get("some_key")
+				assert.Equal(t, tt.want, stored)
+			}
+		})
+	}
+}
+
+func Test_repository_GetValueForKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   string
+		initTTL   time.Duration
+		want      string
+		wantTTL   time.Duration
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			initial:   "42",
+			want:      "42",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path, decimal",
+			initial:   "-42.10",
+			want:      "-42.10",
+			wantTTL:   -1,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Happy path with ttl",
+			initial:   "42",
+			initTTL:   time.Minute,
+			want:      "42",
+			wantTTL:   time.Minute,
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Key not found",
+			want:      "",
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+		{
+			name:      "Value is not a number",
+			initial:   "abc",
+			want:      "",
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			if tt.initial != "" {
+				s.Set("some_key", tt.initial)
+			}
+			if tt.initTTL != 0 {
+				s.SetTTL("some_key", tt.initTTL)
+			}
+			got, gotTTL, err := r.GetValueForKey(context.Background(), "some_key")
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+			if err == nil {
+				assert.Equal(t, tt.wantTTL, gotTTL)
+			}
+		})
+	}
+}
+
+func Test_repository_DeleteKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   string
+		wantErr   error
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path",
+			initial:   "42",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "Key not found",
+			wantErr:   entity.ErrNotFound,
+			assertion: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			if tt.initial != "" {
+				s.Set("some_key", tt.initial)
+			}
+			err := r.DeleteKey(context.Background(), "some_key")
+			tt.assertion(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.False(t, s.Exists("some_key"))
+		})
+	}
+}
+
+func Test_repository_DeleteKey_RedisFails(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("some_key").SetErr(errors.New("some error"))
+	r := &repository{
+		client: client,
+	}
+	assert.Error(t, r.DeleteKey(context.Background(), "some_key"))
+}
+
+func Test_repository_HitSlidingWindow(t *testing.T) {
+	start := time.UnixMilli(1700000000000)
+	type hit struct {
+		at   time.Duration
+		want SlidingWindowResult
+	}
+	tests := []struct {
+		name string
+		hits []hit
+	}{
+		{
+			name: "Hits within the limit are allowed",
+			hits: []hit{
+				{at: 0, want: SlidingWindowResult{Allowed: true, Count: 1, Reset: time.Minute}},
+				{at: 10 * time.Second, want: SlidingWindowResult{Allowed: true, Count: 2, Reset: 50 * time.Second}},
+			},
+		},
+		{
+			name: "Hits over the limit are rejected until the oldest hit leaves the window",
+			hits: []hit{
+				{at: 0, want: SlidingWindowResult{Allowed: true, Count: 1, Reset: time.Minute}},
+				{at: time.Second, want: SlidingWindowResult{Allowed: true, Count: 2, Reset: 59 * time.Second}},
+				{at: 2 * time.Second, want: SlidingWindowResult{Allowed: true, Count: 3, Reset: 58 * time.Second}},
+				{at: 30 * time.Second, want: SlidingWindowResult{Allowed: false, Count: 3, Reset: 30 * time.Second}},
+				{at: time.Minute, want: SlidingWindowResult{Allowed: true, Count: 3, Reset: time.Second}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			for _, h := range tt.hits {
+				got, err := r.HitSlidingWindow(context.Background(), "some_key", 3, time.Minute, start.Add(h.at))
+				assert.NoError(t, err)
+				assert.Equal(t, h.want, got)
+			}
+			assert.Equal(t, time.Minute, s.TTL("some_key"))
+		})
+	}
+}
+
+func Test_repository_HitSlidingWindow_RedisFails(t *testing.T) {
+	client, _ := redismock.NewClientMock()
+	r := &repository{
+		client: client,
+	}
+	_, err := r.HitSlidingWindow(context.Background(), "some_key", 3, time.Minute, time.Now())
+	assert.Error(t, err)
+}
+
+func Test_repository_PeekSlidingWindow(t *testing.T) {
+	start := time.UnixMilli(1700000000000)
+	s, r := newMiniredisRepository(t)
+	ctx := context.Background()
+	got, err := r.PeekSlidingWindow(ctx, "some_key", 2, time.Minute, start)
+	assert.NoError(t, err)
+	assert.Equal(t, SlidingWindowResult{Allowed: true}, got)
+	assert.False(t, s.Exists("some_key"), "checked hits must not be recorded")
+	for i := 0; i < 2; i++ {
+		if _, err = r.HitSlidingWindow(ctx, "some_key", 2, time.Minute, start); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err = r.PeekSlidingWindow(ctx, "some_key", 2, time.Minute, start.Add(10*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, SlidingWindowResult{Allowed: false, Count: 2, Reset: 50 * time.Second}, got)
+	got, err = r.PeekSlidingWindow(ctx, "some_key", 2, time.Minute, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, SlidingWindowResult{Allowed: true}, got)
+}
+
+func Test_repository_AcquireKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		initial     string
+		want        bool
+		wantCurrent []byte
+		wantValue   string
+		wantTTL     time.Duration
+		assertion   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Happy path, key is acquired",
+			want:      true,
+			wantValue: "some value",
+			wantTTL:   time.Minute,
+			assertion: assert.NoError,
+		},
+		{
+			name:        "Key exists",
+			initial:     "other value",
+			want:        false,
+			wantCurrent: []byte("other value"),
+			wantValue:   "other value",
+			assertion:   assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newMiniredisRepository(t)
+			if tt.initial != "" {
+				s.Set("some_key", tt.initial)
+			}
+			got, gotCurrent, err := r.AcquireKey(context.Background(), "some_key", []byte("some value"), time.Minute)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantCurrent, gotCurrent)
+			value, _ := s.Get("some_key")
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantTTL, s.TTL("some_key"))
+		})
+	}
+}
+
+func Test_repository_AcquireKey_RedisFails(t *testing.T) {
+	client, _ := redismock.NewClientMock()
+	r := &repository{
+		client: client,
+	}
+	_, _, err := r.AcquireKey(context.Background(), "some_key", []byte("some value"), time.Minute)
+	assert.Error(t, err)
+}
+
+func Test_repository_SetKey(t *testing.T) {
+	s, r := newMiniredisRepository(t)
+	s.Set("some_key", "other value")
+	err := r.SetKey(context.Background(), "some_key", []byte("some value"), time.Minute)
+	assert.NoError(t, err)
+	value, _ := s.Get("some_key")
+	assert.Equal(t, "some value", value)
+	assert.Equal(t, time.Minute, s.TTL("some_key"))
+}
+
+func Test_repository_SetKey_RedisFails(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectSet("some_key", []byte("some value"), time.Minute).SetErr(errors.New("some error"))
+	r := &repository{
+		client: client,
+	}
+	assert.Error(t, r.SetKey(context.Background(), "some_key", []byte("some value"), time.Minute))
+}
+
+func Test_repository_GetValueForKey_RedisUnavailable(t *testing.T) {
+	s, r := newMiniredisRepository(t)
+	s.Close()
+	_, _, err := r.GetValueForKey(context.Background(), "some_key")
+	assert.ErrorIs(t, err, entity.ErrUnavailable)
+}